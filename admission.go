@@ -0,0 +1,106 @@
+package gocache
+
+import "errors"
+
+// NoMaxKeyLength means that the keys written to the cache have no maximum length
+const NoMaxKeyLength = 0
+
+// WithAdmissionFilter sets a function that is consulted before a new entry is created, and which decides whether the
+// entry should be cached at all based on its key, its value and the approximate size of its value in bytes. This
+// allows refusing to cache low-value entries centrally, such as massive blobs or keys with a blacklisted prefix.
+//
+// Entries that are refused are silently not cached, like entries with a TTL of 0, and are counted in
+// Statistics.NotAdmitted. Updating an entry that already exists never consults the filter. The filter is called while
+// the lock of the cache is held, so it must be fast and must not call any method of the cache. The key passed to the
+// filter is the key given by the caller, even if WithKeyObfuscation is used, except for the entries loaded by
+// LoadHotKeys, whose keys were saved as they are stored.
+//
+// A nil filter admits every entry, which is the default.
+func WithAdmissionFilter(filter func(key string, value interface{}, size int) bool) func(c *Cache) {
+	return func(c *Cache) {
+		c.admissionFilter = filter
+	}
+}
+
+// WithNeverCachePatterns makes the cache silently skip the writes of the keys matching any of the patterns passed as
+// parameter (see MatchPattern), so that those keys always miss, which allows excluding sensitive or volatile data
+// from the cache without touching every call site.
+//
+// Unlike other patterns, they are matched against the keys given by the callers even if WithKeyObfuscation is used,
+// and retrievals of the keys matching them miss without looking up the cache.
+func WithNeverCachePatterns(patterns ...string) func(c *Cache) {
+	return func(c *Cache) {
+		c.neverCachePatterns = append([]string(nil), patterns...)
+	}
+}
+
+// neverCached returns whether the key passed as parameter matches any of the patterns of WithNeverCachePatterns
+func (c *Cache) neverCached(key string) bool {
+	for _, pattern := range c.neverCachePatterns {
+		if MatchPattern(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// errNeverCached is returned by writeKey for the keys matching the patterns of WithNeverCachePatterns, whose writes
+// are silently skipped
+var errNeverCached = errors.New("key is never cached")
+
+// writeKey returns the key under which the entry of the key given by the caller is stored (see storageKey), after
+// checking the key given by the caller against the patterns of WithNeverCachePatterns and the MaxKeyLength, so that
+// they apply to it rather than to its digest if WithKeyObfuscation is used
+//
+// Returns errNeverCached if the write must be silently skipped, or ErrKeyTooLong if it must be rejected, in which
+// case it is neither passed to the hooks nor recorded in the audit log.
+func (c *Cache) writeKey(key string) (string, error) {
+	if c.maxKeyLength != NoMaxKeyLength && len(key) > c.maxKeyLength {
+		// The key isn't obfuscated, as computing the digest of a huge key is precisely what must be avoided
+		c.mutex.Lock()
+		c.stats.LongKeys++
+		c.mutex.Unlock()
+		return "", ErrKeyTooLong
+	}
+	storageKey := c.storageKey(key)
+	if len(c.neverCachePatterns) > 0 && c.neverCached(key) {
+		c.mutex.RLock()
+		if c.traced(storageKey) {
+			c.trace(storageKey, "skip", "reason=never-cached")
+		}
+		c.mutex.RUnlock()
+		return storageKey, errNeverCached
+	}
+	return storageKey, nil
+}
+
+// neverStored returns whether the entry of the key given by the caller cannot be in the cache because its writes are
+// always skipped or rejected, in which case retrievals miss without looking up the cache
+func (c *Cache) neverStored(key string) bool {
+	if c.maxKeyLength != NoMaxKeyLength && len(key) > c.maxKeyLength {
+		return true
+	}
+	return len(c.neverCachePatterns) > 0 && c.neverCached(key)
+}
+
+// WithMaxKeyLength sets the maximum length of the keys written to the cache, which protects it from buggy callers
+// accidentally using huge payloads as keys. Writes with a longer key are rejected with ErrKeyTooLong and counted in
+// Statistics.LongKeys, while retrievals with a longer key simply miss, as such a key cannot be in the cache.
+// Use KeyBuilder.MaxLength to hash long keys instead of rejecting them.
+//
+// The length is that of the key given by the caller, even if WithKeyObfuscation is used, in which case the keys that
+// are too long are rejected before their digest is computed.
+// A maxLength of 0 or less means that keys have no maximum length (NoMaxKeyLength), which is the default.
+func WithMaxKeyLength(maxLength int) func(c *Cache) {
+	return func(c *Cache) {
+		if maxLength < 0 {
+			maxLength = NoMaxKeyLength
+		}
+		c.maxKeyLength = maxLength
+	}
+}
+
+// MaxKeyLength returns the maximum length of the keys written to the cache
+func (c *Cache) MaxKeyLength() int {
+	return c.maxKeyLength
+}
@@ -0,0 +1,162 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithAdmissionFilter(t *testing.T) {
+	cache := NewCache(WithAdmissionFilter(func(key string, value interface{}, size int) bool {
+		return !strings.HasPrefix(key, "tmp:") && size <= 100
+	}))
+	if err := cache.Set("key", "value"); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	if err := cache.Set("tmp:key", "value"); err != nil {
+		t.Error("expected entries refused by the filter not to return an error, got", err)
+	}
+	cache.Set("blob", strings.Repeat("a", 1000))
+	if _, ok := cache.Get("key"); !ok {
+		t.Error("expected the entry to have been admitted")
+	}
+	if _, ok := cache.Get("tmp:key"); ok {
+		t.Error("expected the entry with a blacklisted prefix not to have been admitted")
+	}
+	if _, ok := cache.Get("blob"); ok {
+		t.Error("expected the large entry not to have been admitted")
+	}
+	if notAdmitted := cache.Stats().NotAdmitted; notAdmitted != 2 {
+		t.Error("expected 2 entries not to have been admitted, got", notAdmitted)
+	}
+}
+
+func TestWithAdmissionFilterIsNotConsultedForUpdates(t *testing.T) {
+	admit := true
+	cache := NewCache(WithAdmissionFilter(func(key string, value interface{}, size int) bool {
+		return admit
+	}))
+	cache.Set("key", "value")
+	admit = false
+	cache.Set("key", "new-value")
+	if value, _ := cache.Get("key"); value != "new-value" {
+		t.Error("expected the existing entry to have been updated, got", value)
+	}
+}
+
+func TestWithAdmissionFilterWithKeyObfuscation(t *testing.T) {
+	cache := NewCache(WithKeyObfuscation([]byte("secret")), WithAdmissionFilter(func(key string, value interface{}, size int) bool {
+		return !strings.HasPrefix(key, "tmp:")
+	}))
+	cache.Set("tmp:key", "value")
+	cache.Increment("tmp:counter", 1)
+	cache.SAdd("tmp:set", "member")
+	cache.TrySet("tmp:other", "value", time.Hour)
+	cache.Set("key", "value")
+	if _, ok := cache.Get("tmp:key"); ok {
+		t.Error("expected the entry with a blacklisted prefix not to have been admitted")
+	}
+	if cache.Count() != 1 {
+		t.Error("expected only key to have been admitted, got", cache.Count())
+	}
+	if notAdmitted := cache.Stats().NotAdmitted; notAdmitted != 4 {
+		t.Error("expected 4 entries not to have been admitted, got", notAdmitted)
+	}
+}
+
+func TestWithNeverCachePatterns(t *testing.T) {
+	cache := NewCache(WithNeverCachePatterns("session:*", "*:password"))
+	if err := cache.Set("session:123", "value"); err != nil {
+		t.Error("expected keys matching the patterns to be silently skipped, got", err)
+	}
+	cache.Set("user:1:password", "hunter2")
+	cache.Set("user:1:name", "john")
+	cache.SAdd("session:456", "member")
+	if _, ok := cache.Get("session:123"); ok {
+		t.Error("expected session:123 to miss")
+	}
+	if _, ok := cache.Get("user:1:password"); ok {
+		t.Error("expected user:1:password to miss")
+	}
+	if _, ok := cache.Get("user:1:name"); !ok {
+		t.Error("expected user:1:name to have been cached")
+	}
+	if cache.Count() != 1 {
+		t.Error("expected a single entry to have been cached, got", cache.Count())
+	}
+}
+
+func TestWithNeverCachePatternsWithKeyObfuscation(t *testing.T) {
+	cache := NewCache(WithKeyObfuscation([]byte("secret")), WithNeverCachePatterns("session:*"))
+	cache.Set("session:123", "value")
+	cache.SetWithTTL("session:456", "value", time.Hour)
+	cache.Increment("session:counter", 1)
+	cache.SAdd("session:set", "member")
+	cache.TrySet("session:789", "value", time.Hour)
+	cache.Set("user:1", "value")
+	if _, ok := cache.Get("session:123"); ok {
+		t.Error("expected session:123 to miss")
+	}
+	if _, ok := cache.Get("user:1"); !ok {
+		t.Error("expected user:1 to have been cached")
+	}
+	if cache.Count() != 1 {
+		t.Error("expected only user:1 to have been cached, got", cache.Count())
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d and %d", stats.Hits, stats.Misses)
+	}
+}
+
+func TestWithMaxKeyLength(t *testing.T) {
+	cache := NewCache(WithMaxKeyLength(10))
+	if cache.MaxKeyLength() != 10 {
+		t.Error("expected the max key length to be 10, got", cache.MaxKeyLength())
+	}
+	if err := cache.Set("short", "value"); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	long := strings.Repeat("a", 11)
+	if err := cache.Set(long, "value"); err != ErrKeyTooLong {
+		t.Error("expected ErrKeyTooLong, got", err)
+	}
+	if _, err := cache.SAdd(long, "member"); err != ErrKeyTooLong {
+		t.Error("expected ErrKeyTooLong, got", err)
+	}
+	if _, ok := cache.Get(long); ok {
+		t.Error("expected the entry with a long key not to have been cached")
+	}
+	if longKeys := cache.Stats().LongKeys; longKeys != 2 {
+		t.Error("expected 2 writes to have been rejected, got", longKeys)
+	}
+	if cache.Count() != 1 {
+		t.Error("expected 1 entry, got", cache.Count())
+	}
+	if NewCache(WithMaxKeyLength(-1)).MaxKeyLength() != NoMaxKeyLength {
+		t.Error("expected a negative max key length to mean no max key length")
+	}
+}
+
+func TestWithMaxKeyLengthWithKeyObfuscation(t *testing.T) {
+	// The digests are 64 characters long, so the limit must apply to the keys given by the callers
+	cache := NewCache(WithKeyObfuscation([]byte("secret")), WithMaxKeyLength(10))
+	if err := cache.Set("short", "value"); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	long := strings.Repeat("a", 4096)
+	if err := cache.Set(long, "value"); err != ErrKeyTooLong {
+		t.Error("expected ErrKeyTooLong, got", err)
+	}
+	if err := cache.TrySet(long, "value", time.Hour); err != ErrKeyTooLong {
+		t.Error("expected ErrKeyTooLong, got", err)
+	}
+	if _, ok := cache.Get(long); ok {
+		t.Error("expected the entry with a long key not to have been cached")
+	}
+	if _, ok := cache.Get("short"); !ok {
+		t.Error("expected short to have been cached")
+	}
+	if longKeys := cache.Stats().LongKeys; longKeys != 2 {
+		t.Error("expected 2 writes to have been rejected, got", longKeys)
+	}
+}
@@ -0,0 +1,200 @@
+package gocache
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithRaceAssertions sets whether the cache should verify its internal invariants after every mutation, and panic
+// with a dump of its internal state as soon as one of them is violated.
+//
+// The invariants verified are:
+// - the linked list from head to tail is consistent and contains exactly the entries in the cache
+// - the memory usage is never negative
+// - if the EvictionPolicy is LeastFrequentUsed, the frequency buckets are sorted, none of them is empty, and every
+// entry belongs to exactly one of them
+// - the sample set used by SampleKeys contains exactly the entries in the cache
+// - the scan buckets Scan iterates over contain exactly the keys in the cache
+// - if the EvictionPolicy is LRUK, the heap contains exactly the entries in the cache
+// - the hand of the Sieve eviction policy, if any, points to an entry in the cache
+// - every tag has at least one key, and only keys in the cache
+//
+// This is meant to help with debugging concurrency bugs, as a corrupted cache is caught on the operation that
+// corrupted it rather than much later. It walks the entire cache on every mutation, so it should never be enabled
+// in production.
+//
+// Defaults to false
+func WithRaceAssertions(raceAssertions bool) func(c *Cache) {
+	return func(c *Cache) {
+		c.raceAssertions = raceAssertions
+	}
+}
+
+// assertInvariants panics if the cache's internal state is corrupted and race assertions are enabled.
+//
+// The caller must hold the lock.
+func (c *Cache) assertInvariants() {
+	if !c.raceAssertions {
+		return
+	}
+	if violation := c.findInvariantViolation(); violation != "" {
+		panic(fmt.Sprintf("gocache: invariant violated: %s\n%s", violation, c.dump()))
+	}
+}
+
+// findInvariantViolation returns a description of the first invariant found to be violated, or an empty string if
+// the cache's internal state is consistent
+func (c *Cache) findInvariantViolation() string {
+	if c.memoryUsage < 0 {
+		return fmt.Sprintf("memoryUsage is negative (%d)", c.memoryUsage)
+	}
+	if (c.head == nil) != (c.tail == nil) {
+		return "only one of head and tail is nil"
+	}
+	if c.head != nil && c.head.previous != nil {
+		return fmt.Sprintf("head %q has a previous entry", c.head.Key)
+	}
+	if c.tail != nil && c.tail.next != nil {
+		return fmt.Sprintf("tail %q has a next entry", c.tail.Key)
+	}
+	numberOfEntriesInList := 0
+	var previous *Entry
+	for current := c.head; current != nil; current = current.next {
+		numberOfEntriesInList++
+		if numberOfEntriesInList > len(c.entries) {
+			return fmt.Sprintf("list has more entries than the map (%d), or contains a cycle", len(c.entries))
+		}
+		if current.previous != previous {
+			return fmt.Sprintf("entry %q does not point back to the entry before it", current.Key)
+		}
+		if entryFromMap, ok := c.entries[current.Key]; !ok || entryFromMap != current {
+			return fmt.Sprintf("entry %q is in the list but not in the map", current.Key)
+		}
+		previous = current
+	}
+	if previous != c.tail {
+		return "walking the list from the head does not end at the tail"
+	}
+	if numberOfEntriesInList != len(c.entries) {
+		return fmt.Sprintf("list has %d entries, but map has %d", numberOfEntriesInList, len(c.entries))
+	}
+	if c.evictionPolicy == LeastFrequentUsed && c.freqs != nil {
+		numberOfEntriesInBuckets := 0
+		previousFrequency := 0
+		for element := c.freqs.Front(); element != nil; element = element.Next() {
+			frequencyItem := element.Value.(*FrequencyItem)
+			if frequencyItem.Freq <= previousFrequency {
+				return fmt.Sprintf("frequency bucket %d is not after bucket %d", frequencyItem.Freq, previousFrequency)
+			}
+			if len(frequencyItem.Entries) == 0 {
+				return fmt.Sprintf("frequency bucket %d is empty", frequencyItem.Freq)
+			}
+			for entry := range frequencyItem.Entries {
+				if entry.frequencyParent != element {
+					return fmt.Sprintf("entry %q is in frequency bucket %d, but does not point to it", entry.Key, frequencyItem.Freq)
+				}
+				if entryFromMap, ok := c.entries[entry.Key]; !ok || entryFromMap != entry {
+					return fmt.Sprintf("entry %q is in frequency bucket %d, but not in the map", entry.Key, frequencyItem.Freq)
+				}
+			}
+			numberOfEntriesInBuckets += len(frequencyItem.Entries)
+			previousFrequency = frequencyItem.Freq
+		}
+		if numberOfEntriesInBuckets != len(c.entries) {
+			return fmt.Sprintf("frequency buckets have %d entries, but map has %d", numberOfEntriesInBuckets, len(c.entries))
+		}
+	}
+	if len(c.sampleEntries) != len(c.entries) {
+		return fmt.Sprintf("sample has %d entries, but map has %d", len(c.sampleEntries), len(c.entries))
+	}
+	for i, entry := range c.sampleEntries {
+		if entry.sampleIndex != i {
+			return fmt.Sprintf("entry %q is at index %d of the sample, but does not point to it", entry.Key, i)
+		}
+		if entryFromMap, ok := c.entries[entry.Key]; !ok || entryFromMap != entry {
+			return fmt.Sprintf("entry %q is in the sample, but not in the map", entry.Key)
+		}
+	}
+	// Every entry being in its bucket and the buckets having as many keys as the map is enough to prove that they have
+	// no other key, which avoids iterating over the keys of every bucket
+	if len(c.entries) > 0 && c.scanBuckets == nil {
+		return "scan buckets are missing"
+	}
+	for key, entry := range c.entries {
+		if _, ok := c.scanBuckets[entry.scanBucket][key]; !ok {
+			return fmt.Sprintf("entry %q is not in scan bucket %d", key, entry.scanBucket)
+		}
+	}
+	numberOfKeysInScanBuckets := 0
+	for _, keys := range c.scanBuckets {
+		numberOfKeysInScanBuckets += len(keys)
+	}
+	if numberOfKeysInScanBuckets != len(c.entries) {
+		return fmt.Sprintf("scan buckets have %d keys, but map has %d", numberOfKeysInScanBuckets, len(c.entries))
+	}
+	if c.evictionPolicy == LRUK {
+		if len(c.lruk) != len(c.entries) {
+			return fmt.Sprintf("lru-k heap has %d entries, but map has %d", len(c.lruk), len(c.entries))
+		}
+		for i, entry := range c.lruk {
+			if entry.history == nil || entry.history.index != i {
+				return fmt.Sprintf("entry %q is at index %d of the lru-k heap, but does not point to it", entry.Key, i)
+			}
+			if entryFromMap, ok := c.entries[entry.Key]; !ok || entryFromMap != entry {
+				return fmt.Sprintf("entry %q is in the lru-k heap, but not in the map", entry.Key)
+			}
+		}
+	}
+	if c.sieveHand != nil {
+		if entryFromMap, ok := c.entries[c.sieveHand.Key]; !ok || entryFromMap != c.sieveHand {
+			return fmt.Sprintf("sieve hand %q is not in the map", c.sieveHand.Key)
+		}
+	}
+	for tag, keys := range c.tags {
+		if len(keys) == 0 {
+			return fmt.Sprintf("tag %q has no keys", tag)
+		}
+		for key := range keys {
+			if _, ok := c.entries[key]; !ok {
+				return fmt.Sprintf("key %q is indexed under tag %q, but not in the map", key, tag)
+			}
+		}
+	}
+	return ""
+}
+
+// dump returns a human-readable representation of the cache's internal state
+//
+// The caller must hold the lock.
+func (c *Cache) dump() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "entries=%d; memoryUsage=%d; maxSize=%d; maxMemoryUsage=%d; evictionPolicy=%d\n", len(c.entries), c.memoryUsage, c.maxSize, c.maxMemoryUsage, c.evictionPolicy)
+	sb.WriteString("list (head to tail):")
+	steps := 0
+	for current := c.head; current != nil && steps <= len(c.entries); current = current.next {
+		sb.WriteString(" " + current.Key)
+		steps++
+	}
+	if steps > len(c.entries) {
+		sb.WriteString(" ...")
+	}
+	sb.WriteString("\n")
+	if c.freqs != nil {
+		sb.WriteString("frequency buckets:")
+		for element := c.freqs.Front(); element != nil; element = element.Next() {
+			frequencyItem := element.Value.(*FrequencyItem)
+			fmt.Fprintf(&sb, " %d=[", frequencyItem.Freq)
+			first := true
+			for entry := range frequencyItem.Entries {
+				if !first {
+					sb.WriteString(" ")
+				}
+				sb.WriteString(entry.Key)
+				first = false
+			}
+			sb.WriteString("]")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package gocache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_WithRaceAssertions(t *testing.T) {
+	for _, evictionPolicy := range []EvictionPolicy{FirstInFirstOut, LeastRecentlyUsed, LeastFrequentUsed} {
+		t.Run(fmt.Sprintf("%d", evictionPolicy), func(t *testing.T) {
+			cache := NewCache(WithMaxSize(10), WithMaxMemoryUsage(Kilobyte), WithEvictionPolicy(evictionPolicy), WithRaceAssertions(true))
+			wg := sync.WaitGroup{}
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 200; j++ {
+						key := fmt.Sprintf("%d", (i*j)%15)
+						cache.SetWithTTL(key, strings.Repeat("0", j%64), time.Duration(j%3)*time.Millisecond+time.Millisecond)
+						cache.Get(key)
+						if j%7 == 0 {
+							cache.Delete(key)
+						}
+						if j%50 == 0 {
+							cache.GetAll()
+						}
+					}
+				}(i)
+			}
+			wg.Wait()
+			cache.Clear()
+		})
+	}
+}
+
+func TestCache_WithRaceAssertionsWhenCorrupted(t *testing.T) {
+	cache := NewCache(WithRaceAssertions(true))
+	cache.Set("1", "v")
+	cache.Set("2", "v")
+	// Corrupt the list by detaching the head from the rest of the list
+	cache.head.next = nil
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected a panic")
+		}
+		if !strings.Contains(fmt.Sprint(r), "invariant violated") {
+			t.Error("expected panic message to describe the violated invariant, got", r)
+		}
+	}()
+	cache.Set("3", "v")
+}
@@ -0,0 +1,130 @@
+package gocache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// OpMask is a set of operations, used to select the operations recorded by the audit log (see WithAuditLog)
+type OpMask uint
+
+const (
+	OpGet    OpMask = 1 << iota // Retrieval of a key through Get or GetCtx
+	OpSet                       // Creation or update of a key through Set, SetWithTTL, SetWithTTLCtx or SetWithMinLifetime
+	OpDelete                    // Explicit deletion of a key, through Delete, DeleteAll, Take, SoftDelete, etc.
+	OpEvict                     // Eviction of a key to make room for other entries
+	OpExpire                    // Deletion of a key because it expired
+
+	OpAll = OpGet | OpSet | OpDelete | OpEvict | OpExpire // Every operation
+)
+
+// String returns the name of a single operation, as written in the audit log
+func (op OpMask) String() string {
+	switch op {
+	case OpGet:
+		return "get"
+	case OpSet:
+		return "set"
+	case OpDelete:
+		return "delete"
+	case OpEvict:
+		return "evict"
+	case OpExpire:
+		return "expire"
+	}
+	return "unknown"
+}
+
+// AuditRecord is the format of each line written to the audit log
+type AuditRecord struct {
+	// Time is the time at which the operation happened
+	Time time.Time `json:"time"`
+
+	// Op is the name of the operation (see OpMask.String)
+	Op string `json:"op"`
+
+	// Key is the key the operation applied to
+	Key string `json:"key"`
+
+	// Size is the approximate size in bytes of the value written, read or removed, if any
+	Size int `json:"size,omitempty"`
+
+	// TTL is the TTL of the value written, if the operation is a set and the value expires
+	TTL string `json:"ttl,omitempty"`
+
+	// Hit is whether the key was found, if the operation is a get
+	Hit *bool `json:"hit,omitempty"`
+
+	// RequestID is the request ID attached to the context passed to the operation, if any (see ContextWithRequestID)
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// auditLog is the destination of the audit records and the operations that must be recorded
+type auditLog struct {
+	// mutex guards encoder, as records may be written both with and without the cache's lock held
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	ops     OpMask
+}
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the given request ID, which is included in the audit records of
+// the operations the context is passed to (e.g. GetCtx, SetWithTTLCtx, DeleteCtx)
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID attached to ctx through ContextWithRequestID, if any
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// WithAuditLog records the selected operations to w as JSON lines, one AuditRecord per line, which is useful for
+// caches holding data subject to compliance requirements. Values themselves are never recorded, only their size.
+//
+// Evictions and expirations are recorded while the cache's lock is held, so w should be fast, or buffered.
+// Setting w to nil or ops to 0 disables the audit log, which is the default.
+func WithAuditLog(w io.Writer, ops OpMask) func(c *Cache) {
+	return func(c *Cache) {
+		if w == nil || ops == 0 {
+			c.auditLog = nil
+			return
+		}
+		c.auditLog = &auditLog{encoder: json.NewEncoder(w), ops: ops}
+	}
+}
+
+// audits returns whether the given operation must be recorded, so that callers can avoid building records that would
+// be discarded
+func (c *Cache) audits(op OpMask) bool {
+	return c.auditLog != nil && c.auditLog.ops&op != 0
+}
+
+// audit writes a record for the given operation to the audit log, filling in its time, name and request ID
+// Errors writing the record are ignored, as the operation itself already happened.
+func (c *Cache) audit(ctx context.Context, op OpMask, record AuditRecord) {
+	record.Time = time.Now()
+	record.Op = op.String()
+	record.RequestID = RequestIDFromContext(ctx)
+	c.auditLog.mutex.Lock()
+	_ = c.auditLog.encoder.Encode(record)
+	c.auditLog.mutex.Unlock()
+}
+
+// auditDeletion records the explicit deletion of an entry, if deletions are audited, and in the traces of its key
+func (c *Cache) auditDeletion(ctx context.Context, entry *Entry) {
+	if c.traced(entry.Key) {
+		c.trace(entry.Key, "delete")
+	}
+	if c.audits(OpDelete) {
+		c.audit(ctx, OpDelete, AuditRecord{Key: entry.Key, Size: toBytes(entry.Value)})
+	}
+}
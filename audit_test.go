@@ -0,0 +1,84 @@
+package gocache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func readAuditRecords(t *testing.T, buffer *bytes.Buffer) []AuditRecord {
+	var records []AuditRecord
+	scanner := bufio.NewScanner(buffer)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal("expected each line to be a JSON-encoded record, got", scanner.Text())
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWithAuditLog(t *testing.T) {
+	var buffer bytes.Buffer
+	cache := NewCache(WithMaxSize(2), WithAuditLog(&buffer, OpAll))
+	ctx := ContextWithRequestID(context.Background(), "request-1")
+	cache.SetWithTTLCtx(ctx, "1", "value", time.Hour)
+	cache.GetCtx(ctx, "1")
+	cache.Get("missing")
+	cache.Set("2", "value")
+	cache.Set("3", "value")
+	cache.DeleteCtx(ctx, "2")
+	cache.SetWithTTL("4", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	cache.Get("4")
+	records := readAuditRecords(t, &buffer)
+	expected := []struct {
+		op, key, requestID string
+	}{
+		{"set", "1", "request-1"},
+		{"get", "1", "request-1"},
+		{"get", "missing", ""},
+		{"set", "2", ""},
+		{"evict", "1", ""},
+		{"set", "3", ""},
+		{"delete", "2", "request-1"},
+		{"set", "4", ""},
+		{"expire", "4", ""},
+		{"get", "4", ""},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records, got %d: %+v", len(expected), len(records), records)
+	}
+	for i, record := range records {
+		if record.Op != expected[i].op || record.Key != expected[i].key || record.RequestID != expected[i].requestID {
+			t.Errorf("expected record %d to be %+v, got %+v", i, expected[i], record)
+		}
+		if time.Since(record.Time) > time.Minute {
+			t.Errorf("expected record %d to have a recent time, got %s", i, record.Time)
+		}
+	}
+	if records[0].TTL != "1h0m0s" || records[0].Size == 0 {
+		t.Errorf("expected the first set to have a TTL and a size, got %+v", records[0])
+	}
+	if records[1].Hit == nil || !*records[1].Hit || records[2].Hit == nil || *records[2].Hit {
+		t.Error("expected gets to record whether the key was found")
+	}
+}
+
+func TestWithAuditLogWhenOnlySomeOpsAreAudited(t *testing.T) {
+	var buffer bytes.Buffer
+	cache := NewCache(WithAuditLog(&buffer, OpSet|OpDelete))
+	cache.Set("1", "value")
+	cache.Get("1")
+	cache.Take("1")
+	cache.Set("2", "value")
+	cache.SoftDelete("2", time.Hour)
+	records := readAuditRecords(t, &buffer)
+	if len(records) != 4 || records[0].Op != "set" || records[1].Op != "delete" || records[3].Op != "delete" {
+		t.Errorf("expected only sets and deletes to be audited, got %+v", records)
+	}
+}
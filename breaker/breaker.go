@@ -0,0 +1,188 @@
+// Package breaker implements circuit breakers whose state is stored in a gocache.Cache, so that calls to a failing
+// dependency are rejected early instead of piling up, and so that the breakers live alongside the cached data of the
+// dependencies they protect.
+//
+// Each dependency, identified by a key, has its own circuit. The circuit is closed until the number of failures
+// within the failure window (see WithFailureWindow) reaches the threshold (see WithFailureThreshold), at which point it
+// opens and every call is rejected with ErrOpen for the open duration (see WithOpenDuration). The circuit is then
+// half-open: a single trial call is let through, which closes the circuit if it succeeds, and opens it again if it
+// fails.
+//
+// Usage:
+//
+//	b := breaker.New(cache)
+//	err := b.Execute("payments-api", func() error {
+//		return callPaymentsAPI()
+//	})
+//	if errors.Is(err, breaker.ErrOpen) {
+//		// Fail fast, or fall back to a cached value
+//	}
+package breaker
+
+import (
+	"errors"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+const (
+	// KeyPrefix is the prefix of the keys used to store the state of the circuits in the cache
+	KeyPrefix = "breaker:"
+
+	// DefaultFailureThreshold is the number of failures that opens a circuit if WithFailureThreshold is not used
+	DefaultFailureThreshold = 5
+
+	// DefaultFailureWindow is the window failures are counted in if WithFailureWindow is not used
+	DefaultFailureWindow = time.Minute
+
+	// DefaultOpenDuration is how long a circuit stays open if WithOpenDuration is not used
+	DefaultOpenDuration = 30 * time.Second
+)
+
+// ErrOpen is returned by Execute when the circuit is open, or half-open with a trial call already in flight
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit
+type State int
+
+const (
+	Closed   State = iota // Calls are let through, and their failures are counted
+	Open                  // Calls are rejected with ErrOpen
+	HalfOpen              // A single trial call is let through to decide whether the circuit should close again
+)
+
+// String returns the name of the state
+func (state State) String() string {
+	switch state {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker manages the circuits of the dependencies
+type Breaker struct {
+	cache            *gocache.Cache
+	failureThreshold int
+	failureWindow    time.Duration
+	openDuration     time.Duration
+}
+
+// Option is an option for New
+type Option func(breaker *Breaker)
+
+// WithFailureThreshold sets the number of failures within the failure window that opens a circuit. A threshold of 0
+// or less is treated as 1. Defaults to DefaultFailureThreshold
+func WithFailureThreshold(threshold int) Option {
+	return func(breaker *Breaker) {
+		if threshold < 1 {
+			threshold = 1
+		}
+		breaker.failureThreshold = threshold
+	}
+}
+
+// WithFailureWindow sets the window failures are counted in. The window starts with the first failure, and the
+// failures are forgotten once it is over if they didn't open the circuit. Defaults to DefaultFailureWindow
+func WithFailureWindow(window time.Duration) Option {
+	return func(breaker *Breaker) {
+		breaker.failureWindow = window
+	}
+}
+
+// WithOpenDuration sets how long a circuit stays open before a trial call is let through. Defaults to
+// DefaultOpenDuration
+func WithOpenDuration(duration time.Duration) Option {
+	return func(breaker *Breaker) {
+		breaker.openDuration = duration
+	}
+}
+
+// New creates a Breaker which stores the state of its circuits in the given cache
+//
+// The state of a circuit may be evicted if the cache is full, in which case the circuit is closed again, so the cache
+// should have enough room for a few entries per dependency.
+func New(cache *gocache.Cache, opts ...Option) *Breaker {
+	breaker := &Breaker{
+		cache:            cache,
+		failureThreshold: DefaultFailureThreshold,
+		failureWindow:    DefaultFailureWindow,
+		openDuration:     DefaultOpenDuration,
+	}
+	for _, opt := range opts {
+		opt(breaker)
+	}
+	return breaker
+}
+
+// Execute calls fn unless the circuit of the given key is open, in which case ErrOpen is returned without calling
+// fn, and records whether fn failed. Returns the error returned by fn.
+func (breaker *Breaker) Execute(key string, fn func() error) error {
+	state := breaker.State(key)
+	if state == Open {
+		return ErrOpen
+	}
+	if state == HalfOpen {
+		// Only the first call since the circuit became half-open is let through. If it never completes, another
+		// trial call is let through after the open duration.
+		if trials, err := breaker.cache.IncrementWithTTL(breaker.stateKey(key, "trial"), 1, breaker.openDuration); err == nil && trials > 1 {
+			return ErrOpen
+		}
+	}
+	err := fn()
+	if err == nil {
+		if state == HalfOpen {
+			breaker.Reset(key)
+		}
+		return nil
+	}
+	if state == HalfOpen {
+		breaker.trip(key)
+		return err
+	}
+	failures, incrementErr := breaker.cache.IncrementWithTTL(breaker.stateKey(key, "failures"), 1, breaker.failureWindow)
+	if incrementErr == nil && failures >= int64(breaker.failureThreshold) {
+		breaker.trip(key)
+	}
+	return err
+}
+
+// State returns the state of the circuit of the given key
+func (breaker *Breaker) State(key string) State {
+	if _, ok := breaker.cache.Get(breaker.stateKey(key, "open")); ok {
+		return Open
+	}
+	if _, ok := breaker.cache.Get(breaker.stateKey(key, "tripped")); ok {
+		return HalfOpen
+	}
+	return Closed
+}
+
+// Reset closes the circuit of the given key and forgets its failures
+func (breaker *Breaker) Reset(key string) {
+	breaker.cache.DeleteAll([]string{
+		breaker.stateKey(key, "open"),
+		breaker.stateKey(key, "tripped"),
+		breaker.stateKey(key, "trial"),
+		breaker.stateKey(key, "failures"),
+	})
+}
+
+// trip opens the circuit of the given key
+func (breaker *Breaker) trip(key string) {
+	_ = breaker.cache.SetWithTTL(breaker.stateKey(key, "open"), true, breaker.openDuration)
+	// The circuit is half-open once it is tripped but no longer open
+	_ = breaker.cache.Set(breaker.stateKey(key, "tripped"), true)
+	breaker.cache.DeleteAll([]string{breaker.stateKey(key, "trial"), breaker.stateKey(key, "failures")})
+}
+
+// stateKey returns the key of a part of the state of the circuit of the given key
+func (breaker *Breaker) stateKey(key, part string) string {
+	return KeyPrefix + part + ":" + key
+}
@@ -0,0 +1,125 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+var errDependency = errors.New("dependency is down")
+
+func failing() error {
+	return errDependency
+}
+
+func succeeding() error {
+	return nil
+}
+
+func TestBreaker_Execute(t *testing.T) {
+	b := New(gocache.NewCache(), WithFailureThreshold(3), WithOpenDuration(50*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		if err := b.Execute("api", failing); err != errDependency {
+			t.Fatal("expected the error of the dependency, got", err)
+		}
+	}
+	if b.State("api") != Open {
+		t.Error("expected the circuit to be open, got", b.State("api"))
+	}
+	called := false
+	if err := b.Execute("api", func() error { called = true; return nil }); err != ErrOpen || called {
+		t.Error("expected the call to have been rejected, got", err)
+	}
+	if b.State("other-api") != Closed {
+		t.Error("expected the circuits of other keys to be independent")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if b.State("api") != HalfOpen {
+		t.Error("expected the circuit to be half-open, got", b.State("api"))
+	}
+	if err := b.Execute("api", succeeding); err != nil {
+		t.Error("expected the trial call to have been let through, got", err)
+	}
+	if b.State("api") != Closed {
+		t.Error("expected the circuit to have been closed by the successful trial call, got", b.State("api"))
+	}
+	if err := b.Execute("api", failing); err != errDependency || b.State("api") != Closed {
+		t.Error("expected the failures to have been forgotten once the circuit closed")
+	}
+}
+
+func TestBreaker_ExecuteWithFailedTrialCall(t *testing.T) {
+	b := New(gocache.NewCache(), WithFailureThreshold(1), WithOpenDuration(50*time.Millisecond))
+	b.Execute("api", failing)
+	time.Sleep(60 * time.Millisecond)
+	if err := b.Execute("api", failing); err != errDependency {
+		t.Error("expected the trial call to have been let through, got", err)
+	}
+	if b.State("api") != Open {
+		t.Error("expected the circuit to have been opened again, got", b.State("api"))
+	}
+}
+
+func TestBreaker_ExecuteLetsSingleTrialCallThrough(t *testing.T) {
+	b := New(gocache.NewCache(), WithFailureThreshold(1), WithOpenDuration(50*time.Millisecond))
+	b.Execute("api", failing)
+	time.Sleep(60 * time.Millisecond)
+	release := make(chan struct{})
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Execute("api", func() error {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return nil
+			})
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Error("expected a single trial call to have been let through, got", calls)
+	}
+	if b.State("api") != Closed {
+		t.Error("expected the circuit to have been closed, got", b.State("api"))
+	}
+}
+
+func TestBreaker_ExecuteForgetsFailuresAfterWindow(t *testing.T) {
+	b := New(gocache.NewCache(), WithFailureThreshold(2), WithFailureWindow(50*time.Millisecond))
+	b.Execute("api", failing)
+	time.Sleep(60 * time.Millisecond)
+	b.Execute("api", failing)
+	if b.State("api") != Closed {
+		t.Error("expected failures from a previous window not to count, got", b.State("api"))
+	}
+	b.Execute("api", failing)
+	if b.State("api") != Open {
+		t.Error("expected the circuit to be open, got", b.State("api"))
+	}
+}
+
+func TestBreaker_Reset(t *testing.T) {
+	b := New(gocache.NewCache(), WithFailureThreshold(1))
+	b.Execute("api", failing)
+	b.Reset("api")
+	if b.State("api") != Closed {
+		t.Error("expected the circuit to have been closed, got", b.State("api"))
+	}
+}
+
+func TestState_String(t *testing.T) {
+	for state, expected := range map[State]string{Closed: "closed", Open: "open", HalfOpen: "half-open", State(42): "unknown"} {
+		if state.String() != expected {
+			t.Errorf("expected %s, got %s", expected, state.String())
+		}
+	}
+}
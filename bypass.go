@@ -0,0 +1,36 @@
+package gocache
+
+import "context"
+
+type bypassContextKey struct{}
+
+type forceRefreshContextKey struct{}
+
+// WithBypass returns a copy of ctx that makes the cache be skipped by the operations it is passed to, as if it were
+// empty: GetCtx always misses, and GetOrRefreshCtx always calls refresh without caching the value it returns.
+//
+// This allows individual requests to skip the cache, for instance for debugging, without changing the call sites.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassContextKey{}, true)
+}
+
+// WithForceRefresh returns a copy of ctx that forces the operations it is passed to to revalidate the cached value:
+// GetCtx always misses, and GetOrRefreshCtx always calls refresh and caches the value it returns.
+//
+// This allows individual requests to get a fresh value, for instance when a user hits shift-reload, without changing
+// the call sites.
+func WithForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshContextKey{}, true)
+}
+
+// bypassed returns whether ctx was returned by WithBypass
+func bypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassContextKey{}).(bool)
+	return bypass
+}
+
+// forceRefreshed returns whether ctx was returned by WithForceRefresh
+func forceRefreshed(ctx context.Context) bool {
+	forceRefresh, _ := ctx.Value(forceRefreshContextKey{}).(bool)
+	return forceRefresh
+}
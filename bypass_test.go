@@ -0,0 +1,67 @@
+package gocache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCache_GetCtxWithBypass(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	if _, ok := cache.GetCtx(WithBypass(context.Background()), "key"); ok {
+		t.Error("expected the cache to have been bypassed")
+	}
+	if _, ok := cache.GetCtx(WithForceRefresh(context.Background()), "key"); ok {
+		t.Error("expected a forced refresh to miss")
+	}
+	if value, ok := cache.GetCtx(context.Background(), "key"); !ok || value != "value" {
+		t.Errorf("expected value, got %v", value)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 0 {
+		t.Errorf("expected bypassed lookups not to count in the statistics, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCache_GetOrRefreshCtxWithBypass(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "cached")
+	ctx := WithBypass(context.Background())
+	value, err := cache.GetOrRefreshCtx(ctx, "key", time.Hour, func(string) (interface{}, error) {
+		return "fresh", nil
+	})
+	if err != nil || value != "fresh" {
+		t.Errorf("expected fresh, got %v and %v", value, err)
+	}
+	if cached, _ := cache.Get("key"); cached != "cached" {
+		t.Errorf("expected the fresh value not to have been cached, got %v", cached)
+	}
+	refreshErr := errors.New("failed")
+	if _, err := cache.GetOrRefreshCtx(ctx, "key", time.Hour, func(string) (interface{}, error) {
+		return nil, refreshErr
+	}); err != refreshErr {
+		t.Errorf("expected the error not to be replaced by the cached value, got %v", err)
+	}
+}
+
+func TestCache_GetOrRefreshCtxWithForceRefresh(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "cached")
+	ctx := WithForceRefresh(context.Background())
+	value, err := cache.GetOrRefreshCtx(ctx, "key", time.Hour, func(string) (interface{}, error) {
+		return "fresh", nil
+	})
+	if err != nil || value != "fresh" {
+		t.Errorf("expected fresh, got %v and %v", value, err)
+	}
+	if cached, _ := cache.Get("key"); cached != "fresh" {
+		t.Errorf("expected the fresh value to have been cached, got %v", cached)
+	}
+	value, err = cache.GetOrRefreshCtx(ctx, "key", time.Hour, func(string) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	if err != nil || value != "fresh" {
+		t.Errorf("expected the cached value to be served when the refresh fails, got %v and %v", value, err)
+	}
+}
@@ -0,0 +1,139 @@
+// Package cachefs provides an fs.FS that serves the content of files from a gocache.Cache, and only reads them from
+// the underlying file system when they are not cached or when their modification time has changed.
+//
+// File contents are stored as []byte, so configuring the cache with gocache.WithMaxMemoryUsage bounds the amount of
+// memory used by cached files, and the least useful files are evicted according to the cache's eviction policy.
+//
+// Usage:
+//
+//	fsys := cachefs.New(os.DirFS("templates"), gocache.NewCache(gocache.WithMaxMemoryUsage(50*gocache.Megabyte)))
+//	tmpl, err := template.ParseFS(fsys, "*.html")
+package cachefs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	gocache "github.com/arham09/cache"
+)
+
+const (
+	// KeyPrefix is the prefix of the keys used to store file contents in the cache
+	KeyPrefix = "cachefs:"
+
+	// DefaultMaxFileSize is the size above which files are not cached if WithMaxFileSize is not used
+	DefaultMaxFileSize = gocache.Megabyte
+)
+
+// FS is an fs.FS which caches the content of the files of another fs.FS
+type FS struct {
+	fsys        fs.FS
+	cache       *gocache.Cache
+	maxFileSize int64
+
+	// mutex guards keys
+	mutex sync.Mutex
+
+	// keys contains the key under which the content of each file is currently cached, which includes its
+	// modification time
+	keys map[string]string
+}
+
+// Option is an option for New
+type Option func(fsys *FS)
+
+// WithMaxFileSize sets the size above which files are read directly from the underlying file system instead of being
+// cached. Defaults to DefaultMaxFileSize
+func WithMaxFileSize(maxFileSize int64) Option {
+	return func(fsys *FS) {
+		fsys.maxFileSize = maxFileSize
+	}
+}
+
+// New creates an FS which caches the content of the files of the given fs.FS in the given cache
+func New(fsys fs.FS, cache *gocache.Cache, opts ...Option) *FS {
+	cachedFS := &FS{
+		fsys:        fsys,
+		cache:       cache,
+		maxFileSize: DefaultMaxFileSize,
+		keys:        make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(cachedFS)
+	}
+	return cachedFS
+}
+
+// Open opens the named file
+//
+// The file is always stat'ed on the underlying file system in order to detect modifications, but its content is only
+// read if it isn't cached or if it has been modified since it was cached. Directories and files bigger than the
+// maximum file size are opened directly from the underlying file system.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	info, data, err := fsys.read(name)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return fsys.fsys.Open(name)
+	}
+	return &file{Reader: bytes.NewReader(data), info: info}, nil
+}
+
+// ReadFile reads the named file and returns its content, which implements fs.ReadFileFS
+func (fsys *FS) ReadFile(name string) ([]byte, error) {
+	_, data, err := fsys.read(name)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return fs.ReadFile(fsys.fsys, name)
+	}
+	// The caller is allowed to modify the slice returned, so it must not be the one in the cache
+	return append([]byte(nil), data...), nil
+}
+
+// read returns the FileInfo and the content of the named file, or a nil content if the file must not be cached
+func (fsys *FS) read(name string) (fs.FileInfo, []byte, error) {
+	info, err := fs.Stat(fsys.fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() || info.Size() > fsys.maxFileSize {
+		return info, nil, nil
+	}
+	key := fmt.Sprintf("%s%s@%d", KeyPrefix, name, info.ModTime().UnixNano())
+	if value, ok := fsys.cache.Get(key); ok {
+		return info, value.([]byte), nil
+	}
+	data, err := fs.ReadFile(fsys.fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	fsys.mutex.Lock()
+	previousKey, hasPreviousKey := fsys.keys[name]
+	fsys.keys[name] = key
+	fsys.mutex.Unlock()
+	if hasPreviousKey && previousKey != key {
+		// The file was modified, so the previous content will never be served again
+		fsys.cache.Delete(previousKey)
+	}
+	_ = fsys.cache.Set(key, data)
+	return info, data, nil
+}
+
+// file is a file whose content is cached
+type file struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *file) Close() error {
+	return nil
+}
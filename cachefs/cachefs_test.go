@@ -0,0 +1,77 @@
+package cachefs
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+func TestFS(t *testing.T) {
+	underlying := fstest.MapFS{
+		"index.html":     {Data: []byte("<h1>hello</h1>"), ModTime: time.Unix(1, 0)},
+		"assets/app.css": {Data: []byte("body{}"), ModTime: time.Unix(1, 0)},
+		"big.bin":        {Data: []byte(strings.Repeat("0", 100)), ModTime: time.Unix(1, 0)},
+	}
+	cache := gocache.NewCache()
+	fsys := New(underlying, cache, WithMaxFileSize(50))
+	if err := fstest.TestFS(fsys, "index.html", "assets/app.css", "big.bin"); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected index.html and assets/app.css to be cached, got %d entries", cache.Count())
+	}
+}
+
+func TestFS_ReadFileServesFromCache(t *testing.T) {
+	underlying := fstest.MapFS{"index.html": {Data: []byte("v1"), ModTime: time.Unix(1, 0)}}
+	cache := gocache.NewCache()
+	fsys := New(underlying, cache)
+	data, err := fs.ReadFile(fsys, "index.html")
+	if err != nil || string(data) != "v1" {
+		t.Fatalf("expected v1, got %s and %v", data, err)
+	}
+	// Changing the content without changing the modification time means the cached content is still served
+	underlying["index.html"].Data = []byte("v2")
+	if data, _ = fs.ReadFile(fsys, "index.html"); string(data) != "v1" {
+		t.Errorf("expected the cached content v1 to be served, got %s", data)
+	}
+	// Modifying the slice returned must not modify the cached content
+	data[0] = 'x'
+	if data, _ = fs.ReadFile(fsys, "index.html"); string(data) != "v1" {
+		t.Errorf("expected the cached content not to have been modified, got %s", data)
+	}
+	underlying["index.html"].ModTime = time.Unix(2, 0)
+	if data, _ = fs.ReadFile(fsys, "index.html"); string(data) != "v2" {
+		t.Errorf("expected v2 to be served after the modification time changed, got %s", data)
+	}
+	if cache.Count() != 1 {
+		t.Errorf("expected the previous content to have been removed from the cache, got %d entries", cache.Count())
+	}
+}
+
+func TestFS_Open(t *testing.T) {
+	underlying := fstest.MapFS{"index.html": {Data: []byte("hello"), ModTime: time.Unix(1, 0)}}
+	fsys := New(underlying, gocache.NewCache())
+	for i := 0; i < 2; i++ {
+		f, err := fsys.Open("index.html")
+		if err != nil {
+			t.Fatal("expected no error, got", err)
+		}
+		data, _ := io.ReadAll(f)
+		if string(data) != "hello" {
+			t.Errorf("expected hello, got %s", data)
+		}
+		if info, _ := f.Stat(); info.Name() != "index.html" || info.Size() != 5 {
+			t.Error("expected Stat to return the FileInfo of the underlying file, got", info)
+		}
+		f.Close()
+	}
+	if _, err := fsys.Open("missing.html"); err == nil {
+		t.Error("expected an error when opening a file that does not exist")
+	}
+}
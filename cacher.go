@@ -0,0 +1,22 @@
+package gocache
+
+import "time"
+
+// Cacher is the subset of the functions of Cache that most code using a cache needs, which allows such code to
+// depend on an interface and be unit tested with a fake, such as the one of the gocachetest package
+type Cacher interface {
+	Get(key string) (interface{}, bool)
+	GetByKeys(keys []string) map[string]interface{}
+	GetOrRefresh(key string, ttl time.Duration, refresh func(key string) (interface{}, error)) (interface{}, error)
+	Set(key string, value interface{}) error
+	SetWithTTL(key string, value interface{}, ttl time.Duration) error
+	Delete(key string) bool
+	DeleteAll(keys []string) int
+	TTL(key string) (time.Duration, error)
+	Expire(key string, ttl time.Duration) bool
+	Count() int
+	Clear()
+	Stats() Statistics
+}
+
+var _ Cacher = (*Cache)(nil)
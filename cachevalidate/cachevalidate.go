@@ -0,0 +1,323 @@
+// Package cachevalidate provides a test helper that cross-checks every operation made on a gocache.Cache against a
+// reference implementation, so that bugs in the cache's bookkeeping (linked list, eviction order, expiration) are
+// caught on the very operation that triggers them.
+//
+// The reference implementation is deliberately naive: a map for the values and a slice for the order in which
+// entries will be evicted.
+//
+// Usage:
+//
+//	func TestSomething(t *testing.T) {
+//		v := cachevalidate.New(t, gocache.NewCache(gocache.WithMaxSize(3), gocache.WithEvictionPolicy(gocache.LeastRecentlyUsed)))
+//		v.Set("1", "value")
+//		v.Get("1")
+//	}
+//
+// Caches configured with a MaxMemoryUsage are not supported, as the reference implementation only models evictions
+// caused by the MaxSize.
+package cachevalidate
+
+import (
+	"fmt"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+// TB is the subset of testing.TB used by the Validator
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// modelEntry is an entry of the reference implementation
+type modelEntry struct {
+	value interface{}
+
+	// earliestExpiration and latestExpiration bound the expiration of the entry, since the exact time at which
+	// the cache computed it is unknown. Both are zero if the entry has no expiration.
+	earliestExpiration time.Time
+	latestExpiration   time.Time
+
+	// frequency is the number of times the entry has been accessed, only relevant if the eviction policy is
+	// gocache.LeastFrequentUsed
+	frequency int
+}
+
+// Validator wraps a gocache.Cache and reports, through a TB, every operation whose result differs from the result
+// of the same operation on the reference implementation
+type Validator struct {
+	t     TB
+	cache *gocache.Cache
+
+	// entries is the content of the reference implementation
+	entries map[string]*modelEntry
+
+	// order contains the keys of the entries, from the head (index 0) to the tail
+	order []string
+
+	// evictedKeys is the number of keys that the reference implementation has evicted
+	evictedKeys uint64
+}
+
+// New creates a Validator for the given cache, which must be empty and must not be used directly for as long as the
+// Validator is in use
+func New(t TB, cache *gocache.Cache) *Validator {
+	t.Helper()
+	if cache.Count() != 0 {
+		t.Fatalf("cachevalidate: the cache must be empty, but has %d entries", cache.Count())
+	}
+	if cache.MaxMemoryUsage() != gocache.NoMaxMemoryUsage {
+		t.Fatalf("cachevalidate: caches with a MaxMemoryUsage are not supported")
+	}
+	return &Validator{
+		t:       t,
+		cache:   cache,
+		entries: make(map[string]*modelEntry),
+	}
+}
+
+// Cache returns the cache wrapped by the Validator
+func (v *Validator) Cache() *gocache.Cache {
+	return v.cache
+}
+
+// Set calls Cache.Set and validates its result
+func (v *Validator) Set(key string, value interface{}) error {
+	v.t.Helper()
+	return v.SetWithTTL(key, value, gocache.NoExpiration)
+}
+
+// SetWithTTL calls Cache.SetWithTTL and validates its result
+func (v *Validator) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	v.t.Helper()
+	before := time.Now()
+	err := v.cache.SetWithTTL(key, value, ttl)
+	after := time.Now()
+	entry, exists := v.entries[key]
+	if ttl != gocache.NoExpiration && ttl < 1 {
+		if exists {
+			v.remove(key)
+		}
+		v.expectError("SetWithTTL", key, err, nil)
+		v.check("SetWithTTL", key)
+		return err
+	}
+	if !exists && v.cache.FullBehavior() == gocache.RejectWrites && v.cache.MaxSize() != gocache.NoMaxSize && len(v.entries) >= v.cache.MaxSize() {
+		v.expectError("SetWithTTL", key, err, gocache.ErrCacheFull)
+		v.check("SetWithTTL", key)
+		return err
+	}
+	v.expectError("SetWithTTL", key, err, nil)
+	if !exists {
+		entry = &modelEntry{}
+		v.entries[key] = entry
+	}
+	entry.value = value
+	if ttl == gocache.NoExpiration {
+		entry.earliestExpiration, entry.latestExpiration = time.Time{}, time.Time{}
+	} else {
+		entry.earliestExpiration, entry.latestExpiration = before.Add(ttl), after.Add(ttl)
+	}
+	v.moveToHead(key)
+	if v.cache.MaxSize() != gocache.NoMaxSize && len(v.entries) > v.cache.MaxSize() {
+		v.evict()
+	}
+	if _, stillExists := v.entries[key]; stillExists && v.cache.EvictionPolicy() == gocache.LeastFrequentUsed {
+		entry.frequency++
+	}
+	v.check("SetWithTTL", key)
+	return err
+}
+
+// Get calls Cache.Get and validates its result
+func (v *Validator) Get(key string) (interface{}, bool) {
+	v.t.Helper()
+	before := time.Now()
+	value, ok := v.cache.Get(key)
+	after := time.Now()
+	entry, exists := v.entries[key]
+	if !exists {
+		if ok {
+			v.t.Errorf("cachevalidate: Get(%q) returned a value, but the key should not exist", key)
+		}
+		v.check("Get", key)
+		return value, ok
+	}
+	switch v.expiration(entry, before, after) {
+	case expired:
+		if ok {
+			v.t.Errorf("cachevalidate: Get(%q) returned a value, but the key should have expired", key)
+		}
+		v.remove(key)
+	case notExpired:
+		if !ok {
+			v.t.Errorf("cachevalidate: Get(%q) returned no value, but the key should exist", key)
+		} else {
+			v.hit(key, entry)
+		}
+	default:
+		// The key expired at about the same time as it was retrieved, so either outcome is correct
+		if ok {
+			v.hit(key, entry)
+		} else {
+			v.remove(key)
+		}
+	}
+	if ok && value != entry.value {
+		v.t.Errorf("cachevalidate: Get(%q) returned %v, expected %v", key, value, entry.value)
+	}
+	v.check("Get", key)
+	return value, ok
+}
+
+// Delete calls Cache.Delete and validates its result
+func (v *Validator) Delete(key string) bool {
+	v.t.Helper()
+	deleted := v.cache.Delete(key)
+	_, exists := v.entries[key]
+	if deleted != exists {
+		v.t.Errorf("cachevalidate: Delete(%q) returned %v, expected %v", key, deleted, exists)
+	}
+	v.remove(key)
+	v.check("Delete", key)
+	return deleted
+}
+
+// Take calls Cache.Take and validates its result
+func (v *Validator) Take(key string) (interface{}, bool) {
+	v.t.Helper()
+	before := time.Now()
+	value, ok := v.cache.Take(key)
+	after := time.Now()
+	if entry, exists := v.entries[key]; exists {
+		state := v.expiration(entry, before, after)
+		if ok && state == expired {
+			v.t.Errorf("cachevalidate: Take(%q) returned a value, but the key should have expired", key)
+		} else if !ok && state == notExpired {
+			v.t.Errorf("cachevalidate: Take(%q) returned no value, but the key should exist", key)
+		} else if ok && value != entry.value {
+			v.t.Errorf("cachevalidate: Take(%q) returned %v, expected %v", key, value, entry.value)
+		}
+		v.remove(key)
+	} else if ok {
+		v.t.Errorf("cachevalidate: Take(%q) returned a value, but the key should not exist", key)
+	}
+	v.check("Take", key)
+	return value, ok
+}
+
+// Clear calls Cache.Clear and validates its result
+func (v *Validator) Clear() {
+	v.t.Helper()
+	v.cache.Clear()
+	v.entries = make(map[string]*modelEntry)
+	v.order = nil
+	v.check("Clear", "")
+}
+
+// hit updates the reference implementation after the entry with the given key has been successfully retrieved
+func (v *Validator) hit(key string, entry *modelEntry) {
+	switch v.cache.EvictionPolicy() {
+	case gocache.LeastRecentlyUsed:
+		v.moveToHead(key)
+	case gocache.LeastFrequentUsed:
+		entry.frequency++
+	}
+}
+
+// evict removes the entries that the cache is expected to evict in order to make room for a new entry
+func (v *Validator) evict() {
+	if v.cache.EvictionPolicy() != gocache.LeastFrequentUsed {
+		tail := v.order[len(v.order)-1]
+		v.remove(tail)
+		v.evictedKeys++
+		return
+	}
+	// With LFU, every entry that has the lowest frequency is evicted at once. A newly created entry has yet to be
+	// given a frequency, so it cannot be evicted.
+	lowestFrequency := 0
+	for _, entry := range v.entries {
+		if entry.frequency > 0 && (lowestFrequency == 0 || entry.frequency < lowestFrequency) {
+			lowestFrequency = entry.frequency
+		}
+	}
+	if lowestFrequency == 0 {
+		return
+	}
+	for k, entry := range v.entries {
+		if entry.frequency == lowestFrequency {
+			v.remove(k)
+			v.evictedKeys++
+		}
+	}
+}
+
+// moveToHead moves the given key to the head of the order, adding it if it isn't already there
+func (v *Validator) moveToHead(key string) {
+	v.removeFromOrder(key)
+	v.order = append([]string{key}, v.order...)
+}
+
+// remove removes the given key from the reference implementation
+func (v *Validator) remove(key string) {
+	delete(v.entries, key)
+	v.removeFromOrder(key)
+}
+
+func (v *Validator) removeFromOrder(key string) {
+	for i, k := range v.order {
+		if k == key {
+			v.order = append(v.order[:i], v.order[i+1:]...)
+			return
+		}
+	}
+}
+
+type expirationState int
+
+const (
+	notExpired expirationState = iota
+	expired
+	maybeExpired
+)
+
+// expiration returns whether the given entry is expected to have expired when checked at some point between before
+// and after
+func (v *Validator) expiration(entry *modelEntry, before, after time.Time) expirationState {
+	if entry.latestExpiration.IsZero() {
+		return notExpired
+	}
+	if before.After(entry.latestExpiration) {
+		return expired
+	}
+	if !after.After(entry.earliestExpiration) {
+		return notExpired
+	}
+	return maybeExpired
+}
+
+// expectError reports an error if the error returned by an operation is not the one expected
+func (v *Validator) expectError(operation, key string, err, expected error) {
+	v.t.Helper()
+	if err != expected {
+		v.t.Errorf("cachevalidate: %s(%q) returned error %v, expected %v", operation, key, err, expected)
+	}
+}
+
+// check compares the state of the cache with the state of the reference implementation
+func (v *Validator) check(operation, key string) {
+	v.t.Helper()
+	if count := v.cache.Count(); count != len(v.entries) {
+		v.t.Errorf("cachevalidate: after %s(%q), the cache has %d entries, expected %d %s", operation, key, count, len(v.entries), v)
+	}
+	if evictedKeys := v.cache.Stats().EvictedKeys; evictedKeys != v.evictedKeys {
+		v.t.Errorf("cachevalidate: after %s(%q), the cache has evicted %d keys, expected %d %s", operation, key, evictedKeys, v.evictedKeys, v)
+	}
+}
+
+// String returns the order of the keys in the reference implementation, from head to tail
+func (v *Validator) String() string {
+	return fmt.Sprintf("(expected order from head to tail: %v)", v.order)
+}
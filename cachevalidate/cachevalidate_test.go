@@ -0,0 +1,77 @@
+package cachevalidate
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+func TestValidator(t *testing.T) {
+	for _, evictionPolicy := range []gocache.EvictionPolicy{gocache.FirstInFirstOut, gocache.LeastRecentlyUsed, gocache.LeastFrequentUsed} {
+		for _, fullBehavior := range []gocache.FullBehavior{gocache.EvictTail, gocache.RejectWrites} {
+			t.Run(fmt.Sprintf("%d-%d", evictionPolicy, fullBehavior), func(t *testing.T) {
+				v := New(t, gocache.NewCache(gocache.WithMaxSize(5), gocache.WithEvictionPolicy(evictionPolicy), gocache.WithFullBehavior(fullBehavior)))
+				r := rand.New(rand.NewSource(int64(evictionPolicy)))
+				for i := 0; i < 5000; i++ {
+					key := strconv.Itoa(r.Intn(10))
+					switch r.Intn(7) {
+					case 0, 1:
+						v.Set(key, i)
+					case 2:
+						v.SetWithTTL(key, i, time.Duration(r.Intn(3))*time.Millisecond)
+					case 3, 4:
+						v.Get(key)
+					case 5:
+						v.Delete(key)
+					case 6:
+						v.Take(key)
+					}
+					if i%1000 == 0 {
+						v.Clear()
+					}
+				}
+			})
+		}
+	}
+}
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestValidatorWhenCacheIsNotEmpty(t *testing.T) {
+	cache := gocache.NewCache()
+	cache.Set("key", "value")
+	r := &recorder{}
+	New(r, cache)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "must be empty") {
+		t.Error("expected the Validator to report that the cache is not empty, got", r.errors)
+	}
+}
+
+func TestValidatorWhenCacheMisbehaves(t *testing.T) {
+	r := &recorder{}
+	v := New(r, gocache.NewCache(gocache.WithMaxSize(2)))
+	v.Set("1", "value")
+	// Bypass the Validator so that the reference implementation no longer matches the cache
+	v.Cache().Delete("1")
+	v.Get("1")
+	if len(r.errors) == 0 {
+		t.Error("expected the Validator to report that the key should exist")
+	}
+}
@@ -0,0 +1,54 @@
+package gocache
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+)
+
+// ErrChecksumMismatch is passed to CorruptionObserver.OnCorruption when the checksum of a persisted value does not
+// match the value
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// CorruptionObserver can be implemented by Hooks to be notified of the persisted entries found to be corrupted when
+// they are loaded (see WithChecksums), for instance to alert on bit rot in the storage they were persisted to.
+//
+// OnCorruption is called without holding the lock, with the key of the corrupted entry and the reason it was deemed
+// corrupted.
+type CorruptionObserver interface {
+	OnCorruption(key string, err error)
+}
+
+// WithChecksums sets whether a CRC-32 checksum of each value is persisted along with it by SaveHotKeys, ExportKeys and
+// ExportByTag, and verified by LoadHotKeys, which skips the entries whose checksum does not match their value, counts
+// them in Statistics.CorruptedEntries and reports them to the Hooks if they implement CorruptionObserver.
+//
+// Entries persisted without checksum, for instance before checksums were enabled, are loaded without verification.
+// Defaults to false
+func WithChecksums(checksums bool) func(c *Cache) {
+	return func(c *Cache) {
+		c.checksums = checksums
+	}
+}
+
+// rawExportedEntry is an ExportedEntry whose value has not been decoded yet, so that its checksum can be verified
+// against the exact bytes that were persisted
+type rawExportedEntry struct {
+	ExportedEntry
+	Value json.RawMessage `json:"value"`
+}
+
+// verifyChecksum returns ErrChecksumMismatch if the entry has a checksum which does not match its value, in which case
+// the corruption is counted and reported to the CorruptionObserver, if any
+func (c *Cache) verifyChecksum(entry rawExportedEntry) error {
+	if !c.checksums || entry.Checksum == nil || crc32.ChecksumIEEE(entry.Value) == *entry.Checksum {
+		return nil
+	}
+	c.mutex.Lock()
+	c.stats.CorruptedEntries++
+	c.mutex.Unlock()
+	if c.corruptionObserver != nil {
+		c.corruptionObserver.OnCorruption(entry.Key, ErrChecksumMismatch)
+	}
+	return ErrChecksumMismatch
+}
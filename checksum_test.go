@@ -0,0 +1,71 @@
+package gocache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type corruptionRecordingHooks struct {
+	NoopHooks
+	corrupted []string
+}
+
+func (hooks *corruptionRecordingHooks) OnCorruption(key string, err error) {
+	hooks.corrupted = append(hooks.corrupted, key+": "+err.Error())
+}
+
+func TestWithChecksums(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotkeys.jsonl")
+	cache := NewCache(WithChecksums(true))
+	cache.Set("a", "apple")
+	cache.Set("b", map[string]interface{}{"name": "banana", "count": 12345678901234567})
+	if _, err := cache.SaveHotKeys(path, 0); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), `"checksum":`) != 2 {
+		t.Fatalf("expected every entry to have a checksum, got %s", data)
+	}
+	// Flip a character of the value of a
+	os.WriteFile(path, bytes.Replace(data, []byte(`"apple"`), []byte(`"appla"`), 1), 0o644)
+	hooks := &corruptionRecordingHooks{}
+	restoredCache := NewCache(WithChecksums(true), WithHooks(hooks))
+	numberOfEntriesRestored, err := restoredCache.LoadHotKeys(path)
+	if err != nil || numberOfEntriesRestored != 1 {
+		t.Fatalf("expected 1 entry to have been restored, got %d and %v", numberOfEntriesRestored, err)
+	}
+	if _, ok := restoredCache.Get("a"); ok {
+		t.Error("expected the corrupted entry not to have been restored")
+	}
+	if value, ok := restoredCache.Get("b"); !ok || value.(map[string]interface{})["name"] != "banana" {
+		t.Errorf("expected b to have been restored, got %v", value)
+	}
+	if corrupted := restoredCache.Stats().CorruptedEntries; corrupted != 1 {
+		t.Errorf("expected 1 corrupted entry, got %d", corrupted)
+	}
+	if len(hooks.corrupted) != 1 || hooks.corrupted[0] != "a: "+ErrChecksumMismatch.Error() {
+		t.Errorf("expected OnCorruption to have been called for a, got %v", hooks.corrupted)
+	}
+	// Without checksums, the corruption goes unnoticed
+	if numberOfEntriesRestored, _ := NewCache().LoadHotKeys(path); numberOfEntriesRestored != 2 {
+		t.Errorf("expected 2 entries to have been restored, got %d", numberOfEntriesRestored)
+	}
+}
+
+func TestWithChecksumsWhenEntriesHaveNoChecksum(t *testing.T) {
+	var buffer bytes.Buffer
+	cache := NewCache()
+	cache.Set("key", "value")
+	cache.ExportKeys(&buffer, "*")
+	if strings.Contains(buffer.String(), "checksum") {
+		t.Errorf("expected no checksum when checksums are disabled, got %s", buffer.String())
+	}
+	path := filepath.Join(t.TempDir(), "hotkeys.jsonl")
+	os.WriteFile(path, buffer.Bytes(), 0o644)
+	if numberOfEntriesRestored, err := NewCache(WithChecksums(true)).LoadHotKeys(path); err != nil || numberOfEntriesRestored != 1 {
+		t.Errorf("expected the entry without checksum to have been restored, got %d and %v", numberOfEntriesRestored, err)
+	}
+}
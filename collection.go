@@ -0,0 +1,133 @@
+package gocache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrWrongType is returned by the functions operating on the values of a specific kind, such as SAdd, when the key
+// holds a value of another kind
+var ErrWrongType = errors.New("key holds a value of the wrong kind")
+
+// collection is implemented by the values that are modified in place under the lock of the cache, such as the sets
+// created through SAdd, rather than replaced by Set
+//
+// Its cost must be updated as it is modified, as it is used to compute the memory usage of the cache.
+type collection interface {
+	Coster
+
+	// Len returns the number of elements in the collection
+	Len() int
+}
+
+// lookupCollection retrieves the collection stored under a key, counting as accessing the entry like Get does
+// If the key doesn't exist or has expired, the collection returned will be the zero value and the boolean will be
+// false. If the key holds a value that isn't a T, ErrWrongType is returned.
+//
+// The caller must hold the lock.
+func lookupCollection[T collection](c *Cache, key string) (T, bool, error) {
+	var zero T
+	value, ok := c.lookup(key)
+	if !ok {
+		return zero, false, nil
+	}
+	coll, ok := value.(T)
+	if !ok {
+		return zero, false, ErrWrongType
+	}
+	return coll, true, nil
+}
+
+// modifyCollection atomically modifies the collection stored under a key in place
+//
+// If the key doesn't exist or has expired, create is called to create an empty collection, which is then stored
+// without expiration (see Expire) if modify adds elements to it. If create is nil, modify is not called at all.
+// modify returns whether it modified the collection, and the key is deleted if the collection is empty afterwards.
+// If the key holds a value that isn't a T, ErrWrongType is returned.
+//
+// Modifying an existing collection counts as updating the entry, however, unlike Set, it keeps its expiration,
+// minimum lifetime and metadata, and it is never rejected when the FullBehavior is RejectWrites: other entries are
+// evicted instead. Hooks are not invoked, and OnSetPattern callbacks are only invoked when the collection is created.
+func modifyCollection[T collection](c *Cache, key string, create func() T, modify func(coll T) (bool, error)) error {
+	storageKey, err := c.writeKey(key)
+	if err != nil {
+		if err == errNeverCached {
+			return nil
+		}
+		return err
+	}
+	callerKey, key := key, storageKey
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.draining {
+		return ErrDraining
+	}
+	entry, ok := c.get(key)
+	if ok && entry.Expired() {
+		c.stats.ExpiredKeys++
+		c.delete(key)
+		c.onExpire(entry)
+		ok = false
+	}
+	if !ok {
+		if create == nil {
+			return nil
+		}
+		coll := create()
+		if modified, err := modify(coll); err != nil || !modified || coll.Len() == 0 {
+			return err
+		}
+		return c.setLocked(callerKey, key, coll, NoExpiration, 0, nil)
+	}
+	coll, ok := entry.Value.(T)
+	if !ok {
+		return ErrWrongType
+	}
+	if c.maxMemoryUsage != NoMaxMemoryUsage {
+		c.memoryUsage -= entry.SizeInBytes()
+	}
+	modified, err := modify(coll)
+	if c.maxMemoryUsage != NoMaxMemoryUsage {
+		c.memoryUsage += entry.SizeInBytes()
+	}
+	if err != nil || !modified {
+		return err
+	}
+	if coll.Len() == 0 {
+		c.delete(key)
+		c.assertInvariants()
+		return nil
+	}
+	if c.traced(key) {
+		c.trace(key, "modify")
+	}
+	c.modified(entry)
+	return nil
+}
+
+// modified updates the position of an entry whose value was modified in place according to the eviction policy,
+// like set does for the entries it updates, and evicts other entries if the memory usage is above the MaxMemoryUsage
+//
+// The caller must hold the lock.
+func (c *Cache) modified(entry *Entry) {
+	entry.RelevantTimestamp = time.Now()
+	entry.updatedAt = entry.RelevantTimestamp.UnixNano()
+	if c.evictionPolicy == Sieve {
+		entry.visited = true
+	} else {
+		c.moveExistingEntryToHead(entry)
+	}
+	if c.maxMemoryUsage != NoMaxMemoryUsage && c.memoryUsage > c.maxMemoryUsage {
+		c.evictUntilBelowMaxMemoryUsage(entry)
+	}
+	if c.evictionPolicy == LeastFrequentUsed && c.entries[entry.Key] == entry {
+		c.incrementEntryFrequency(entry)
+	}
+	if c.evictionPolicy == LRUK && c.entries[entry.Key] == entry {
+		c.recordAccess(entry)
+	}
+	if c.usageAlert != nil {
+		c.checkUsage()
+	}
+	c.assertInvariants()
+}
@@ -0,0 +1,77 @@
+package gocache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestModifyCollection_MemoryUsage(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(Megabyte))
+	cache.SAdd("key", "a", "b")
+	usage := cache.MemoryUsage()
+	if usage != toBytes("key")+toBytes("a")+toBytes("b")+32 {
+		t.Error("expected the memory usage to account for the members, got", usage)
+	}
+	cache.SAdd("key", "c")
+	if cache.MemoryUsage() != usage+toBytes("c") {
+		t.Error("expected the memory usage to have grown by the size of the new member, got", cache.MemoryUsage())
+	}
+	cache.SRem("key", "a", "c")
+	if cache.MemoryUsage() != usage-toBytes("a") {
+		t.Error("expected the memory usage to have shrunk by the size of the removed members, got", cache.MemoryUsage())
+	}
+	cache.SRem("key", "b")
+	if cache.MemoryUsage() != 0 {
+		t.Error("expected the memory usage to be 0 once the set was deleted, got", cache.MemoryUsage())
+	}
+}
+
+func TestModifyCollection_EvictsOtherEntries(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(1000), WithFullBehavior(RejectWrites))
+	cache.Set("other", "value")
+	cache.SAdd("key", "a")
+	for i := 0; i < 100; i++ {
+		if _, err := cache.SAdd("key", fmt.Sprintf("member-%d", i)); err != nil {
+			t.Fatal("expected growing an existing set never to be rejected, got", err)
+		}
+	}
+	if _, ok := cache.Get("other"); ok {
+		t.Error("expected the other entry to have been evicted")
+	}
+	if cache.MemoryUsage() > cache.MaxMemoryUsage() {
+		t.Error("expected the memory usage to be below the maximum, got", cache.MemoryUsage())
+	}
+}
+
+func TestModifyCollection_MovesEntryToHead(t *testing.T) {
+	cache := NewCache(WithMaxSize(2), WithEvictionPolicy(LeastRecentlyUsed))
+	cache.SAdd("set", "a")
+	cache.Set("other", "value")
+	cache.SAdd("set", "b")
+	cache.Set("new", "value")
+	if _, ok := cache.Get("other"); ok {
+		t.Error("expected the least recently modified entry to have been evicted")
+	}
+	if members, _ := cache.SMembers("set"); len(members) != 2 {
+		t.Error("expected the set to have been kept, got", members)
+	}
+}
+
+func TestModifyCollection_Concurrency(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(Megabyte))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cache.SAdd("key", fmt.Sprintf("%d-%d", i, j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	if members, _ := cache.SMembers("key"); len(members) != 1000 {
+		t.Error("expected 1000 members, got", len(members))
+	}
+}
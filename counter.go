@@ -0,0 +1,49 @@
+package gocache
+
+import "time"
+
+// Increment atomically adds delta, which may be negative, to the int64 value stored under a key, creating the key
+// with a value of delta and no expiration if it doesn't exist, and returns the new value.
+//
+// Returns ErrWrongType if the key holds a value that isn't an int64
+func (c *Cache) Increment(key string, delta int64) (int64, error) {
+	return c.IncrementWithTTL(key, delta, NoExpiration)
+}
+
+// IncrementWithTTL is the same as Increment, but the key is created with the TTL passed as parameter if it doesn't
+// exist. The expiration of an existing key is left untouched, so counters can be used for fixed time windows.
+//
+// Like with SetWithTTL, a TTL of 0 means that the key isn't created at all, in which case the value returned is delta.
+func (c *Cache) IncrementWithTTL(key string, delta int64, ttl time.Duration) (int64, error) {
+	storageKey, err := c.writeKey(key)
+	if err != nil {
+		if err == errNeverCached {
+			return delta, nil
+		}
+		return delta, err
+	}
+	callerKey, key := key, storageKey
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.draining {
+		return 0, ErrDraining
+	}
+	entry, ok := c.get(key)
+	if ok && entry.Expired() {
+		c.stats.ExpiredKeys++
+		c.delete(key)
+		c.onExpire(entry)
+		ok = false
+	}
+	if !ok {
+		return delta, c.setLocked(callerKey, key, delta, expirationOf(ttl), 0, nil)
+	}
+	value, ok := entry.Value.(int64)
+	if !ok {
+		return 0, ErrWrongType
+	}
+	value += delta
+	entry.Value = value
+	c.modified(entry)
+	return value, nil
+}
@@ -0,0 +1,61 @@
+package gocache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_Increment(t *testing.T) {
+	cache := NewCache()
+	if value, err := cache.Increment("counter", 5); err != nil || value != 5 {
+		t.Errorf("expected 5, got %d (%v)", value, err)
+	}
+	if value, err := cache.Increment("counter", -2); err != nil || value != 3 {
+		t.Errorf("expected 3, got %d (%v)", value, err)
+	}
+	if value, _ := cache.Get("counter"); value != int64(3) {
+		t.Error("expected the value to be stored as an int64, got", value)
+	}
+	cache.Set("string", "value")
+	if _, err := cache.Increment("string", 1); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+}
+
+func TestCache_IncrementWithTTL(t *testing.T) {
+	cache := NewCache()
+	cache.IncrementWithTTL("counter", 1, 50*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if value, _ := cache.IncrementWithTTL("counter", 1, time.Hour); value != 2 {
+		t.Error("expected 2, got", value)
+	}
+	if ttl, _ := cache.TTL("counter"); ttl > 20*time.Millisecond {
+		t.Error("expected incrementing an existing key to keep its expiration, got a TTL of", ttl)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if value, _ := cache.IncrementWithTTL("counter", 1, time.Hour); value != 1 {
+		t.Error("expected the counter to have been recreated once expired, got", value)
+	}
+	if value, _ := cache.IncrementWithTTL("zero", 1, 0); value != 1 || cache.Count() != 1 {
+		t.Error("expected a key with a TTL of 0 not to have been created")
+	}
+}
+
+func TestCache_IncrementConcurrency(t *testing.T) {
+	cache := NewCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cache.Increment("counter", 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if value, _ := cache.Get("counter"); value != int64(1000) {
+		t.Error("expected 1000, got", value)
+	}
+}
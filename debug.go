@@ -0,0 +1,67 @@
+package gocache
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DumpOrder writes the internal order of the cache's entries to the writer passed as parameter, so that the state
+// of a cache that evicted the wrong entry can be attached to a bug report.
+//
+// The list is written from head to tail, each entry on its own line along with its approximate size in bytes, its
+// remaining TTL and the number of times it was accessed. Unless the EvictionPolicy is LeastFrequentUsed, LRUK,
+// MostRecentlyUsed or Sieve, the tail is the next entry to be evicted. If the EvictionPolicy is LeastFrequentUsed,
+// the frequency buckets are written as well, from the next to be evicted to the last, with their keys sorted so that
+// two dumps of the same state are identical.
+//
+// The state is captured while holding the lock, but it is written after releasing it, so a slow writer doesn't block
+// the cache. Returns the error returned by the writer, if any.
+func (c *Cache) DumpOrder(w io.Writer) error {
+	var sb strings.Builder
+	now := time.Now().UnixNano()
+	c.mutex.RLock()
+	fmt.Fprintf(&sb, "entries=%d; memoryUsage=%d; maxSize=%d; maxMemoryUsage=%d; evictionPolicy=%d\n", len(c.entries), c.memoryUsage, c.maxSize, c.maxMemoryUsage, c.evictionPolicy)
+	sb.WriteString("list (head to tail):\n")
+	for current := c.head; current != nil; current = current.next {
+		fmt.Fprintf(&sb, "  key=%q size=%d ttl=%s accesses=%d", current.Key, current.SizeInBytes(), dumpTTL(current.Expiration, now), current.accessCount)
+		if current.pinned(now) {
+			sb.WriteString(" pinned")
+		}
+		if c.evictionPolicy == Sieve && current.visited {
+			sb.WriteString(" visited")
+		}
+		if current == c.sieveHand {
+			sb.WriteString(" hand")
+		}
+		sb.WriteString("\n")
+	}
+	if c.evictionPolicy == LeastFrequentUsed && c.freqs != nil {
+		sb.WriteString("frequency buckets (least to most frequently used):\n")
+		for element := c.freqs.Front(); element != nil; element = element.Next() {
+			frequencyItem := element.Value.(*FrequencyItem)
+			keys := make([]string, 0, len(frequencyItem.Entries))
+			for entry := range frequencyItem.Entries {
+				keys = append(keys, fmt.Sprintf("%q", entry.Key))
+			}
+			sort.Strings(keys)
+			fmt.Fprintf(&sb, "  %d: %s\n", frequencyItem.Freq, strings.Join(keys, " "))
+		}
+	}
+	c.mutex.RUnlock()
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// dumpTTL returns a human-readable representation of the time left until the given expiration
+func dumpTTL(expiration, now int64) string {
+	if expiration == NoExpiration {
+		return "none"
+	}
+	if expiration < now {
+		return "expired"
+	}
+	return time.Duration(expiration - now).Round(time.Millisecond).String()
+}
@@ -0,0 +1,65 @@
+package gocache
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_DumpOrder(t *testing.T) {
+	cache := NewCache(WithEvictionPolicy(LeastRecentlyUsed))
+	cache.Set("1", "value")
+	cache.SetWithTTL("2", "value", time.Hour)
+	cache.SetWithMinLifetime("3", "value", time.Hour, NoExpiration)
+	cache.Get("1")
+	var buf bytes.Buffer
+	if err := cache.DumpOrder(&buf); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[2], `  key="1" `) || !strings.Contains(lines[2], "ttl=none accesses=1") {
+		t.Errorf("expected 1 to be at the head with no TTL and 1 access, got %s", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], `  key="3" `) || !strings.HasSuffix(lines[3], " pinned") {
+		t.Errorf("expected 3 to be pinned and second, got %s", lines[3])
+	}
+	if !strings.HasPrefix(lines[4], `  key="2" `) || !strings.Contains(lines[4], "ttl=59m") && !strings.Contains(lines[4], "ttl=1h0m0s") {
+		t.Errorf("expected 2 to be at the tail with a TTL of about an hour, got %s", lines[4])
+	}
+}
+
+func TestCache_DumpOrderWithLeastFrequentUsed(t *testing.T) {
+	cache := NewCache(WithEvictionPolicy(LeastFrequentUsed))
+	cache.Set("b", "value")
+	cache.Set("a", "value")
+	cache.Set("c", "value")
+	cache.Get("c")
+	var first, second bytes.Buffer
+	cache.DumpOrder(&first)
+	cache.DumpOrder(&second)
+	if first.String() != second.String() {
+		t.Error("expected two dumps of the same state to be identical")
+	}
+	if !strings.Contains(first.String(), "  1: \"a\" \"b\"\n  2: \"c\"\n") {
+		t.Errorf("expected the frequency buckets to be dumped with their keys sorted, got:\n%s", first.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+func TestCache_DumpOrderWithFailingWriter(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	if err := cache.DumpOrder(failingWriter{}); err == nil {
+		t.Error("expected the error returned by the writer to be returned")
+	}
+}
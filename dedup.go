@@ -0,0 +1,31 @@
+package gocache
+
+import "time"
+
+// Deduplicate returns true if the key passed as parameter wasn't seen within the window, and false if it was, which
+// makes it easy to process messages or requests only once based on their idempotency key. Checking whether the key
+// was seen and recording it is atomic, so concurrent calls for the same key only ever return true once.
+//
+// The key is recorded as an entry that expires after the window, and which is subject to eviction like any other
+// entry. If the key cannot be recorded, for instance because the cache is full and its FullBehavior is RejectWrites,
+// true is returned. A window of NoExpiration remembers the key until it is evicted or deleted.
+//
+// The number of calls returning true and false are counted in Statistics.FirstSeen and Statistics.Duplicates.
+func (c *Cache) Deduplicate(key string, window time.Duration) bool {
+	callerKey := key
+	key, err := c.writeKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err != nil {
+		// The key cannot be recorded
+		c.stats.FirstSeen++
+		return true
+	}
+	if entry, ok := c.get(key); ok && !entry.Expired() {
+		c.stats.Duplicates++
+		return false
+	}
+	c.stats.FirstSeen++
+	_ = c.setLocked(callerKey, key, true, expirationOf(window), 0, nil)
+	return true
+}
@@ -0,0 +1,56 @@
+package gocache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_Deduplicate(t *testing.T) {
+	cache := NewCache()
+	if !cache.Deduplicate("message-1", 50*time.Millisecond) {
+		t.Error("expected the key to be seen for the first time")
+	}
+	if cache.Deduplicate("message-1", 50*time.Millisecond) {
+		t.Error("expected the key to be a duplicate")
+	}
+	if !cache.Deduplicate("message-2", 50*time.Millisecond) {
+		t.Error("expected other keys to be seen for the first time")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if !cache.Deduplicate("message-1", 50*time.Millisecond) {
+		t.Error("expected the key to be seen for the first time once the window was over")
+	}
+	stats := cache.Stats()
+	if stats.FirstSeen != 3 || stats.Duplicates != 1 {
+		t.Errorf("expected 3 keys seen for the first time and 1 duplicate, got %d and %d", stats.FirstSeen, stats.Duplicates)
+	}
+}
+
+func TestCache_DeduplicateWhenCacheIsFull(t *testing.T) {
+	cache := NewCache(WithMaxSize(1), WithFullBehavior(RejectWrites))
+	cache.Set("key", "value")
+	if !cache.Deduplicate("message", time.Minute) || !cache.Deduplicate("message", time.Minute) {
+		t.Error("expected keys that cannot be recorded to always be seen for the first time")
+	}
+}
+
+func TestCache_DeduplicateConcurrency(t *testing.T) {
+	cache := NewCache()
+	var firstSeen int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if cache.Deduplicate("message", time.Minute) {
+				atomic.AddInt32(&firstSeen, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if firstSeen != 1 {
+		t.Error("expected the key to have been seen for the first time exactly once, got", firstSeen)
+	}
+}
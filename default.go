@@ -0,0 +1,32 @@
+package gocache
+
+import "time"
+
+// DefaultCache is the cache used by the package-level Set, SetWithTTL, DefaultGet and Delete functions, which spare
+// quick scripts and tests from having to create and pass a cache around
+var DefaultCache = NewCache()
+
+// Set creates or updates a key with a given value in the DefaultCache
+func Set(key string, value interface{}) error {
+	return DefaultCache.Set(key, value)
+}
+
+// SetWithTTL creates or updates a key with a given value and TTL in the DefaultCache
+func SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	return DefaultCache.SetWithTTL(key, value, ttl)
+}
+
+// DefaultGet retrieves an entry of the DefaultCache using the key passed as parameter
+// If there is no such entry, the value returned will be nil and the boolean will be false
+//
+// Unlike the other functions operating on the DefaultCache, it isn't named after the method of Cache, since Get
+// retrieves a cache of the DefaultRegistry.
+func DefaultGet(key string) (interface{}, bool) {
+	return DefaultCache.Get(key)
+}
+
+// Delete removes a key from the DefaultCache
+// Returns true if the key existed, false otherwise
+func Delete(key string) bool {
+	return DefaultCache.Delete(key)
+}
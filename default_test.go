@@ -0,0 +1,71 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultCache(t *testing.T) {
+	defer DefaultCache.Clear()
+	if err := Set("key", "value"); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if err := SetWithTTL("expiring", "value", time.Hour); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if value, ok := DefaultGet("key"); !ok || value != "value" {
+		t.Errorf("expected value, got %v", value)
+	}
+	if value, _ := DefaultCache.Get("expiring"); value != "value" {
+		t.Errorf("expected the package-level functions to operate on the DefaultCache, got %v", value)
+	}
+	if !Delete("key") || Delete("key") {
+		t.Error("expected key to have been deleted once")
+	}
+	if _, ok := DefaultGet("key"); ok {
+		t.Error("expected key not to exist anymore")
+	}
+}
+
+func TestCache_ZeroValue(t *testing.T) {
+	var cache Cache
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected key not to exist")
+	}
+	if err := cache.Set("key", "value"); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	cache.SetWithTTL("expiring", "value", time.Hour)
+	if value, ok := cache.Get("key"); !ok || value != "value" {
+		t.Errorf("expected value, got %v", value)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.Count())
+	}
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d and %d", stats.Hits, stats.Misses)
+	}
+	if cache.StatsSampling() != 1 {
+		t.Errorf("expected every hit and miss to be counted, got a rate of %f", cache.StatsSampling())
+	}
+	var scanned []string
+	for cursor := uint64(0); ; {
+		var keys []string
+		keys, cursor = cache.Scan(cursor, "*", 10)
+		scanned = append(scanned, keys...)
+		if cursor == 0 {
+			break
+		}
+	}
+	if len(scanned) != 2 {
+		t.Errorf("expected 2 keys to have been scanned, got %v", scanned)
+	}
+	if !cache.Delete("key") {
+		t.Error("expected key to have been deleted")
+	}
+	cache.Clear()
+	if cache.Count() != 0 {
+		t.Errorf("expected no entry after Clear, got %d", cache.Count())
+	}
+}
@@ -1,13 +1,29 @@
 package gocache
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Delete removes a key from the cache
 //
 // Returns false if the key did not exist.
 func (c *Cache) Delete(key string) bool {
+	return c.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx is the same as Delete, but the context passed as parameter is used to attach a request ID to the audit
+// record of the deletion (see WithAuditLog)
+func (c *Cache) DeleteCtx(ctx context.Context, key string) bool {
+	key = c.storageKey(key)
+	defer c.endOp(OpDelete, key, c.startOp())
 	c.mutex.Lock()
-	ok := c.delete(key)
+	entry, ok := c.entries[key]
+	if ok {
+		c.delete(key)
+		c.auditDeletion(ctx, entry)
+	}
+	c.assertInvariants()
 	c.mutex.Unlock()
 	return ok
 }
@@ -16,22 +32,170 @@ func (c *Cache) Delete(key string) bool {
 //
 // Returns the number of keys deleted
 func (c *Cache) DeleteAll(keys []string) int {
+	if c.keyObfuscation != nil {
+		storageKeys := make([]string, len(keys))
+		for i, key := range keys {
+			storageKeys[i] = c.storageKey(key)
+		}
+		keys = storageKeys
+	}
+	return c.deleteAll(keys)
+}
+
+// deleteAll deletes the entries stored under the keys passed as parameter, and returns the number of keys deleted
+func (c *Cache) deleteAll(keys []string) int {
 	numberOfKeysDeleted := 0
 	c.mutex.Lock()
 	for _, key := range keys {
-		if c.delete(key) {
+		if entry, ok := c.entries[key]; ok {
+			c.delete(key)
+			c.auditDeletion(context.Background(), entry)
 			numberOfKeysDeleted++
 		}
 	}
+	c.assertInvariants()
 	c.mutex.Unlock()
 	return numberOfKeysDeleted
 }
 
+// Take removes a key from the cache and returns the value it had
+//
+// If the key did not exist or has already expired, the value returned will be nil and the boolean will be false.
+// Because both the retrieval and the deletion happen under the same lock, no concurrent write can slip in between.
+func (c *Cache) Take(key string) (interface{}, bool) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	value, ok := c.take(key)
+	c.assertInvariants()
+	c.mutex.Unlock()
+	return value, ok
+}
+
+// TakeAll removes multiple keys from the cache and returns the values they had
+//
+// Unlike GetByKeys, only the keys that existed and had not expired are present in the map returned
+func (c *Cache) TakeAll(keys []string) map[string]interface{} {
+	entries := make(map[string]interface{})
+	c.mutex.Lock()
+	for _, key := range keys {
+		if value, ok := c.take(c.storageKey(key)); ok {
+			entries[key] = value
+		}
+	}
+	c.assertInvariants()
+	c.mutex.Unlock()
+	return entries
+}
+
+// DeletedEntry is an entry that was deleted through SoftDelete, as returned by GetDeleted
+type DeletedEntry struct {
+	// Value is the value the entry had when it was deleted
+	Value interface{}
+
+	// DeletedAt is the time at which the entry was deleted
+	DeletedAt time.Time
+
+	// PurgeAt is the time after which the deleted entry will no longer be returned by GetDeleted
+	PurgeAt time.Time
+}
+
+// SoftDelete removes a key from the cache, but retains a tombstone of the entry for the given duration, during which
+// it can be retrieved through GetDeleted for debugging or auditing purposes.
+//
+// Tombstones do not count towards the MaxSize nor the MaxMemoryUsage of the cache. They are purged when accessed
+// through GetDeleted after their purge time, or by the janitor if it is running.
+//
+// Returns false if the key did not exist.
+func (c *Cache) SoftDelete(key string, purgeAfter time.Duration) bool {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	entry, ok := c.get(key)
+	if !ok || entry.Expired() {
+		c.mutex.Unlock()
+		return false
+	}
+	c.delete(key)
+	c.auditDeletion(context.Background(), entry)
+	if c.tombstones == nil {
+		c.tombstones = make(map[string]*DeletedEntry)
+	}
+	now := time.Now()
+	c.tombstones[key] = &DeletedEntry{Value: entry.Value, DeletedAt: now, PurgeAt: now.Add(purgeAfter)}
+	c.assertInvariants()
+	c.mutex.Unlock()
+	return true
+}
+
+// GetDeleted retrieves the tombstone of a key deleted through SoftDelete
+//
+// If the key was not soft deleted, or if its tombstone has been purged, the boolean returned will be false.
+// Note that a key that was soft deleted and then set again still has a tombstone until its purge time.
+func (c *Cache) GetDeleted(key string) (DeletedEntry, bool) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	tombstone, ok := c.tombstones[key]
+	if !ok {
+		return DeletedEntry{}, false
+	}
+	if time.Now().After(tombstone.PurgeAt) {
+		delete(c.tombstones, key)
+		return DeletedEntry{}, false
+	}
+	return *tombstone, true
+}
+
+// purgeTombstones deletes the tombstones whose purge time has passed
+func (c *Cache) purgeTombstones() {
+	now := time.Now()
+	for key, tombstone := range c.tombstones {
+		if now.After(tombstone.PurgeAt) {
+			delete(c.tombstones, key)
+		}
+	}
+}
+
 // DeleteKeysByPattern deletes all entries matching a given key pattern and returns the number of entries deleted.
 //
 // Note that DeleteKeysByPattern does not trigger active evictions, nor does it count as accessing the entry (if LRU).
 func (c *Cache) DeleteKeysByPattern(pattern string) int {
-	return c.DeleteAll(c.GetKeysByPattern(pattern, 0))
+	return c.deleteAll(c.GetKeysByPattern(pattern, 0))
+}
+
+// DeleteKeysByPatternWithLimit deletes at most max entries matching a given key pattern, and returns the number of
+// entries deleted as well as whether there were more matching entries than max, in which case the remaining ones were
+// left untouched.
+// If max is 0 or less, all matching entries are deleted, like DeleteKeysByPattern.
+//
+// This is meant to be used when the pattern comes from an operator, so that an accidental "*" doesn't wipe the entire
+// cache in a single call that holds the lock for every entry. CountKeysByPattern can be used to find out how many
+// entries would be deleted beforehand.
+func (c *Cache) DeleteKeysByPatternWithLimit(pattern string, max int) (deleted int, truncated bool) {
+	if max <= 0 {
+		return c.DeleteKeysByPattern(pattern), false
+	}
+	keys := c.GetKeysByPattern(pattern, max+1)
+	if len(keys) > max {
+		keys = keys[:max]
+		truncated = true
+	}
+	return c.deleteAll(keys), truncated
+}
+
+// CountKeysByPattern returns the number of entries matching a given key pattern without deleting them, which is the
+// number of entries DeleteKeysByPattern would delete.
+//
+// Like GetKeysByPattern, expired entries are not counted.
+func (c *Cache) CountKeysByPattern(pattern string) int {
+	count := 0
+	c.mutex.RLock()
+	for key, entry := range c.entries {
+		if !entry.Expired() && MatchPattern(pattern, key) {
+			count++
+		}
+	}
+	c.mutex.RUnlock()
+	return count
 }
 
 // Count returns the total amount of entries in the cache, regardless of whether they're expired or not
@@ -45,16 +209,77 @@ func (c *Cache) Count() int {
 // Clear deletes all entries from the cache
 func (c *Cache) Clear() {
 	c.mutex.Lock()
-	c.entries = make(map[string]*Entry)
+	for key := range c.traces {
+		if _, ok := c.entries[key]; ok {
+			c.trace(key, "clear")
+		}
+	}
+	c.entries = make(map[string]*Entry, c.initialCapacity)
+	c.tags = nil
+	c.tombstones = nil
 	c.memoryUsage = 0
 	c.head = nil
 	c.tail = nil
+	if c.freqs != nil {
+		c.freqs.Init()
+	}
+	c.lruk = nil
+	c.sampleEntries = nil
+	c.scanBuckets = nil
+	c.expirationWheel = nil
+	c.expirationSlots = nil
+	c.wakeUpExpiring()
+	c.sieveHand = nil
+	c.assertInvariants()
 	c.mutex.Unlock()
 }
 
+// ClearFraction evicts the given fraction of the entries of the cache, between 0 and 1, according to the eviction
+// policy, which sheds part of the memory used by the cache under pressure without causing the storm of misses that
+// a Clear would. For instance, ClearFraction(0.25) evicts the quarter of the entries that would be evicted first.
+//
+// Like any eviction, pinned entries are skipped (see SetWithMinLifetime), EvictedKeys is incremented and OnEvict is
+// called for every entry evicted. Entries are evicted in batches of ReclaimerBatchSize, and the lock is released
+// between batches so that other operations are not blocked for long.
+// Returns the number of entries evicted.
+func (c *Cache) ClearFraction(fraction float64) int {
+	if fraction <= 0 {
+		return 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	c.mutex.RLock()
+	target := int(float64(len(c.entries))*fraction + 0.5)
+	c.mutex.RUnlock()
+	evicted := 0
+	for evicted < target {
+		c.mutex.Lock()
+		// A single eviction may evict several entries (see LeastFrequentUsed), so they are counted from EvictedKeys
+		evictedKeysBefore := c.stats.EvictedKeys
+		batchStart := evicted
+		batchEnd := evicted + ReclaimerBatchSize
+		if batchEnd > target {
+			batchEnd = target
+		}
+		for evicted < batchEnd && c.evictAtMost(batchEnd-evicted) {
+			evicted = batchStart + int(c.stats.EvictedKeys-evictedKeysBefore)
+		}
+		done := evicted < batchEnd
+		c.assertInvariants()
+		c.mutex.Unlock()
+		if done {
+			// Either the target was reached, or no entry could be evicted
+			break
+		}
+	}
+	return evicted
+}
+
 // TTL returns the time until the cache entry specified by the key passed as parameter
 // will be deleted.
 func (c *Cache) TTL(key string) (time.Duration, error) {
+	key = c.storageKey(key)
 	c.mutex.RLock()
 	entry, ok := c.get(key)
 	c.mutex.RUnlock()
@@ -81,18 +306,67 @@ func (c *Cache) TTL(key string) (time.Duration, error) {
 //
 // Returns true if the cache key exists and has had its expiration time altered
 func (c *Cache) Expire(key string, ttl time.Duration) bool {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	entry, ok := c.get(key)
 	if !ok || entry.Expired() {
 		return false
 	}
-	if ttl != NoExpiration {
+	if ttl > 0 {
+		entry.Expiration = c.bucketed(time.Now().Add(ttl).UnixNano())
+	} else if ttl != NoExpiration {
 		entry.Expiration = time.Now().Add(ttl).UnixNano()
 	} else {
 		entry.Expiration = NoExpiration
 	}
+	c.scheduleExpiration(entry)
 	return true
 }
 
+// NextExpiration returns the key that will expire next and the time at which it will expire, which allows external
+// schedulers to coordinate with the cache (e.g. to refresh data right before it expires)
+//
+// Entries that have already expired and entries with no expiration are ignored. If there is no such entry, the
+// boolean returned is false.
+//
+// Note that this scans every entry of the cache, so it should not be called on every operation of a large cache.
+func (c *Cache) NextExpiration() (string, time.Time, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var next *Entry
+	now := time.Now().UnixNano()
+	for _, entry := range c.entries {
+		if entry.Expiration == NoExpiration || entry.Expiration < now {
+			continue
+		}
+		if next == nil || entry.Expiration < next.Expiration {
+			next = entry
+		}
+	}
+	if next == nil {
+		return "", time.Time{}, false
+	}
+	return next.Key, time.Unix(0, next.Expiration), true
+}
+
+// take deletes an entry and returns its value, unless the entry has expired, in which case it is deleted and
+// reported as missing
+func (c *Cache) take(key string) (interface{}, bool) {
+	entry, ok := c.get(key)
+	if !ok {
+		return nil, false
+	}
+	c.delete(key)
+	if entry.Expired() {
+		c.stats.ExpiredKeys++
+		c.onExpire(entry)
+		return nil, false
+	}
+	c.auditDeletion(context.Background(), entry)
+	return entry.Value, true
+}
+
 func (c *Cache) delete(key string) bool {
 	entry, ok := c.entries[key]
 	if ok {
@@ -103,9 +377,16 @@ func (c *Cache) delete(key string) bool {
 		if c.evictionPolicy == LeastFrequentUsed {
 			c.removeEntryFromFrequencyList(entry.frequencyParent, entry)
 		}
+		if c.evictionPolicy == LRUK {
+			c.removeFromLRUKHeap(entry)
+		}
 
 		c.removeExistingEntryReferences(entry)
 		delete(c.entries, key)
+		c.removeFromSample(entry)
+		c.removeFromScanBuckets(entry)
+		c.unscheduleExpiration(entry)
+		c.untag(entry)
 
 	}
 	return ok
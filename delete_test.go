@@ -1,6 +1,7 @@
 package gocache
 
 import (
+	"fmt"
 	"testing"
 	"time"
 )
@@ -75,6 +76,114 @@ func TestCache_DeleteAll(t *testing.T) {
 	}
 }
 
+func TestCache_Take(t *testing.T) {
+	cache := NewCache(WithEvictionPolicy(LeastFrequentUsed))
+	cache.Set("1", "one")
+	cache.SetWithTTL("2", "two", time.Millisecond)
+	value, ok := cache.Take("1")
+	if !ok {
+		t.Error("expected key 1 to exist")
+	}
+	if value != "one" {
+		t.Errorf("expected: %s, but got: %s", "one", value)
+	}
+	if _, ok := cache.Get("1"); ok {
+		t.Error("expected key 1 to have been deleted")
+	}
+	if _, ok := cache.Take("1"); ok {
+		t.Error("expected Take to return false, because key 1 no longer exists")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cache.Take("2"); ok {
+		t.Error("expected Take to return false, because key 2 has expired")
+	}
+	if cache.Count() != 0 {
+		t.Error("expected cache to be empty, got", cache.Count())
+	}
+	if cache.Stats().ExpiredKeys != 1 {
+		t.Error("expected ExpiredKeys to be 1, got", cache.Stats().ExpiredKeys)
+	}
+}
+
+func TestCache_TakeAll(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", "one")
+	cache.Set("2", "two")
+	cache.Set("3", "three")
+	values := cache.TakeAll([]string{"1", "3", "4"})
+	if len(values) != 2 {
+		t.Errorf("expected 2 values to have been taken, got %d", len(values))
+	}
+	if values["1"] != "one" || values["3"] != "three" {
+		t.Error("expected values of keys 1 and 3 to have been returned, got", values)
+	}
+	if _, ok := values["4"]; ok {
+		t.Error("expected key 4 not to be in the map, because it did not exist")
+	}
+	if cache.Count() != 1 {
+		t.Error("expected only key 2 to be left in the cache, got", cache.Count())
+	}
+	if cache.head != cache.tail || cache.head.Key != "2" {
+		t.Error("expected key 2 to be both the head and the tail")
+	}
+}
+
+func TestCache_SoftDelete(t *testing.T) {
+	cache := NewCache(WithRaceAssertions(true))
+	cache.Set("key", "value")
+	if !cache.SoftDelete("key", time.Hour) {
+		t.Error("expected key to have been soft deleted")
+	}
+	if cache.SoftDelete("key", time.Hour) {
+		t.Error("expected key not to be soft deleted twice")
+	}
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected key to be hidden from Get")
+	}
+	if cache.Count() != 0 {
+		t.Errorf("expected the tombstone not to count as an entry, got %d", cache.Count())
+	}
+	deleted, ok := cache.GetDeleted("key")
+	if !ok || deleted.Value != "value" {
+		t.Fatalf("expected the tombstone of key to have value value, got %v", deleted.Value)
+	}
+	if time.Since(deleted.DeletedAt) > time.Second || deleted.PurgeAt.Sub(deleted.DeletedAt) != time.Hour {
+		t.Errorf("expected the tombstone to be purged an hour after its deletion, got %s and %s", deleted.DeletedAt, deleted.PurgeAt)
+	}
+	if _, ok := cache.GetDeleted("never-deleted"); ok {
+		t.Error("expected no tombstone for a key that was never deleted")
+	}
+	cache.Clear()
+	if _, ok := cache.GetDeleted("key"); ok {
+		t.Error("expected Clear to remove tombstones")
+	}
+}
+
+func TestCache_SoftDeleteWhenTombstoneIsPurged(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	cache.SoftDelete("1", time.Millisecond)
+	cache.SoftDelete("2", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cache.GetDeleted("1"); ok {
+		t.Error("expected the tombstone of 1 to have been purged")
+	}
+	cache.StartJanitor()
+	defer cache.StopJanitor()
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		cache.mutex.Lock()
+		numberOfTombstones := len(cache.tombstones)
+		cache.mutex.Unlock()
+		if numberOfTombstones == 0 {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal("expected the janitor to have purged the tombstone of 2")
+		}
+	}
+}
+
 func TestCache_DeleteKeysByPattern(t *testing.T) {
 	cache := NewCache()
 	cache.Set("a1", []byte("v"))
@@ -92,6 +201,34 @@ func TestCache_DeleteKeysByPattern(t *testing.T) {
 	}
 }
 
+func TestCache_DeleteKeysByPatternWithLimit(t *testing.T) {
+	cache := NewCache()
+	for _, key := range []string{"a1", "a2", "a3", "b1"} {
+		cache.Set(key, "v")
+	}
+	if count := cache.CountKeysByPattern("a*"); count != 3 {
+		t.Errorf("expected 3 keys to match, got %d", count)
+	}
+	if cache.Count() != 4 {
+		t.Error("expected CountKeysByPattern not to delete anything")
+	}
+	deleted, truncated := cache.DeleteKeysByPatternWithLimit("a*", 2)
+	if deleted != 2 || !truncated {
+		t.Errorf("expected 2 keys to have been deleted and the deletion to have been truncated, got %d and %v", deleted, truncated)
+	}
+	deleted, truncated = cache.DeleteKeysByPatternWithLimit("a*", 2)
+	if deleted != 1 || truncated {
+		t.Errorf("expected 1 key to have been deleted and the deletion not to have been truncated, got %d and %v", deleted, truncated)
+	}
+	deleted, truncated = cache.DeleteKeysByPatternWithLimit("*", 0)
+	if deleted != 1 || truncated {
+		t.Errorf("expected a max of 0 to delete every matching key, got %d and %v", deleted, truncated)
+	}
+	if cache.Count() != 0 {
+		t.Errorf("expected the cache to be empty, got %d entries", cache.Count())
+	}
+}
+
 func TestCache_TTL(t *testing.T) {
 	cache := NewCache()
 	ttl, err := cache.TTL("key")
@@ -159,6 +296,28 @@ func TestCache_Expire(t *testing.T) {
 	}
 }
 
+func TestCache_NextExpiration(t *testing.T) {
+	cache := NewCache()
+	if _, _, ok := cache.NextExpiration(); ok {
+		t.Error("expected no next expiration in an empty cache")
+	}
+	cache.Set("never", "value")
+	if _, _, ok := cache.NextExpiration(); ok {
+		t.Error("expected no next expiration when no entry expires")
+	}
+	cache.SetWithTTL("hour", "value", time.Hour)
+	cache.SetWithTTL("minute", "value", time.Minute)
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	key, at, ok := cache.NextExpiration()
+	if !ok || key != "minute" {
+		t.Fatalf("expected minute to expire next, got %s", key)
+	}
+	if until := time.Until(at); until > time.Minute || until < 59*time.Second {
+		t.Error("expected minute to expire in about a minute, got", until)
+	}
+}
+
 func TestCache_Clear(t *testing.T) {
 	cache := NewCache(WithMaxSize(10))
 	cache.Set("k1", "v1")
@@ -175,3 +334,60 @@ func TestCache_Clear(t *testing.T) {
 		t.Error("expected cache.memoryUsage to be 0")
 	}
 }
+
+func TestCache_ClearFraction(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithEvictionPolicy(LeastRecentlyUsed), WithRaceAssertions(true))
+	for n := 0; n < 1000; n++ {
+		cache.Set(fmt.Sprintf("%03d", n), n)
+	}
+	cache.Get("000")
+	if evicted := cache.ClearFraction(0.25); evicted != 250 {
+		t.Errorf("expected 250 entries to have been evicted, got %d", evicted)
+	}
+	if cache.Count() != 750 || cache.Stats().EvictedKeys != 250 {
+		t.Errorf("expected 750 entries left and 250 evicted keys, got %d and %d", cache.Count(), cache.Stats().EvictedKeys)
+	}
+	// The least recently used entries were evicted
+	if _, ok := cache.Get("000"); !ok {
+		t.Error("expected 000 not to have been evicted, since it was recently used")
+	}
+	if _, ok := cache.Get("001"); ok {
+		t.Error("expected 001 to have been evicted")
+	}
+	if evicted := cache.ClearFraction(0); evicted != 0 {
+		t.Errorf("expected no entry to have been evicted, got %d", evicted)
+	}
+	if evicted := cache.ClearFraction(2); evicted != 750 || cache.Count() != 0 {
+		t.Errorf("expected every entry to have been evicted, got %d and %d entries left", evicted, cache.Count())
+	}
+}
+
+func TestCache_ClearFractionSkipsPinnedEntries(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithMinLifetime("pinned", "value", time.Hour, time.Hour)
+	cache.Set("key", "value")
+	if evicted := cache.ClearFraction(1); evicted != 1 {
+		t.Errorf("expected only the unpinned entry to have been evicted, got %d", evicted)
+	}
+	if _, ok := cache.Get("pinned"); !ok {
+		t.Error("expected the pinned entry to still be in the cache")
+	}
+}
+
+func TestCache_ClearFractionWithLeastFrequentUsed(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithEvictionPolicy(LeastFrequentUsed), WithRaceAssertions(true))
+	for n := 0; n < 100; n++ {
+		cache.Set(fmt.Sprint(n), n)
+	}
+	cache.Get("0")
+	// Every entry but 0 is in the same frequency bucket, yet only a quarter of them must be evicted
+	if evicted := cache.ClearFraction(0.25); evicted != 25 {
+		t.Errorf("expected 25 entries to have been evicted, got %d", evicted)
+	}
+	if cache.Count() != 75 || cache.Stats().EvictedKeys != 25 {
+		t.Errorf("expected 75 entries left and 25 evicted keys, got %d and %d", cache.Count(), cache.Stats().EvictedKeys)
+	}
+	if _, ok := cache.Get("0"); !ok {
+		t.Error("expected 0 not to have been evicted, since it was used more frequently")
+	}
+}
@@ -0,0 +1,50 @@
+package gocache
+
+import (
+	"context"
+	"time"
+)
+
+// DrainPollInterval is how often Drain checks whether the loads in flight and the queued callbacks have completed
+const DrainPollInterval = 10 * time.Millisecond
+
+// Drain prepares the cache for the shutdown of the process, and blocks until the cache is quiescent or the context
+// passed as parameter is done, whichever comes first.
+//
+// Once Drain is called, every write is rejected with ErrDraining (or fails, for functions that return a boolean),
+// while reads keep being served from the cache, so that requests still being handled during the grace period of the
+// shutdown do not all miss at once. The janitor, the reclaimer and the refresher are stopped, and Drain then waits for
+// the loads in flight (see LoadStarted) and for the queued OnSetPattern callbacks to complete.
+//
+// Returns the number of loads still in flight and the error of the context if the context is done first, or 0 and nil
+// otherwise. A cache cannot be undrained.
+func (c *Cache) Drain(ctx context.Context) (int, error) {
+	c.mutex.Lock()
+	c.draining = true
+	c.mutex.Unlock()
+	c.StopJanitor()
+	c.StopReclaimer()
+	c.StopRefresher()
+	ticker := time.NewTicker(DrainPollInterval)
+	defer ticker.Stop()
+	for {
+		c.mutex.RLock()
+		loadsInFlight := int(c.stats.LoadsInFlight)
+		c.mutex.RUnlock()
+		if loadsInFlight == 0 && c.hookWorkers.idle() {
+			return 0, nil
+		}
+		select {
+		case <-ctx.Done():
+			return loadsInFlight, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Draining returns whether Drain was called
+func (c *Cache) Draining() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.draining
+}
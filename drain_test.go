@@ -0,0 +1,89 @@
+package gocache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_Drain(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	cache.SAdd("set", "a")
+	if err := cache.StartJanitor(); err != nil {
+		t.Fatal(err)
+	}
+	if inFlight, err := cache.Drain(context.Background()); inFlight != 0 || err != nil {
+		t.Errorf("expected the cache to have been drained, got %d loads in flight (%v)", inFlight, err)
+	}
+	if !cache.Draining() {
+		t.Error("expected the cache to be draining")
+	}
+	if cache.stopJanitor != nil {
+		t.Error("expected the janitor to have been stopped")
+	}
+	if value, ok := cache.Get("key"); !ok || value != "value" {
+		t.Error("expected reads to still be served")
+	}
+	if err := cache.Set("key", "new-value"); err != ErrDraining {
+		t.Error("expected ErrDraining, got", err)
+	}
+	if err := cache.Set("new-key", "value"); err != ErrDraining {
+		t.Error("expected ErrDraining, got", err)
+	}
+	if cache.Replace("key", "new-value") {
+		t.Error("expected Replace to have failed")
+	}
+	if _, err := cache.SAdd("set", "b"); err != ErrDraining {
+		t.Error("expected ErrDraining, got", err)
+	}
+	if _, err := cache.Increment("counter", 1); err != ErrDraining {
+		t.Error("expected ErrDraining, got", err)
+	}
+	if value, _ := cache.Get("key"); value != "value" || cache.Count() != 2 {
+		t.Error("expected the cache not to have been modified")
+	}
+}
+
+func TestCache_DrainWaitsForLoadsInFlight(t *testing.T) {
+	cache := NewCache()
+	loaded := cache.LoadStarted()
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		loaded()
+	}()
+	start := time.Now()
+	if inFlight, err := cache.Drain(context.Background()); inFlight != 0 || err != nil {
+		t.Errorf("expected the cache to have been drained, got %d loads in flight (%v)", inFlight, err)
+	}
+	if time.Since(start) < 30*time.Millisecond {
+		t.Error("expected Drain to have waited for the load in flight")
+	}
+}
+
+func TestCache_DrainWaitsForCallbacks(t *testing.T) {
+	cache := NewCache()
+	var called int32
+	cache.OnSetPattern("*", func(key string, value interface{}) {
+		time.Sleep(30 * time.Millisecond)
+		atomic.StoreInt32(&called, 1)
+	})
+	cache.Set("key", "value")
+	cache.Drain(context.Background())
+	if atomic.LoadInt32(&called) != 1 {
+		t.Error("expected Drain to have waited for the callback")
+	}
+}
+
+func TestCache_DrainWithContextDone(t *testing.T) {
+	cache := NewCache()
+	loaded := cache.LoadStarted()
+	defer loaded()
+	cache.LoadStarted()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if inFlight, err := cache.Drain(ctx); inFlight != 2 || err != context.DeadlineExceeded {
+		t.Errorf("expected 2 loads in flight and context.DeadlineExceeded, got %d (%v)", inFlight, err)
+	}
+}
@@ -25,9 +25,55 @@ type Entry struct {
 	// Pointer to parent in cacheList
 	frequencyParent *list.Element
 
+	// metadata is the metadata attached to the entry through SetWithMetadata, if any
+	// It is never modified once set, as it is shared with the entries it is copied to
+	metadata map[string]string
+
+	// tags are the tags the entry is indexed under, if its value is a Tagger
+	tags []string
+
 	// Expiration is the unix time in nanoseconds at which the entry will expire (-1 means no expiration)
 	Expiration int64
 
+	// createdAt is the unix time in nanoseconds at which the entry was created
+	createdAt int64
+
+	// updatedAt is the unix time in nanoseconds at which the value of the entry was last set
+	updatedAt int64
+
+	// history is the access history of the entry, only used by the LRUK eviction policy
+	history *accessHistory
+
+	// pinnedUntil is the unix time in nanoseconds until which the entry cannot be evicted, or 0 if it isn't pinned
+	// See SetWithMinLifetime
+	pinnedUntil int64
+
+	// softExpiration is the unix time in nanoseconds after which the entry needs a refresh, or 0 if it never does
+	// See SetWithSoftHardTTL
+	softExpiration int64
+
+	// refreshRequested is whether the RefreshObserver was notified that the entry needs a refresh since it was last set
+	refreshRequested bool
+
+	// retained is whether the eviction of the entry was vetoed since it was last set (see EvictionVetoer)
+	retained bool
+
+	// visited is whether the entry was accessed since the hand of the Sieve eviction policy last passed it
+	visited bool
+
+	// expirationSlot is the slot of the cache's expirationWheel the entry is in, or 0 if it isn't in any
+	// See WithPreciseExpiration
+	expirationSlot int64
+
+	// scanBucket is the index of the bucket of the cache's scanBuckets the key of the entry is in
+	scanBucket uint16
+
+	// sampleIndex is the index of the entry in the cache's sampleEntries
+	sampleIndex int
+
+	// accessCount is the number of times the entry was retrieved through Get and similar functions
+	accessCount uint64
+
 	next     *Entry
 	previous *Entry
 }
@@ -47,6 +93,16 @@ func (entry Entry) Expired() bool {
 	return false
 }
 
+// expiredBeyond returns whether the Entry expired more than the given grace period ago
+func (entry Entry) expiredBeyond(grace time.Duration) bool {
+	return entry.Expiration > 0 && time.Now().UnixNano() > entry.Expiration+int64(grace)
+}
+
+// pinned returns whether the entry cannot be evicted at the given unix time in nanoseconds
+func (entry *Entry) pinned(now int64) bool {
+	return entry.pinnedUntil > now
+}
+
 // SizeInBytes returns the size of an entry in bytes, approximately.
 func (entry *Entry) SizeInBytes() int {
 	return toBytes(entry.Key) + toBytes(entry.Value) + 32
@@ -54,6 +110,8 @@ func (entry *Entry) SizeInBytes() int {
 
 func toBytes(value interface{}) int {
 	switch value.(type) {
+	case Coster:
+		return value.(Coster).CacheCost()
 	case string:
 		return int(unsafe.Sizeof(value)) + len(value.(string))
 	case int8, uint8, bool:
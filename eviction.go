@@ -1,5 +1,10 @@
 package gocache
 
+import (
+	"runtime"
+	"time"
+)
+
 // moveExistingEntryToHead replaces the current c head for an existing entry
 func (c *Cache) moveExistingEntryToHead(entry *Entry) {
 	if !(entry == c.head && entry == c.tail) {
@@ -33,39 +38,215 @@ func (c *Cache) removeExistingEntryReferences(entry *Entry) {
 	if entry.next != nil {
 		entry.next.previous = entry.previous
 	}
+	if c.sieveHand == entry {
+		c.sieveHand = entry.previous
+	}
 	entry.next = nil
 	entry.previous = nil
 }
 
-// evict removes the tail from the cache
-func (c *Cache) evict() {
+// evictAtMost evicts like evict does, but never more than max entries at once, which matters for
+// LeastFrequentUsed, where evict removes every evictable entry of the least frequently used bucket
+//
+// Returns false if there was no entry that could be evicted
+func (c *Cache) evictAtMost(max int) bool {
+	if c.evictionPolicy == LeastFrequentUsed {
+		if c.tail == nil || len(c.entries) == 0 {
+			return false
+		}
+		return c.evictLeastFrequentlyUsed(time.Now().UnixNano(), max)
+	}
+	return c.evict(nil)
+}
+
+// evictLeastFrequentlyUsed evicts the unpinned entries of the least frequently used bucket that has any, but no more
+// than max of them, unless max is 0
+//
+// Returns false if there was no entry that could be evicted
+func (c *Cache) evictLeastFrequentlyUsed(now int64, max int) bool {
+	for item := c.freqs.Front(); item != nil; {
+		next := item.Next()
+		evicted := 0
+		for entry := range item.Value.(*FrequencyItem).Entries {
+			if max > 0 && evicted >= max {
+				break
+			}
+			if !c.evictable(entry, now) {
+				continue
+			}
+			oldEntry := entry
+			c.removeExistingEntryReferences(oldEntry)
+			delete(c.entries, oldEntry.Key)
+			c.removeFromSample(oldEntry)
+			c.removeFromScanBuckets(oldEntry)
+			c.unscheduleExpiration(oldEntry)
+			c.untag(oldEntry)
+			c.removeEntryFromFrequencyList(item, entry)
+			c.stats.EvictedKeys++
+			if c.maxMemoryUsage != NoMaxMemoryUsage {
+				c.memoryUsage -= oldEntry.SizeInBytes()
+			}
+			c.onEvict(oldEntry)
+			evicted++
+		}
+		if evicted > 0 {
+			return true
+		}
+		item = next
+	}
+	return false
+}
+
+// evict removes the tail from the cache, skipping entries that cannot be evicted (see evictable)
+// protected is the entry being written, if any, which MostRecentlyUsed and Sieve must not evict since it has not had
+// a chance to be accessed yet.
+//
+// Returns false if there was no entry that could be evicted
+func (c *Cache) evict(protected *Entry) bool {
 	if c.tail == nil || len(c.entries) == 0 {
-		return
+		return false
 	}
+	now := time.Now().UnixNano()
 
 	if c.evictionPolicy == LeastFrequentUsed {
-		if item := c.freqs.Front(); item != nil {
-			for entry, _ := range item.Value.(*FrequencyItem).Entries {
-				oldEntry := entry
-				c.removeExistingEntryReferences(oldEntry)
-				delete(c.entries, oldEntry.Key)
-				c.removeEntryFromFrequencyList(item, entry)
-				c.stats.EvictedKeys++
-				if c.maxMemoryUsage != NoMaxMemoryUsage {
-					c.memoryUsage -= oldEntry.SizeInBytes()
-				}
-			}
+		return c.evictLeastFrequentlyUsed(now, 0)
+	}
+
+	if c.evictionPolicy == LRUK {
+		victim := c.nextLRUKVictim(now)
+		if victim == nil {
+			return false
 		}
-		return
+		c.delete(victim.Key)
+		c.stats.EvictedKeys++
+		c.onEvict(victim)
+		return true
 	}
 
-	if c.tail != nil {
-		oldTail := c.tail
-		c.removeExistingEntryReferences(oldTail)
-		delete(c.entries, oldTail.Key)
-		if c.maxMemoryUsage != NoMaxMemoryUsage {
-			c.memoryUsage -= oldTail.SizeInBytes()
+	if c.evictionPolicy == Sieve {
+		victim := c.nextSieveVictim(now, protected)
+		if victim == nil {
+			return false
 		}
+		c.delete(victim.Key)
 		c.stats.EvictedKeys++
+		c.onEvict(victim)
+		return true
+	}
+
+	if c.evictionPolicy == MostRecentlyUsed {
+		// The victim is the head rather than the tail, so the list is walked in the other direction
+		victim := c.head
+		for victim != nil && (victim == protected || !c.evictable(victim, now)) {
+			victim = victim.next
+		}
+		if victim == nil {
+			return false
+		}
+		c.delete(victim.Key)
+		c.stats.EvictedKeys++
+		c.onEvict(victim)
+		return true
+	}
+
+	oldTail := c.tail
+	for oldTail != nil && !c.evictable(oldTail, now) {
+		oldTail = oldTail.previous
+	}
+	if oldTail == nil {
+		return false
+	}
+	c.removeExistingEntryReferences(oldTail)
+	delete(c.entries, oldTail.Key)
+	c.removeFromSample(oldTail)
+	c.removeFromScanBuckets(oldTail)
+	c.unscheduleExpiration(oldTail)
+	c.untag(oldTail)
+	if c.maxMemoryUsage != NoMaxMemoryUsage {
+		c.memoryUsage -= oldTail.SizeInBytes()
+	}
+	c.stats.EvictedKeys++
+	c.onEvict(oldTail)
+	return true
+}
+
+// evictable returns whether an entry may be evicted at the given unix time in nanoseconds, which it may not if it is
+// pinned by its minimum lifetime, or if the hooks are an EvictionVetoer that retains it. An entry can only be retained
+// once until it is set again, so if the EvictionVetoer retains it, it is marked as such. Expired entries are never
+// retained.
+//
+// The caller must hold the lock.
+func (c *Cache) evictable(entry *Entry, now int64) bool {
+	if entry.pinned(now) {
+		return false
+	}
+	if c.evictionVetoer == nil || entry.retained {
+		return true
 	}
+	if entry.Expiration > 0 && now > entry.Expiration {
+		return true
+	}
+	if c.evictionVetoer.BeforeEvict(entry.Key, entry.Value) == Retain {
+		entry.retained = true
+		return false
+	}
+	return true
+}
+
+// evictUntilBelowMaxMemoryUsage evicts entries until the memoryUsage is no longer above the maxMemoryUsage, or the
+// memoryLowWatermark if there is one, unless eviction pacing is enabled and the number of entries evicted reaches it,
+// in which case the rest is left to a background goroutine. The caller must hold the lock.
+func (c *Cache) evictUntilBelowMaxMemoryUsage(protected *Entry) {
+	if c.evictPaced(protected) {
+		c.startReclaiming()
+	}
+}
+
+// evictPaced evicts entries until the memoryUsage is no longer above the evictionTarget or until evictionPacing
+// entries have been evicted, and returns whether more entries must be evicted. The caller must hold the lock.
+func (c *Cache) evictPaced(protected *Entry) bool {
+	evictedKeysBefore := c.stats.EvictedKeys
+	target := c.evictionTarget()
+	for c.memoryUsage > target && len(c.entries) > 0 {
+		if c.evictionPacing > 0 && c.stats.EvictedKeys-evictedKeysBefore >= uint64(c.evictionPacing) {
+			return true
+		}
+		if !c.evict(protected) {
+			break
+		}
+	}
+	return false
+}
+
+// evictionTarget returns the memoryUsage down to which entries are evicted once the maxMemoryUsage is exceeded
+func (c *Cache) evictionTarget() int {
+	if c.memoryLowWatermark > 0 && c.memoryLowWatermark < c.maxMemoryUsage {
+		return c.memoryLowWatermark
+	}
+	return c.maxMemoryUsage
+}
+
+// startReclaiming starts a goroutine that evicts entries in batches of evictionPacing until the memoryUsage is no
+// longer above the maxMemoryUsage, unless one is already running. The caller must hold the lock.
+func (c *Cache) startReclaiming() {
+	if c.reclaiming {
+		return
+	}
+	c.reclaiming = true
+	goLabeled(c.name, "eviction-pacing", func() {
+		for {
+			c.mutex.Lock()
+			more := c.evictPaced(nil)
+			if !more {
+				c.reclaiming = false
+			}
+			c.assertInvariants()
+			c.mutex.Unlock()
+			if !more {
+				return
+			}
+			// Give the writes waiting for the lock a chance to go through between batches
+			runtime.Gosched()
+		}
+	})
 }
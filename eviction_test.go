@@ -2,7 +2,9 @@ package gocache
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestCache_EvictionsRespectMaxSize(t *testing.T) {
@@ -325,3 +327,89 @@ func TestCache_HeadToTailSimple(t *testing.T) {
 		t.Error("expected tail=3 and head=5")
 	}
 }
+
+func TestCache_WithEvictionPacing(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMaxMemoryUsage(10*Kilobyte), WithEvictionPacing(5), WithRaceAssertions(true))
+	for n := 0; n < 100; n++ {
+		cache.Set(fmt.Sprintf("%02d", n), "value")
+	}
+	// Pretend that the background evictions are already running, so that only the inline evictions happen
+	cache.reclaiming = true
+	cache.Set("large", strings.Repeat("x", 8*Kilobyte))
+	if evicted := cache.Stats().EvictedKeys; evicted != 5 {
+		t.Errorf("expected the write to have evicted exactly 5 entries inline, got %d", evicted)
+	}
+	cache.mutex.Lock()
+	cache.reclaiming = false
+	cache.startReclaiming()
+	cache.mutex.Unlock()
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		cache.mutex.Lock()
+		memoryUsage, reclaiming := cache.memoryUsage, cache.reclaiming
+		cache.mutex.Unlock()
+		if memoryUsage <= cache.MaxMemoryUsage() && !reclaiming {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatalf("expected the background evictions to bring the memory usage below %d, got %d", cache.MaxMemoryUsage(), memoryUsage)
+		}
+	}
+	if _, ok := cache.Get("large"); !ok {
+		t.Error("expected the large entry to still be in the cache")
+	}
+}
+
+func TestCache_WithEvictionPacingStartsBackgroundEvictions(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMaxMemoryUsage(10*Kilobyte), WithEvictionPacing(1))
+	for n := 0; n < 100; n++ {
+		cache.Set(fmt.Sprintf("%02d", n), "value")
+	}
+	cache.Set("large", strings.Repeat("x", 8*Kilobyte))
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		cache.mutex.Lock()
+		memoryUsage := cache.memoryUsage
+		cache.mutex.Unlock()
+		if memoryUsage <= cache.MaxMemoryUsage() {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatalf("expected the background evictions to bring the memory usage below %d, got %d", cache.MaxMemoryUsage(), memoryUsage)
+		}
+	}
+}
+
+func TestCache_WithMemoryWatermarks(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMemoryWatermarks(10*Kilobyte, 5*Kilobyte), WithRaceAssertions(true))
+	if cache.MaxMemoryUsage() != 10*Kilobyte || cache.MemoryLowWatermark() != 5*Kilobyte {
+		t.Fatalf("expected watermarks of %d and %d, got %d and %d", 10*Kilobyte, 5*Kilobyte, cache.MaxMemoryUsage(), cache.MemoryLowWatermark())
+	}
+	value := strings.Repeat("x", 900)
+	for n := 0; n < 10; n++ {
+		cache.Set(fmt.Sprintf("%02d", n), value)
+	}
+	if cache.Stats().EvictedKeys != 0 {
+		t.Fatalf("expected no eviction below the high watermark, got %d", cache.Stats().EvictedKeys)
+	}
+	// Exceeding the high watermark evicts entries until the memory usage is below the low watermark
+	cache.Set("10", value)
+	if memoryUsage := cache.MemoryUsage(); memoryUsage > 5*Kilobyte {
+		t.Errorf("expected the memory usage to be below the low watermark, got %d", memoryUsage)
+	}
+	evicted := cache.Stats().EvictedKeys
+	if evicted < 5 {
+		t.Errorf("expected at least 5 entries to have been evicted, got %d", evicted)
+	}
+	// The room freed allows the next writes not to evict anything
+	cache.Set("11", value)
+	cache.Set("12", value)
+	if cache.Stats().EvictedKeys != evicted {
+		t.Errorf("expected no eviction until the high watermark is exceeded again, got %d", cache.Stats().EvictedKeys-evicted)
+	}
+}
+
+func TestWithMemoryWatermarksWhenLowIsNotBelowHigh(t *testing.T) {
+	cache := NewCache(WithMemoryWatermarks(10*Kilobyte, 20*Kilobyte))
+	if cache.MaxMemoryUsage() != 10*Kilobyte || cache.MemoryLowWatermark() != 0 {
+		t.Errorf("expected the low watermark to be ignored, got %d and %d", cache.MaxMemoryUsage(), cache.MemoryLowWatermark())
+	}
+}
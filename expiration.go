@@ -0,0 +1,336 @@
+package gocache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// DefaultExpirationEpsilon is how long after their expiration entries are deleted if WithPreciseExpiration is enabled
+// and WithExpirationEpsilon is not used
+const DefaultExpirationEpsilon = 10 * time.Millisecond
+
+// WithPreciseExpiration sets whether expired entries are actively deleted within the expiration epsilon (see
+// WithExpirationEpsilon) of their expiration, which is meant for caches holding security-sensitive data, such as
+// tokens, for which serving expired data is unacceptable.
+//
+// While Get and similar functions never return entries that have expired, expired entries otherwise stay in the cache
+// until they are accessed, found by the janitor or evicted, which means that they are still counted by Count, still
+// take up memory and that OnExpire may be called long after they expire. Furthermore, GetOrRefresh may serve them if
+// ServeStaleMax is set. With precise expiration, entries are tracked in a timer wheel whose slots are as wide as the
+// expiration epsilon, and a background goroutine wakes up at the end of each slot holding entries to delete them. Stale
+// entries are never served, regardless of ServeStaleMax. The goroutine only runs while there are entries with an
+// expiration time in the cache, or until StopExpiring is called.
+//
+// Defaults to false
+func WithPreciseExpiration(preciseExpiration bool) func(c *Cache) {
+	return func(c *Cache) {
+		c.preciseExpiration = preciseExpiration
+	}
+}
+
+// WithExpirationEpsilon sets how long after their expiration entries are deleted at most if WithPreciseExpiration is
+// enabled. The smaller the epsilon, the more often the background goroutine wakes up.
+// Defaults to DefaultExpirationEpsilon
+func WithExpirationEpsilon(epsilon time.Duration) func(c *Cache) {
+	return func(c *Cache) {
+		if epsilon <= 0 {
+			epsilon = DefaultExpirationEpsilon
+		}
+		c.expirationEpsilon = epsilon
+	}
+}
+
+// WithTTLBuckets rounds the expiration time of every entry up to the next multiple of the granularity passed as
+// parameter, so that the entries expiring within the same bucket of that granularity all expire at the same time.
+// The entries are tracked in a set per bucket, and a background goroutine deletes the entries of each bucket as a
+// whole as soon as it has passed, which makes mass expiration much cheaper than having the janitor look for expired
+// entries one by one, at the cost of entries expiring up to one granularity later than their TTL.
+//
+// Like with WithPreciseExpiration, which uses buckets of the expiration epsilon without rounding the expiration
+// times, expired entries are not served by GetOrRefresh, regardless of ServeStaleMax. If both are used, the buckets are
+// as wide as the granularity. The goroutine only runs while there are entries with an expiration time in the cache,
+// or until StopExpiring is called.
+//
+// Defaults to 0, meaning that expiration times are not rounded
+func WithTTLBuckets(granularity time.Duration) func(c *Cache) {
+	return func(c *Cache) {
+		if granularity < 0 {
+			granularity = 0
+		}
+		c.ttlBucketGranularity = granularity
+	}
+}
+
+// TTLBucketGranularity returns the granularity the expiration times are rounded up to, or 0 if they aren't rounded
+func (c *Cache) TTLBucketGranularity() time.Duration {
+	return c.ttlBucketGranularity
+}
+
+// PreciseExpiration returns whether expired entries are deleted within the expiration epsilon of their expiration
+func (c *Cache) PreciseExpiration() bool {
+	return c.preciseExpiration
+}
+
+// ExpirationEpsilon returns how long after their expiration entries are deleted at most if PreciseExpiration is true
+func (c *Cache) ExpirationEpsilon() time.Duration {
+	return c.expirationEpsilon
+}
+
+// staleGrace returns how long after their expiration entries may be served by GetOrRefresh, which is always 0 if
+// expired entries are actively deleted
+func (c *Cache) staleGrace() time.Duration {
+	if c.tracksExpirations() {
+		return 0
+	}
+	return c.serveStaleMax
+}
+
+// tracksExpirations returns whether entries are tracked in the expirationWheel, which is the case if either
+// preciseExpiration is enabled or expiration times are rounded to TTL buckets
+func (c *Cache) tracksExpirations() bool {
+	return c.preciseExpiration || c.ttlBucketGranularity > 0
+}
+
+// slotWidth returns the width of the slots of the expirationWheel in nanoseconds
+func (c *Cache) slotWidth() int64 {
+	if c.ttlBucketGranularity > 0 {
+		return int64(c.ttlBucketGranularity)
+	}
+	return int64(c.expirationEpsilon)
+}
+
+// bucketed returns the expiration time passed as parameter rounded up to the next multiple of the TTL bucket
+// granularity, or as is if it isn't rounded or if it is NoExpiration or expiresInstantly
+func (c *Cache) bucketed(expiration int64) int64 {
+	if c.ttlBucketGranularity <= 0 || expiration <= 0 {
+		return expiration
+	}
+	granularity := int64(c.ttlBucketGranularity)
+	return (expiration + granularity - 1) / granularity * granularity
+}
+
+// scheduleExpiration moves an entry to the slot of the expirationWheel matching its expiration, starting the
+// goroutine deleting expired entries if it isn't running. It does nothing unless expirations are tracked.
+//
+// The caller must hold the lock.
+func (c *Cache) scheduleExpiration(entry *Entry) {
+	if !c.tracksExpirations() {
+		return
+	}
+	c.unscheduleExpiration(entry)
+	if entry.Expiration <= 0 {
+		// The entry never expires
+		return
+	}
+	width := c.slotWidth()
+	if c.stopExpiring == nil && len(c.expirationWheel) == 0 {
+		// The slots of the entries left in the wheel by StopExpiring, if any, must still be expired
+		c.lastExpiredSlot = time.Now().UnixNano()/width - 1
+	}
+	// The slot is the one at the end of which the entry has expired, which, for an expiration rounded to a TTL bucket,
+	// is the slot ending at the boundary of the bucket
+	slot := (entry.Expiration + width - 1) / width
+	if slot <= c.lastExpiredSlot {
+		slot = c.lastExpiredSlot + 1
+	}
+	if c.expirationWheel == nil {
+		c.expirationWheel = make(map[int64]*expirationSlot)
+	}
+	wheelSlot := c.expirationWheel[slot]
+	if wheelSlot == nil {
+		wheelSlot = &expirationSlot{slot: slot, entries: make(map[*Entry]struct{})}
+		c.expirationWheel[slot] = wheelSlot
+		heap.Push(&c.expirationSlots, wheelSlot)
+		if c.expirationSlots[0] == wheelSlot {
+			// The goroutine may be sleeping until the end of a later slot
+			c.wakeUpExpiring()
+		}
+	}
+	wheelSlot.entries[entry] = struct{}{}
+	entry.expirationSlot = slot
+	c.startExpiring()
+}
+
+// unscheduleExpiration removes an entry from the expirationWheel, if it is in it
+//
+// The caller must hold the lock.
+func (c *Cache) unscheduleExpiration(entry *Entry) {
+	if entry.expirationSlot == 0 {
+		return
+	}
+	if wheelSlot := c.expirationWheel[entry.expirationSlot]; wheelSlot != nil {
+		delete(wheelSlot.entries, entry)
+		if len(wheelSlot.entries) == 0 {
+			delete(c.expirationWheel, entry.expirationSlot)
+			root := wheelSlot.index == 0
+			heap.Remove(&c.expirationSlots, wheelSlot.index)
+			if root {
+				// The goroutine is sleeping until the end of the removed slot, and may have no slot left to expire
+				c.wakeUpExpiring()
+			}
+		}
+	}
+	entry.expirationSlot = 0
+}
+
+// StopExpiring stops the background goroutine deleting expired entries if WithPreciseExpiration or WithTTLBuckets is
+// used, which otherwise keeps the cache from being garbage collected while it has entries with an expiration time.
+// Expired entries are then left in the cache until they are accessed, found by the janitor or evicted. The goroutine
+// is started again by the next write of an entry with an expiration time.
+func (c *Cache) StopExpiring() {
+	c.mutex.Lock()
+	if c.stopExpiring != nil {
+		close(c.stopExpiring)
+		c.stopExpiring = nil
+		c.wakeExpiring = nil
+	}
+	c.mutex.Unlock()
+}
+
+// wakeUpExpiring wakes the goroutine deleting the entries of the expirationWheel up, if it is running, so that it
+// sleeps until the end of the earliest slot again, or stops if the expirationWheel is empty
+//
+// The caller must hold the lock.
+func (c *Cache) wakeUpExpiring() {
+	if c.wakeExpiring == nil {
+		return
+	}
+	select {
+	case c.wakeExpiring <- struct{}{}:
+	default:
+	}
+}
+
+// startExpiring starts a goroutine that deletes the entries of each slot of the expirationWheel once it has passed,
+// until the expirationWheel is empty or StopExpiring is called, unless one is already running.
+//
+// The caller must hold the lock.
+func (c *Cache) startExpiring() {
+	if c.stopExpiring != nil {
+		return
+	}
+	stop, wake := make(chan struct{}), make(chan struct{}, 1)
+	c.stopExpiring, c.wakeExpiring = stop, wake
+	next := c.nextSlotEnd()
+	goLabeled(c.name, "expiration", func() {
+		timer := time.NewTimer(time.Until(next))
+		defer timer.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-timer.C:
+			case <-wake:
+				// The timer must be stopped before being reset, and may have fired in the meantime
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			}
+			c.mutex.Lock()
+			if c.stopExpiring != stop {
+				// StopExpiring was called while waiting for the lock
+				c.mutex.Unlock()
+				return
+			}
+			more := c.expireSlots(time.Now().UnixNano() / c.slotWidth())
+			if !more {
+				c.stopExpiring, c.wakeExpiring = nil, nil
+			}
+			next = c.nextSlotEnd()
+			c.assertInvariants()
+			c.mutex.Unlock()
+			if !more {
+				return
+			}
+			timer.Reset(time.Until(next))
+		}
+	})
+}
+
+// nextSlotEnd returns the time at which the earliest slot of the expirationWheel has passed, which is just after its
+// end, since entries only expire once their expiration time is over (see Entry.Expired), or the time at which the
+// slot following the last expired one has passed if the expirationWheel is empty
+//
+// The caller must hold the lock.
+func (c *Cache) nextSlotEnd() time.Time {
+	slot := c.lastExpiredSlot + 1
+	if len(c.expirationSlots) > 0 {
+		slot = c.expirationSlots[0].slot
+	}
+	return time.Unix(0, slot*c.slotWidth()+1)
+}
+
+// expireSlots deletes the entries of the slots of the expirationWheel up to the given slot, and returns whether there
+// are entries left in the expirationWheel. The caller must hold the lock.
+//
+// Only the slots holding entries are visited, in order, so that the cost doesn't depend on how long ago the last slot
+// was expired.
+func (c *Cache) expireSlots(upToSlot int64) bool {
+	var notExpired []*Entry
+	for len(c.expirationSlots) > 0 && c.expirationSlots[0].slot <= upToSlot {
+		wheelSlot := heap.Pop(&c.expirationSlots).(*expirationSlot)
+		delete(c.expirationWheel, wheelSlot.slot)
+		for entry := range wheelSlot.entries {
+			entry.expirationSlot = 0
+			if !entry.Expired() {
+				// Since the slot of an entry is the one at the end of which it has expired, this only happens if the
+				// clock went backwards, in which case the entry is moved to a later slot rather than deleted early
+				notExpired = append(notExpired, entry)
+				continue
+			}
+			c.delete(entry.Key)
+			c.stats.ExpiredKeys++
+			c.onExpire(entry)
+		}
+	}
+	if upToSlot > c.lastExpiredSlot {
+		c.lastExpiredSlot = upToSlot
+	}
+	for _, entry := range notExpired {
+		c.scheduleExpiration(entry)
+	}
+	return len(c.expirationWheel) > 0
+}
+
+// expirationSlot is a slot of the expirationWheel, which holds the entries that expire within it
+type expirationSlot struct {
+	slot    int64
+	entries map[*Entry]struct{}
+
+	// index is the index of the slot in the expirationSlotHeap
+	index int
+}
+
+// expirationSlotHeap is a min-heap of the slots of the expirationWheel, so that the root is the next slot to expire
+type expirationSlotHeap []*expirationSlot
+
+func (h expirationSlotHeap) Len() int {
+	return len(h)
+}
+
+func (h expirationSlotHeap) Less(i, j int) bool {
+	return h[i].slot < h[j].slot
+}
+
+func (h expirationSlotHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expirationSlotHeap) Push(x interface{}) {
+	wheelSlot := x.(*expirationSlot)
+	wheelSlot.index = len(*h)
+	*h = append(*h, wheelSlot)
+}
+
+func (h *expirationSlotHeap) Pop() interface{} {
+	old := *h
+	wheelSlot := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return wheelSlot
+}
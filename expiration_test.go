@@ -0,0 +1,193 @@
+package gocache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type expirationCountingHooks struct {
+	NoopHooks
+	expired int32
+}
+
+func (hooks *expirationCountingHooks) OnExpire(string, interface{}) {
+	atomic.AddInt32(&hooks.expired, 1)
+}
+
+func TestWithPreciseExpiration(t *testing.T) {
+	hooks := &expirationCountingHooks{}
+	cache := NewCache(WithPreciseExpiration(true), WithExpirationEpsilon(5*time.Millisecond), WithHooks(hooks), WithRaceAssertions(true))
+	if !cache.PreciseExpiration() || cache.ExpirationEpsilon() != 5*time.Millisecond {
+		t.Fatalf("expected precise expiration with an epsilon of 5ms, got %v and %s", cache.PreciseExpiration(), cache.ExpirationEpsilon())
+	}
+	for n := 0; n < 100; n++ {
+		cache.SetWithTTL(fmt.Sprintf("token-%02d", n), n, 20*time.Millisecond)
+	}
+	cache.Set("no-expiration", "value")
+	cache.SetWithTTL("extended", "value", 20*time.Millisecond)
+	cache.Expire("extended", time.Hour)
+	time.Sleep(50 * time.Millisecond)
+	// The expired entries were deleted without being accessed
+	if count := cache.Count(); count != 2 {
+		t.Errorf("expected the expired entries to have been deleted, got %d entries", count)
+	}
+	if expired := atomic.LoadInt32(&hooks.expired); expired != 100 {
+		t.Errorf("expected OnExpire to have been called 100 times, got %d", expired)
+	}
+	if cache.Stats().ExpiredKeys != 100 {
+		t.Errorf("expected 100 expired keys, got %d", cache.Stats().ExpiredKeys)
+	}
+	cache.Delete("extended")
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		cache.mutex.Lock()
+		expiring := cache.stopExpiring != nil
+		cache.mutex.Unlock()
+		if !expiring {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal("expected the expiration goroutine to have stopped once no entry could expire")
+		}
+	}
+}
+
+func TestWithPreciseExpirationNeverServesStaleEntries(t *testing.T) {
+	cache := NewCache(WithPreciseExpiration(true), WithServeStaleMax(time.Hour))
+	cache.SetWithTTL("token", "value", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	value, err := cache.GetOrRefresh("token", time.Hour, func(string) (interface{}, error) {
+		return nil, fmt.Errorf("unavailable")
+	})
+	if err == nil {
+		t.Errorf("expected the refresh error rather than the stale value, got %v", value)
+	}
+}
+
+func TestCache_ExpireSlotsReschedulesEntriesThatHaveNotExpired(t *testing.T) {
+	cache := NewCache(WithPreciseExpiration(true), WithExpirationEpsilon(time.Hour))
+	cache.SetWithTTL("key", "value", time.Hour)
+	cache.mutex.Lock()
+	entry := cache.entries["key"]
+	// Pretend that the clock went backwards
+	cache.expireSlots(entry.expirationSlot)
+	slot := entry.expirationSlot
+	cache.mutex.Unlock()
+	if _, ok := cache.Get("key"); !ok {
+		t.Fatal("expected the entry not to have been deleted early")
+	}
+	if slot == 0 {
+		t.Error("expected the entry to have been scheduled again")
+	}
+}
+
+func TestWithTTLBuckets(t *testing.T) {
+	hooks := &expirationCountingHooks{}
+	granularity := 20 * time.Millisecond
+	cache := NewCache(WithTTLBuckets(granularity), WithHooks(hooks), WithRaceAssertions(true))
+	if cache.TTLBucketGranularity() != granularity {
+		t.Fatalf("expected a granularity of %s, got %s", granularity, cache.TTLBucketGranularity())
+	}
+	for n := 0; n < 100; n++ {
+		cache.SetWithTTL(fmt.Sprintf("key-%02d", n), n, time.Duration(n%10+1)*time.Millisecond)
+	}
+	cache.Set("no-expiration", "value")
+	cache.SetWithTTL("extended", "value", time.Millisecond)
+	cache.Expire("extended", time.Hour)
+	cache.mutex.Lock()
+	for key, entry := range cache.entries {
+		if entry.Expiration > 0 && entry.Expiration%int64(granularity) != 0 {
+			t.Errorf("expected the expiration of %s to have been rounded to the granularity", key)
+		}
+	}
+	// The entries expire within 10ms, so they share a few buckets at most, even if setting them was slow
+	if buckets := len(cache.expirationWheel); buckets > 5 {
+		t.Errorf("expected the entries to share a few buckets, got %d", buckets)
+	}
+	cache.mutex.Unlock()
+	time.Sleep(3 * granularity)
+	if count := cache.Count(); count != 2 {
+		t.Errorf("expected the expired entries to have been deleted, got %d entries", count)
+	}
+	if expired := atomic.LoadInt32(&hooks.expired); expired != 100 {
+		t.Errorf("expected OnExpire to have been called 100 times, got %d", expired)
+	}
+	if ttl, err := cache.TTL("extended"); err != nil || ttl < 59*time.Minute {
+		t.Errorf("expected the extended entry to expire in an hour, got %s, %v", ttl, err)
+	}
+}
+
+func TestWithTTLBucketsNeverExpiresEarly(t *testing.T) {
+	cache := NewCache(WithTTLBuckets(time.Hour))
+	cache.SetWithTTL("key", "value", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cache.Get("key"); !ok {
+		t.Error("expected the expiration to have been rounded up to the next bucket")
+	}
+	cache.Expire("key", 0)
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected a TTL of 0 to expire the key immediately")
+	}
+}
+
+func TestWithPreciseExpirationDeletesWithinEpsilon(t *testing.T) {
+	cache := NewCache(WithPreciseExpiration(true), WithExpirationEpsilon(50*time.Millisecond))
+	cache.SetWithTTL("token", "value", 10*time.Millisecond)
+	expiration := time.Now().Add(10 * time.Millisecond)
+	for cache.Count() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// The deadline leaves some room for the scheduling of the goroutine, but less than a second slot
+	if late := time.Since(expiration); late > 75*time.Millisecond {
+		t.Errorf("expected the entry to have been deleted within the epsilon of its expiration, got %s late", late)
+	}
+}
+
+func TestCache_StopExpiring(t *testing.T) {
+	cache := NewCache(WithPreciseExpiration(true), WithExpirationEpsilon(time.Millisecond))
+	cache.SetWithTTL("key", "value", 5*time.Millisecond)
+	cache.StopExpiring()
+	cache.mutex.Lock()
+	stopped := cache.stopExpiring == nil
+	cache.mutex.Unlock()
+	if !stopped {
+		t.Fatal("expected the expiration goroutine to have been stopped")
+	}
+	cache.StopExpiring()
+	time.Sleep(20 * time.Millisecond)
+	if count := cache.Count(); count != 1 {
+		t.Fatalf("expected the expired entry not to have been deleted while stopped, got %d entries", count)
+	}
+	// The slots that passed while the goroutine was stopped are still expired once it is started again
+	cache.SetWithTTL("token", "value", time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if count := cache.Count(); count != 0 {
+		t.Errorf("expected both entries to have been deleted, got %d entries", count)
+	}
+}
+
+func TestWithPreciseExpirationOnlyVisitsSlotsHoldingEntries(t *testing.T) {
+	cache := NewCache(WithPreciseExpiration(true), WithExpirationEpsilon(time.Millisecond))
+	cache.SetWithTTL("token", "value", time.Millisecond)
+	cache.SetWithTTL("session", "value", time.Hour)
+	cache.StopExpiring()
+	time.Sleep(2 * time.Millisecond)
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if next := cache.nextSlotEnd(); time.Until(next) > 0 {
+		t.Errorf("expected the goroutine to wake up at the end of the slot of token, got %s", next)
+	}
+	// Walking every slot since the epoch would never end
+	cache.lastExpiredSlot = 0
+	if more := cache.expireSlots(time.Now().UnixNano() / cache.slotWidth()); !more {
+		t.Error("expected session to be left in the wheel")
+	}
+	if _, ok := cache.entries["token"]; ok {
+		t.Error("expected token to have been deleted")
+	}
+	if next := cache.nextSlotEnd(); time.Until(next) < 59*time.Minute {
+		t.Errorf("expected the goroutine to sleep until the end of the slot of session, got %s", next)
+	}
+}
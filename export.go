@@ -0,0 +1,95 @@
+package gocache
+
+import (
+	"bufio"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// ExportedEntry is the format of each line written by ExportKeys and ExportByTag
+type ExportedEntry struct {
+	// Key is the name of the cache entry
+	Key string `json:"key"`
+
+	// Value is the value of the cache entry
+	Value interface{} `json:"value"`
+
+	// ExpiresAt is the time at which the entry will expire, or nil if it never expires
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Checksum is the CRC-32 checksum of the JSON encoding of the value, or nil if checksums are disabled
+	// See WithChecksums
+	Checksum *uint32 `json:"checksum,omitempty"`
+
+	// Version is the schema version of the value (see WithMigrations), which is 0 if no migration was registered
+	Version int `json:"version,omitempty"`
+}
+
+// ExportKeys writes the entries whose key matches the given pattern to w as JSON lines, one ExportedEntry per line,
+// which makes it possible to dump a subset of the cache (e.g. a single tenant) for debugging or migration.
+//
+// Expired entries are skipped. Like GetKeysByPattern, this does not count as accessing the entries.
+// The entries are copied under the lock and encoded after it has been released, so values must be safe to read
+// concurrently and encodable by encoding/json; if a value cannot be encoded, the export stops and the error is
+// returned along with the number of entries written until then.
+func (c *Cache) ExportKeys(w io.Writer, pattern string) (int, error) {
+	c.mutex.RLock()
+	var entries []ExportedEntry
+	for key, entry := range c.entries {
+		if !entry.Expired() && MatchPattern(pattern, key) {
+			entries = append(entries, c.newExportedEntry(entry))
+		}
+	}
+	c.mutex.RUnlock()
+	return c.writeExportedEntries(w, entries)
+}
+
+// ExportByTag writes the entries whose value is a Tagger with the given tag to w, in the same format as ExportKeys
+func (c *Cache) ExportByTag(w io.Writer, tag string) (int, error) {
+	c.mutex.RLock()
+	var entries []ExportedEntry
+	for key := range c.tags[tag] {
+		if entry := c.entries[key]; !entry.Expired() {
+			entries = append(entries, c.newExportedEntry(entry))
+		}
+	}
+	c.mutex.RUnlock()
+	return c.writeExportedEntries(w, entries)
+}
+
+func (c *Cache) newExportedEntry(entry *Entry) ExportedEntry {
+	exportedEntry := ExportedEntry{Key: entry.Key, Value: entry.Value, Version: c.schemaVersion}
+	if entry.Expiration != NoExpiration {
+		expiresAt := time.Unix(0, entry.Expiration)
+		exportedEntry.ExpiresAt = &expiresAt
+	}
+	return exportedEntry
+}
+
+// writeExportedEntries writes the entries passed as parameter to w as JSON lines, along with the checksum of their
+// value if checksums are enabled, and returns the number written
+func (c *Cache) writeExportedEntries(w io.Writer, entries []ExportedEntry) (int, error) {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	numberOfEntriesWritten := 0
+	for _, entry := range entries {
+		if c.checksums {
+			// The value is encoded beforehand so that the checksum is computed from the exact bytes written
+			value, err := json.Marshal(entry.Value)
+			if err != nil {
+				writer.Flush()
+				return numberOfEntriesWritten, err
+			}
+			checksum := crc32.ChecksumIEEE(value)
+			entry.Value, entry.Checksum = json.RawMessage(value), &checksum
+		}
+		if err := encoder.Encode(entry); err != nil {
+			writer.Flush()
+			return numberOfEntriesWritten, err
+		}
+		numberOfEntriesWritten++
+	}
+	return numberOfEntriesWritten, writer.Flush()
+}
@@ -0,0 +1,70 @@
+package gocache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readExportedEntries(t *testing.T, buffer *bytes.Buffer) map[string]ExportedEntry {
+	entries := make(map[string]ExportedEntry)
+	scanner := bufio.NewScanner(buffer)
+	for scanner.Scan() {
+		var entry ExportedEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal("expected each line to be a JSON-encoded entry, got", scanner.Text())
+		}
+		entries[entry.Key] = entry
+	}
+	return entries
+}
+
+func TestCache_ExportKeys(t *testing.T) {
+	cache := NewCache()
+	cache.Set("tenant1:a", "value")
+	cache.SetWithTTL("tenant1:b", 42, time.Hour)
+	cache.SetWithTTL("tenant1:expired", "value", time.Nanosecond)
+	cache.Set("tenant2:a", "value")
+	time.Sleep(time.Millisecond)
+	var buffer bytes.Buffer
+	exported, err := cache.ExportKeys(&buffer, "tenant1:*")
+	if err != nil || exported != 2 {
+		t.Fatalf("expected 2 entries to have been exported without error, got %d and %v", exported, err)
+	}
+	entries := readExportedEntries(t, &buffer)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(entries))
+	}
+	if entries["tenant1:a"].Value != "value" || entries["tenant1:a"].ExpiresAt != nil {
+		t.Error("expected tenant1:a to have been exported with value value and no expiration, got", entries["tenant1:a"])
+	}
+	if entries["tenant1:b"].Value != float64(42) || entries["tenant1:b"].ExpiresAt == nil || time.Until(*entries["tenant1:b"].ExpiresAt) < 59*time.Minute {
+		t.Error("expected tenant1:b to have been exported with value 42 and its expiration, got", entries["tenant1:b"])
+	}
+}
+
+func TestCache_ExportKeysWhenValueCannotBeEncoded(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", make(chan int))
+	var buffer bytes.Buffer
+	if exported, err := cache.ExportKeys(&buffer, "*"); err == nil || exported != 0 {
+		t.Errorf("expected an error and no entry exported, got %d and %v", exported, err)
+	}
+}
+
+func TestCache_ExportByTag(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", taggedValue{"tenant1"})
+	cache.Set("2", taggedValue{"tenant2"})
+	var buffer bytes.Buffer
+	exported, err := cache.ExportByTag(&buffer, "tenant1")
+	if err != nil || exported != 1 {
+		t.Fatalf("expected 1 entry to have been exported without error, got %d and %v", exported, err)
+	}
+	if !strings.HasPrefix(buffer.String(), `{"key":"1","value":["tenant1"]}`) {
+		t.Error("expected the entry tagged with tenant1 to have been exported, got", buffer.String())
+	}
+}
@@ -0,0 +1,92 @@
+package gocache
+
+import "time"
+
+// FailureCachingMaxTrackedKeys is the maximum number of keys whose failures are cached at once (see
+// WithFailureCaching). Once it is reached, every failure is forgotten, so that keys that keep failing cannot grow
+// the memory usage of the cache indefinitely.
+const FailureCachingMaxTrackedKeys = 10000
+
+// failure is the last error returned by the refresh function passed to GetOrRefresh for a key
+type failure struct {
+	// err is the error returned by the refresh function
+	err error
+
+	// until is the unix time in nanoseconds until which err is returned instead of calling the refresh function
+	until int64
+
+	// consecutiveFailures is the number of times in a row the refresh function failed for the key
+	consecutiveFailures int
+}
+
+// WithFailureCaching makes GetOrRefresh cache the errors returned by the refresh function, so that a key whose
+// refresh fails is not refreshed again until its error expires, which protects flaky upstreams from being hammered.
+// Until then, GetOrRefresh returns the same error, or the stale value if there is one (see WithServeStaleMax).
+//
+// Errors are cached for baseTTL after the first failure, and the TTL doubles with every consecutive failure of the
+// same key, up to maxTTL. A successful refresh resets the backoff, as does not refreshing the key for maxTTL after
+// its last error expired. Contexts returned by WithBypass and WithForceRefresh ignore the cached errors.
+//
+// A baseTTL of 0 or less disables failure caching, which is the default. A maxTTL lower than baseTTL is treated as
+// baseTTL. See FailureCachingMaxTrackedKeys
+func WithFailureCaching(baseTTL, maxTTL time.Duration) func(c *Cache) {
+	return func(c *Cache) {
+		if baseTTL < 0 {
+			baseTTL = 0
+		}
+		if maxTTL < baseTTL {
+			maxTTL = baseTTL
+		}
+		c.failureBaseTTL, c.failureMaxTTL = baseTTL, maxTTL
+		c.failures = nil
+	}
+}
+
+// cachedFailure returns the cached error of the key passed as parameter, if it hasn't expired
+func (c *Cache) cachedFailure(key string) error {
+	if c.failureBaseTTL <= 0 {
+		return nil
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if cached, ok := c.failures[key]; ok && time.Now().UnixNano() < cached.until {
+		return cached.err
+	}
+	return nil
+}
+
+// recordRefresh caches the error returned by the refresh function for the key passed as parameter with a TTL that
+// grows exponentially with the number of consecutive failures, or forgets the failures of the key if err is nil
+func (c *Cache) recordRefresh(key string, err error) {
+	if c.failureBaseTTL <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err == nil {
+		delete(c.failures, key)
+		return
+	}
+	now := time.Now().UnixNano()
+	cached, ok := c.failures[key]
+	if !ok || now > cached.until+int64(c.failureMaxTTL) {
+		if !ok && len(c.failures) >= FailureCachingMaxTrackedKeys {
+			c.failures = nil
+		}
+		if c.failures == nil {
+			c.failures = make(map[string]*failure)
+		}
+		cached = &failure{}
+		c.failures[key] = cached
+	}
+	cached.consecutiveFailures++
+	ttl := c.failureBaseTTL
+	for i := 1; i < cached.consecutiveFailures && ttl < c.failureMaxTTL; i++ {
+		ttl *= 2
+	}
+	if ttl > c.failureMaxTTL {
+		ttl = c.failureMaxTTL
+	}
+	cached.err = err
+	cached.until = now + int64(ttl)
+}
@@ -0,0 +1,94 @@
+package gocache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithFailureCaching(t *testing.T) {
+	cache := NewCache(WithFailureCaching(50*time.Millisecond, 200*time.Millisecond))
+	errUnavailable := errors.New("unavailable")
+	calls := 0
+	failing := func(string) (interface{}, error) {
+		calls++
+		return nil, errUnavailable
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := cache.GetOrRefresh("key", time.Hour, failing); err != errUnavailable {
+			t.Fatalf("expected the error to be returned, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the error to have been cached, got %d calls", calls)
+	}
+	// The error was cached for 50ms, after which the key is refreshed again and its error cached for 100ms
+	time.Sleep(60 * time.Millisecond)
+	cache.GetOrRefresh("key", time.Hour, failing)
+	time.Sleep(30 * time.Millisecond)
+	cache.GetOrRefresh("key", time.Hour, failing)
+	if calls != 2 {
+		t.Errorf("expected the backoff to have doubled, got %d calls", calls)
+	}
+	cache.mutex.RLock()
+	consecutiveFailures, ttl := cache.failures["key"].consecutiveFailures, time.Duration(cache.failures["key"].until-time.Now().UnixNano())
+	cache.mutex.RUnlock()
+	if consecutiveFailures != 2 || ttl > 70*time.Millisecond {
+		t.Errorf("expected 2 consecutive failures with at most 70ms left, got %d and %s", consecutiveFailures, ttl)
+	}
+	// Forcing a refresh ignores the cached error, and a successful refresh resets the backoff
+	value, err := cache.GetOrRefreshCtx(WithForceRefresh(context.Background()), "key", time.Hour, func(string) (interface{}, error) {
+		return "value", nil
+	})
+	if err != nil || value != "value" {
+		t.Fatalf("expected value, got %v and %v", value, err)
+	}
+	cache.mutex.RLock()
+	_, tracked := cache.failures["key"]
+	cache.mutex.RUnlock()
+	if tracked {
+		t.Error("expected the failures of the key to have been forgotten")
+	}
+}
+
+func TestWithFailureCachingCapsBackoff(t *testing.T) {
+	cache := NewCache(WithFailureCaching(time.Second, 4*time.Second))
+	for i := 0; i < 10; i++ {
+		cache.recordRefresh("key", errors.New("unavailable"))
+	}
+	cache.mutex.RLock()
+	ttl := time.Duration(cache.failures["key"].until - time.Now().UnixNano())
+	cache.mutex.RUnlock()
+	if ttl > 4*time.Second || ttl < 3*time.Second {
+		t.Errorf("expected the error to be cached for the max TTL, got %s", ttl)
+	}
+}
+
+func TestWithFailureCachingServesStaleValue(t *testing.T) {
+	cache := NewCache(WithFailureCaching(time.Hour, time.Hour), WithServeStaleMax(time.Hour))
+	cache.SetWithTTL("key", "stale", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		value, err := cache.GetOrRefresh("key", time.Hour, func(string) (interface{}, error) {
+			return nil, errors.New("unavailable")
+		})
+		if err != nil || value != "stale" {
+			t.Errorf("expected the stale value, got %v and %v", value, err)
+		}
+	}
+}
+
+func TestWithFailureCachingWhenDisabled(t *testing.T) {
+	cache := NewCache()
+	calls := 0
+	for i := 0; i < 3; i++ {
+		cache.GetOrRefresh("key", time.Hour, func(string) (interface{}, error) {
+			calls++
+			return nil, errors.New("unavailable")
+		})
+	}
+	if calls != 3 || cache.failures != nil {
+		t.Errorf("expected errors not to have been cached, got %d calls", calls)
+	}
+}
@@ -4,11 +4,65 @@ import (
 	"container/list"
 )
 
+// FrequencyItem is a bucket of entries which have all been accessed the same number of times
 type FrequencyItem struct {
 	Entries map[*Entry]byte // Set of entries
 	Freq    int             // Access frequency
 }
 
+// FrequencyBucket is a snapshot of a FrequencyItem
+type FrequencyBucket struct {
+	// Frequency is the number of times each entry in the bucket has been accessed
+	Frequency int
+
+	// Keys are the keys of the entries in the bucket
+	Keys []string
+}
+
+// FrequencyHistogram returns the number of entries for each access frequency
+//
+// This is only relevant if the EvictionPolicy is LeastFrequentUsed. For any other eviction policy, the map returned
+// will always be empty.
+func (c *Cache) FrequencyHistogram() map[int]int {
+	histogram := make(map[int]int)
+	c.mutex.RLock()
+	if c.freqs != nil {
+		for element := c.freqs.Front(); element != nil; element = element.Next() {
+			frequencyItem := element.Value.(*FrequencyItem)
+			histogram[frequencyItem.Freq] = len(frequencyItem.Entries)
+		}
+	}
+	c.mutex.RUnlock()
+	return histogram
+}
+
+// RangeFrequencyBuckets calls fn for each frequency bucket, from the least frequently used bucket (the next to be
+// evicted) to the most frequently used one. If fn returns false, the iteration stops.
+//
+// The buckets are snapshotted before fn is first called, so fn may safely call other methods of the cache.
+// This is only relevant if the EvictionPolicy is LeastFrequentUsed. For any other eviction policy, fn is never called.
+func (c *Cache) RangeFrequencyBuckets(fn func(bucket FrequencyBucket) bool) {
+	var buckets []FrequencyBucket
+	c.mutex.RLock()
+	if c.freqs != nil {
+		buckets = make([]FrequencyBucket, 0, c.freqs.Len())
+		for element := c.freqs.Front(); element != nil; element = element.Next() {
+			frequencyItem := element.Value.(*FrequencyItem)
+			bucket := FrequencyBucket{Frequency: frequencyItem.Freq, Keys: make([]string, 0, len(frequencyItem.Entries))}
+			for entry := range frequencyItem.Entries {
+				bucket.Keys = append(bucket.Keys, entry.Key)
+			}
+			buckets = append(buckets, bucket)
+		}
+	}
+	c.mutex.RUnlock()
+	for _, bucket := range buckets {
+		if !fn(bucket) {
+			return
+		}
+	}
+}
+
 func (c *Cache) incrementEntryFrequency(entry *Entry) {
 	var (
 		currentFrequency    = entry.frequencyParent
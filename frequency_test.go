@@ -0,0 +1,76 @@
+package gocache
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCache_FrequencyHistogram(t *testing.T) {
+	cache := NewCache(WithEvictionPolicy(LeastFrequentUsed))
+	cache.Set("1", "v")
+	cache.Set("2", "v")
+	cache.Set("3", "v")
+	cache.Get("2")
+	cache.Get("3")
+	cache.Get("3")
+	histogram := cache.FrequencyHistogram()
+	if len(histogram) != 3 {
+		t.Fatalf("expected 3 frequencies, got %v", histogram)
+	}
+	if histogram[1] != 1 || histogram[2] != 1 || histogram[3] != 1 {
+		t.Error("expected one entry with each frequency from 1 to 3, got", histogram)
+	}
+	cache.Get("1")
+	if histogram = cache.FrequencyHistogram(); histogram[1] != 0 || histogram[2] != 2 {
+		t.Error("expected two entries with a frequency of 2, got", histogram)
+	}
+	cache.Clear()
+	if histogram = cache.FrequencyHistogram(); len(histogram) != 0 {
+		t.Error("expected histogram to be empty after clearing the cache, got", histogram)
+	}
+}
+
+func TestCache_FrequencyHistogramWhenEvictionPolicyIsNotLFU(t *testing.T) {
+	cache := NewCache(WithEvictionPolicy(LeastRecentlyUsed))
+	cache.Set("1", "v")
+	if histogram := cache.FrequencyHistogram(); len(histogram) != 0 {
+		t.Error("expected histogram to be empty, got", histogram)
+	}
+	cache.RangeFrequencyBuckets(func(bucket FrequencyBucket) bool {
+		t.Error("expected fn not to be called")
+		return true
+	})
+}
+
+func TestCache_RangeFrequencyBuckets(t *testing.T) {
+	cache := NewCache(WithEvictionPolicy(LeastFrequentUsed))
+	cache.Set("1", "v")
+	cache.Set("2", "v")
+	cache.Set("3", "v")
+	cache.Get("3")
+	var buckets []FrequencyBucket
+	cache.RangeFrequencyBuckets(func(bucket FrequencyBucket) bool {
+		// Calling the cache from fn must not deadlock
+		cache.Count()
+		buckets = append(buckets, bucket)
+		return true
+	})
+	if len(buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(buckets))
+	}
+	sort.Strings(buckets[0].Keys)
+	if buckets[0].Frequency != 1 || len(buckets[0].Keys) != 2 || buckets[0].Keys[0] != "1" || buckets[0].Keys[1] != "2" {
+		t.Error("expected first bucket to have a frequency of 1 and contain keys 1 and 2, got", buckets[0])
+	}
+	if buckets[1].Frequency != 2 || len(buckets[1].Keys) != 1 || buckets[1].Keys[0] != "3" {
+		t.Error("expected second bucket to have a frequency of 2 and contain key 3, got", buckets[1])
+	}
+	calls := 0
+	cache.RangeFrequencyBuckets(func(bucket FrequencyBucket) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Error("expected iteration to stop after fn returned false")
+	}
+}
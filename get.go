@@ -1,38 +1,179 @@
 package gocache
 
+import (
+	"context"
+	"time"
+)
+
 // Get retrieves an entry using the key passed as parameter
 // If there is no such entry, the value returned will be nil and the boolean will be false
 // If there is an entry, the value returned will be the value cached and the boolean will be true
 func (c *Cache) Get(key string) (interface{}, bool) {
+	return c.GetCtx(context.Background(), key)
+}
+
+// GetCtx is the same as Get, but the context passed as parameter is used to attach a request ID to the audit record
+// of the retrieval (see WithAuditLog)
+//
+// If the context was returned by WithBypass or WithForceRefresh, the cache is not looked up at all, and the value
+// returned is always nil and the boolean false.
+func (c *Cache) GetCtx(ctx context.Context, key string) (interface{}, bool) {
+	if bypassed(ctx) || forceRefreshed(ctx) {
+		return nil, false
+	}
+	if c.neverStored(key) {
+		c.mutex.Lock()
+		c.countMiss()
+		c.mutex.Unlock()
+		return nil, false
+	}
+	key = c.storageKey(key)
+	defer c.endOp(OpGet, key, c.startOp())
+	if c.hooks != nil {
+		c.hooks.BeforeGet(key)
+	}
 	c.mutex.Lock()
+	value, ok := c.lookup(key)
+	notify := false
+	if ok && c.refreshObserver != nil {
+		_, notify = c.needsRefresh(key)
+	}
+	c.mutex.Unlock()
+	if notify {
+		c.refreshObserver.OnNeedsRefresh(key, value)
+	}
+	c.afterGet(ctx, key, value, ok)
+	return value, ok
+}
+
+// afterGet invokes the AfterGet hook and records the retrieval in the audit log
+func (c *Cache) afterGet(ctx context.Context, key string, value interface{}, ok bool) {
+	if c.hooks != nil {
+		c.hooks.AfterGet(key, value, ok)
+	}
+	if c.audits(OpGet) {
+		record := AuditRecord{Key: key, Hit: &ok}
+		if ok {
+			record.Size = toBytes(value)
+		}
+		c.audit(ctx, OpGet, record)
+	}
+}
+
+// lookup retrieves an entry using the key passed as parameter, updating the statistics, deleting the entry if it has
+// expired and updating the position of the entry according to the eviction policy
+//
+// The caller must hold the lock.
+func (c *Cache) lookup(key string) (interface{}, bool) {
 	entry, ok := c.get(key)
 	if !ok {
-		c.stats.Misses++
-		c.mutex.Unlock()
+		c.countMiss()
+		if c.traced(key) {
+			c.trace(key, "get", "miss")
+		}
 		return nil, false
 	}
 	if entry.Expired() {
+		if !entry.expiredBeyond(c.staleGrace()) {
+			// The entry is retained so that GetOrRefresh may serve it if refreshing it fails
+			c.countMiss()
+			if c.traced(key) {
+				c.trace(key, "get", "miss", "expired")
+			}
+			return nil, false
+		}
+		if c.traced(key) {
+			c.trace(key, "get", "miss", "expired")
+		}
 		c.stats.ExpiredKeys++
 		c.delete(key)
-		c.mutex.Unlock()
+		c.onExpire(entry)
+		c.assertInvariants()
 		return nil, false
 	}
-	c.stats.Hits++
-	if c.evictionPolicy == LeastRecentlyUsed {
+	c.countHits(1)
+	entry.accessCount++
+	if c.traced(key) {
+		c.trace(key, "get", "hit")
+	}
+	// The value must be read while the lock is held, as the entry may be updated as soon as the lock is released
+	value := entry.Value
+	if c.evictionPolicy == LeastRecentlyUsed || c.evictionPolicy == MostRecentlyUsed {
 		entry.Accessed()
 		if c.head == entry {
-			c.mutex.Unlock()
-			return entry.Value, true
+			return value, true
 		}
 		// Because the eviction policy is LRU, we need to move the entry back to HEAD
 		c.moveExistingEntryToHead(entry)
+		if c.traced(key) {
+			c.trace(key, "reposition", "head")
+		}
 	}
 
 	if c.evictionPolicy == LeastFrequentUsed {
 		c.incrementEntryFrequency(entry)
 	}
-	c.mutex.Unlock()
-	return entry.Value, true
+	if c.evictionPolicy == LRUK {
+		c.recordAccess(entry)
+	}
+	if c.evictionPolicy == Sieve {
+		entry.visited = true
+	}
+	c.assertInvariants()
+	return value, true
+}
+
+// GetOrRefresh retrieves the value of a key, or calls refresh to retrieve a fresh value if the key doesn't exist or
+// has expired, in which case the value returned by refresh is cached with the TTL passed as parameter.
+// If the cache rejects the fresh value (see RejectWrites), the value is returned along with ErrCacheFull.
+//
+// If refresh returns an error and the key expired less than ServeStaleMax ago, the expired value is returned instead
+// of the error, and Statistics.StaleServes is incremented. Otherwise, the error returned by refresh is returned.
+// If WithFailureCaching is used, the error is cached, and refresh is not called again for the key until it expires.
+//
+// Note that concurrent calls for the same key that isn't in the cache will each call refresh.
+func (c *Cache) GetOrRefresh(key string, ttl time.Duration, refresh func(key string) (interface{}, error)) (interface{}, error) {
+	return c.GetOrRefreshCtx(context.Background(), key, ttl, refresh)
+}
+
+// GetOrRefreshCtx is the same as GetOrRefresh, but the context passed as parameter is used to attach a request ID to
+// the audit records (see WithAuditLog), and to control the use of the cache for this call only.
+//
+// If the context was returned by WithBypass, refresh is always called, the value it returns is not cached, and the
+// cached value is not served if it fails. If the context was returned by WithForceRefresh, refresh is always called
+// and the value it returns is cached, but if it fails, the cached value is still served as long as it hasn't expired
+// for longer than ServeStaleMax. Neither of them returns errors cached through WithFailureCaching.
+func (c *Cache) GetOrRefreshCtx(ctx context.Context, key string, ttl time.Duration, refresh func(key string) (interface{}, error)) (interface{}, error) {
+	if value, ok := c.GetCtx(ctx, key); ok {
+		return value, nil
+	}
+	if bypassed(ctx) {
+		loaded := c.LoadStarted()
+		defer loaded()
+		return refresh(key)
+	}
+	storageKey := c.storageKey(key)
+	var value interface{}
+	err := c.cachedFailure(storageKey)
+	if err == nil || forceRefreshed(ctx) {
+		loaded := c.LoadStarted()
+		value, err = refresh(key)
+		loaded()
+		c.recordRefresh(storageKey, err)
+	}
+	if err == nil {
+		return value, c.SetWithTTLCtx(ctx, key, value, ttl)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.get(storageKey)
+	if !ok || entry.expiredBeyond(c.staleGrace()) {
+		return nil, err
+	}
+	if entry.Expired() {
+		c.stats.StaleServes++
+	}
+	return entry.Value, nil
 }
 
 // GetValue retrieves an entry using the key passed as parameter
@@ -47,13 +188,100 @@ func (c *Cache) GetValue(key string) interface{} {
 // cache will return nil, meaning that there is no way of determining whether a key genuinely has the value nil, or
 // whether it doesn't exist in the cache using only this function.
 func (c *Cache) GetByKeys(keys []string) map[string]interface{} {
-	entries := make(map[string]interface{})
+	entries := make(map[string]interface{}, len(keys))
 	for _, key := range keys {
 		entries[key], _ = c.Get(key)
 	}
 	return entries
 }
 
+// Tier is the cache of a LayeredCache a value was retrieved from
+type Tier int
+
+const (
+	// NoTier is the Tier of the values that weren't retrieved through a LayeredCache, or that weren't found
+	NoTier Tier = iota
+
+	// TierL1 is the Tier of the values retrieved from the l1 cache of a LayeredCache
+	TierL1
+
+	// TierL2 is the Tier of the values retrieved from the l2 cache of a LayeredCache
+	TierL2
+)
+
+// GetResult is the result of the retrieval of a key, as returned by GetManyDetailed
+type GetResult struct {
+	// Value is the value cached, or nil if the key wasn't found
+	Value interface{}
+
+	// Found is whether the key was found
+	Found bool
+
+	// ExpiresAt is the time at which the entry will expire, or the zero time if it never expires or wasn't found
+	ExpiresAt time.Time
+
+	// Stale is whether the soft TTL of the entry has passed, meaning that it needs a refresh (see SetWithSoftHardTTL)
+	Stale bool
+
+	// Tier is the cache of a LayeredCache the value was retrieved from, or NoTier
+	Tier Tier
+}
+
+// GetManyDetailed retrieves multiple entries using the keys passed as parameter, just like GetByKeys, but along with
+// whether they were found, when they expire and whether they need a refresh, which gives callers full visibility on
+// the entries in a single call. Every key is returned in the map, regardless of whether it exists or not.
+//
+// Unlike GetByKeys, the lock is only acquired once for all keys, but the hooks are invoked and slow operations are
+// recorded for each key, just like Get does. The RefreshObserver, if any, is notified of the entries that need a
+// refresh, just like GetWithSoftTTL does.
+func (c *Cache) GetManyDetailed(keys []string) map[string]GetResult {
+	storageKeys := make([]string, len(keys))
+	neverStored := make([]bool, len(keys))
+	starts := make([]time.Time, len(keys))
+	for i, key := range keys {
+		if neverStored[i] = c.neverStored(key); neverStored[i] {
+			continue
+		}
+		storageKeys[i] = c.storageKey(key)
+		starts[i] = c.startOp()
+		if c.hooks != nil {
+			c.hooks.BeforeGet(storageKeys[i])
+		}
+	}
+	results := make([]GetResult, len(keys))
+	notify := make([]bool, len(keys))
+	c.mutex.Lock()
+	for i, key := range storageKeys {
+		if neverStored[i] {
+			c.countMiss()
+			continue
+		}
+		results[i].Value, results[i].Found = c.lookup(key)
+		if !results[i].Found {
+			continue
+		}
+		// The entry may have been evicted by the lookup of one of the previous keys
+		if entry, ok := c.get(key); ok && entry.Expiration != NoExpiration {
+			results[i].ExpiresAt = time.Unix(0, entry.Expiration)
+		}
+		results[i].Stale, notify[i] = c.needsRefresh(key)
+	}
+	c.mutex.Unlock()
+	detailed := make(map[string]GetResult, len(keys))
+	for i, key := range keys {
+		detailed[key] = results[i]
+		if neverStored[i] {
+			continue
+		}
+		if notify[i] && c.refreshObserver != nil {
+			c.refreshObserver.OnNeedsRefresh(storageKeys[i], results[i].Value)
+		}
+		c.afterGet(context.Background(), storageKeys[i], results[i].Value, results[i].Found)
+		c.endOp(OpGet, storageKeys[i], starts[i])
+	}
+	return detailed
+}
+
 // GetAll retrieves all cache entries
 //
 // If the eviction policy is LeastRecentlyUsed, note that unlike Get and GetByKeys, this does not update the last access
@@ -70,16 +298,66 @@ func (c *Cache) GetAll() map[string]interface{} {
 	c.mutex.Lock()
 	for key, entry := range c.entries {
 		if entry.Expired() {
-			c.delete(key)
+			if entry.expiredBeyond(c.staleGrace()) {
+				c.delete(key)
+				c.onExpire(entry)
+			}
 			continue
 		}
 		entries[key] = entry.Value
 	}
-	c.stats.Hits += uint64(len(entries))
+	c.countHits(uint64(len(entries)))
+	c.assertInvariants()
 	c.mutex.Unlock()
 	return entries
 }
 
+// EntryInfo is a snapshot of a cache entry and of its metadata, as returned by GetAllEntries
+type EntryInfo struct {
+	// Value is the value of the cache entry
+	Value interface{}
+
+	// CreatedAt is the time at which the entry was created
+	CreatedAt time.Time
+
+	// UpdatedAt is the time at which the value of the entry was last set
+	UpdatedAt time.Time
+
+	// ExpiresAt is the time at which the entry will expire, or the zero time if it never expires
+	ExpiresAt time.Time
+
+	// AccessCount is the number of times the entry was retrieved through Get and similar functions
+	AccessCount uint64
+}
+
+// GetAllEntries retrieves all cache entries along with their metadata in a single pass
+//
+// Unlike GetAll, this is meant for introspection (e.g. admin dashboards): it neither counts as accessing the entries
+// nor updates the statistics, and expired entries are skipped without being deleted.
+//
+// You should probably avoid using this if you have a lot of entries.
+func (c *Cache) GetAllEntries() map[string]EntryInfo {
+	c.mutex.RLock()
+	entries := make(map[string]EntryInfo, len(c.entries))
+	for key, entry := range c.entries {
+		if entry.Expired() {
+			continue
+		}
+		info := EntryInfo{
+			Value:       entry.Value,
+			CreatedAt:   time.Unix(0, entry.createdAt),
+			UpdatedAt:   time.Unix(0, entry.updatedAt),
+			AccessCount: entry.accessCount,
+		}
+		if entry.Expiration != NoExpiration {
+			info.ExpiresAt = time.Unix(0, entry.Expiration)
+		}
+		entries[key] = info
+	}
+	c.mutex.RUnlock()
+	return entries
+}
+
 // GetKeysByPattern retrieves a slice of keys that match a given pattern
 // If the limit is set to 0, the entire cache will be searched for matching keys.
 // If the limit is above 0, the search will stop once the specified number of matching keys have been found.
@@ -109,6 +387,37 @@ func (c *Cache) GetKeysByPattern(pattern string, limit int) []string {
 	return matchingKeys
 }
 
+// GetByPattern retrieves the entries whose key matches a given pattern, along with their values
+// If the limit is set to 0, the entire cache will be searched for matching entries.
+// If the limit is above 0, the search will stop once the specified number of matching entries have been found.
+//
+// This is the same as calling GetKeysByPattern followed by GetByKeys, but the lock is only acquired once.
+// Like GetAll, the entries retrieved count as hits, but it does not update their last access timestamp (if LRU).
+func (c *Cache) GetByPattern(pattern string, limit int) map[string]interface{} {
+	entries := make(map[string]interface{})
+	c.mutex.Lock()
+	for key, entry := range c.entries {
+		if !MatchPattern(pattern, key) {
+			continue
+		}
+		if entry.Expired() {
+			if entry.expiredBeyond(c.staleGrace()) {
+				c.delete(key)
+				c.onExpire(entry)
+			}
+			continue
+		}
+		entries[key] = entry.Value
+		if limit > 0 && len(entries) >= limit {
+			break
+		}
+	}
+	c.countHits(uint64(len(entries)))
+	c.assertInvariants()
+	c.mutex.Unlock()
+	return entries
+}
+
 // get retrieves an entry using the key passed as parameter, but unlike Get, it doesn't update the access time or
 // move the position of the entry to the head
 func (c *Cache) get(key string) (*Entry, bool) {
@@ -1,6 +1,8 @@
 package gocache
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -36,6 +38,68 @@ func TestCache_GetEntryThatHasNotExpiredYet(t *testing.T) {
 	}
 }
 
+func TestCache_GetOrRefresh(t *testing.T) {
+	cache := NewCache()
+	calls := 0
+	refresh := func(key string) (interface{}, error) {
+		calls++
+		return key + "-value", nil
+	}
+	for i := 0; i < 2; i++ {
+		value, err := cache.GetOrRefresh("key", time.Hour, refresh)
+		if err != nil || value != "key-value" {
+			t.Errorf("expected key-value, got %v and %v", value, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected refresh to have been called once, got %d", calls)
+	}
+}
+
+func TestCache_GetOrRefreshWhenRefreshFails(t *testing.T) {
+	errRefresh := errors.New("refresh failed")
+	refresh := func(key string) (interface{}, error) {
+		return nil, errRefresh
+	}
+	cache := NewCache(WithServeStaleMax(time.Hour))
+	if _, err := cache.GetOrRefresh("missing", time.Hour, refresh); err != errRefresh {
+		t.Error("expected the error returned by refresh, got", err)
+	}
+	cache.SetWithTTL("key", "stale", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected Get not to return the stale value")
+	}
+	value, err := cache.GetOrRefresh("key", time.Hour, refresh)
+	if err != nil || value != "stale" {
+		t.Errorf("expected the stale value to be served, got %v and %v", value, err)
+	}
+	if cache.Stats().StaleServes != 1 {
+		t.Errorf("expected 1 stale serve, got %d", cache.Stats().StaleServes)
+	}
+	value, err = cache.GetOrRefresh("key", time.Hour, func(key string) (interface{}, error) {
+		return "fresh", nil
+	})
+	if err != nil || value != "fresh" {
+		t.Errorf("expected the fresh value once the refresh succeeds, got %v and %v", value, err)
+	}
+}
+
+func TestCache_GetOrRefreshWhenStaleValueIsTooOld(t *testing.T) {
+	cache := NewCache(WithServeStaleMax(time.Millisecond))
+	cache.SetWithTTL("key", "stale", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	errRefresh := errors.New("refresh failed")
+	if _, err := cache.GetOrRefresh("key", time.Hour, func(key string) (interface{}, error) {
+		return nil, errRefresh
+	}); err != errRefresh {
+		t.Error("expected the error returned by refresh, got", err)
+	}
+	if cache.Count() != 0 {
+		t.Error("expected the stale entry to have been deleted")
+	}
+}
+
 func TestCache_GetValue(t *testing.T) {
 	cache := NewCache(WithMaxSize(10))
 	cache.Set("key", "value")
@@ -64,6 +128,70 @@ func TestCache_GetByKeys(t *testing.T) {
 	}
 }
 
+func TestCache_GetByKeysWithLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache(WithMaxSize(3), WithEvictionPolicy(LeastRecentlyUsed), WithRaceAssertions(true))
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+	cache.GetByKeys([]string{"key1", "key2", "missing"})
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d and %d", stats.Hits, stats.Misses)
+	}
+	// key3 is the only key that wasn't accessed, so it must be the next to be evicted
+	cache.Set("key4", "value4")
+	if _, ok := cache.Get("key3"); ok {
+		t.Error("expected key3 to have been evicted")
+	}
+}
+
+func TestCache_GetManyDetailed(t *testing.T) {
+	hooks := &refreshRecordingHooks{}
+	cache := NewCache(WithHooks(hooks))
+	cache.Set("forever", "value1")
+	cache.SetWithTTL("expiring", "value2", time.Hour)
+	cache.SetWithSoftHardTTL("stale", "value3", time.Nanosecond, time.Hour)
+	time.Sleep(time.Millisecond)
+	results := cache.GetManyDetailed([]string{"forever", "expiring", "stale", "missing"})
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if result := results["forever"]; !result.Found || result.Value != "value1" || !result.ExpiresAt.IsZero() || result.Stale {
+		t.Errorf("expected forever to have been found without expiration, got %+v", result)
+	}
+	if result := results["expiring"]; !result.Found || time.Until(result.ExpiresAt) <= 59*time.Minute || result.Stale {
+		t.Errorf("expected expiring to expire in an hour, got %+v", result)
+	}
+	if result := results["stale"]; !result.Found || result.Value != "value3" || !result.Stale || result.Tier != NoTier {
+		t.Errorf("expected stale to need a refresh, got %+v", result)
+	}
+	if result := results["missing"]; result.Found || result.Value != nil {
+		t.Errorf("expected missing not to have been found, got %+v", result)
+	}
+	if len(hooks.keys) != 1 || hooks.keys[0] != "stale" {
+		t.Errorf("expected the observer to have been notified of stale, got %v", hooks.keys)
+	}
+	if stats := cache.Stats(); stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("expected 3 hits and 1 miss, got %d and %d", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCache_GetManyDetailedWithKeysThatAreNeverStored(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithHooks(hooks), WithNeverCachePatterns("session:*"), WithMaxKeyLength(10))
+	cache.Set("key", "value")
+	long := strings.Repeat("a", 11)
+	results := cache.GetManyDetailed([]string{"key", "session:1", long})
+	if len(results) != 3 || !results["key"].Found || results["session:1"].Found || results[long].Found {
+		t.Errorf("expected only key to have been found, got %+v", results)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("expected 1 hit and 2 misses, got %d and %d", stats.Hits, stats.Misses)
+	}
+	if len(hooks.events) != 4 || hooks.events[2] != "BeforeGet key" || hooks.events[3] != "AfterGet key=value found=true" {
+		t.Errorf("expected the hooks to have only been invoked for key, got %v", hooks.events)
+	}
+}
+
 func TestCache_GetAll(t *testing.T) {
 	cache := NewCache(WithMaxSize(10))
 	cache.Set("key1", "value1")
@@ -98,6 +226,38 @@ func TestCache_GetAllWhenOneKeyIsExpired(t *testing.T) {
 	}
 }
 
+func TestCache_GetAllEntries(t *testing.T) {
+	cache := NewCache()
+	before := time.Now()
+	cache.Set("key1", "value1")
+	cache.SetWithTTL("key2", "value2", time.Hour)
+	cache.SetWithTTL("key3", "value3", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	cache.Set("key1", "updated")
+	cache.Get("key1")
+	cache.Get("key1")
+	entries := cache.GetAllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	key1 := entries["key1"]
+	if key1.Value != "updated" || key1.AccessCount != 2 {
+		t.Errorf("expected key1 to have value updated and 2 accesses, got %v and %d", key1.Value, key1.AccessCount)
+	}
+	if key1.CreatedAt.Before(before) || !key1.UpdatedAt.After(key1.CreatedAt) {
+		t.Errorf("expected key1 to have been updated after its creation, got %s and %s", key1.CreatedAt, key1.UpdatedAt)
+	}
+	if !key1.ExpiresAt.IsZero() {
+		t.Error("expected key1 not to have an expiration time, got", key1.ExpiresAt)
+	}
+	if until := time.Until(entries["key2"].ExpiresAt); until < 59*time.Minute || until > time.Hour {
+		t.Error("expected key2 to expire in about an hour, got", until)
+	}
+	if cache.Count() != 3 || cache.Stats().Hits != 2 {
+		t.Error("expected GetAllEntries not to delete expired entries nor to update the statistics")
+	}
+}
+
 func TestCache_GetKeysByPattern(t *testing.T) {
 	// All keys match
 	testGetKeysByPattern(t, []string{"key1", "key2", "key3", "key4"}, "key*", 0, 4)
@@ -130,6 +290,28 @@ func testGetKeysByPattern(t *testing.T, keys []string, pattern string, limit, ex
 	}
 }
 
+func TestCache_GetByPattern(t *testing.T) {
+	cache := NewCache(WithRaceAssertions(true))
+	cache.Set("user:1", "john")
+	cache.Set("user:2", "jane")
+	cache.Set("product:1", "book")
+	cache.SetWithTTL("user:3", "jim", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	entries := cache.GetByPattern("user:*", 0)
+	if len(entries) != 2 || entries["user:1"] != "john" || entries["user:2"] != "jane" {
+		t.Errorf("expected user:1 and user:2, got %v", entries)
+	}
+	if cache.Count() != 3 {
+		t.Errorf("expected the expired entry to have been deleted, got %d entries", cache.Count())
+	}
+	if entries := cache.GetByPattern("*", 2); len(entries) != 2 {
+		t.Errorf("expected the limit of 2 to be respected, got %d entries", len(entries))
+	}
+	if hits := cache.Stats().Hits; hits != 4 {
+		t.Errorf("expected 4 hits, got %d", hits)
+	}
+}
+
 func TestCache_GetKeysByPatternWithExpiredKey(t *testing.T) {
 	cache := NewCache(WithMaxSize(10))
 	cache.SetWithTTL("key", "value", 10*time.Millisecond)
@@ -4,6 +4,7 @@ import (
 	"container/list"
 	"errors"
 	"sync"
+	"time"
 )
 
 var (
@@ -30,13 +31,27 @@ const (
 )
 
 var (
-	ErrKeyDoesNotExist       = errors.New("key does not exist")         // Returned when a c key does not exist
-	ErrKeyHasNoExpiration    = errors.New("key has no expiration")      // Returned when a c key has no expiration
-	ErrJanitorAlreadyRunning = errors.New("janitor is already running") // Returned when the janitor has already been started
+	ErrKeyDoesNotExist         = errors.New("key does not exist")           // Returned when a c key does not exist
+	ErrKeyHasNoExpiration      = errors.New("key has no expiration")        // Returned when a c key has no expiration
+	ErrJanitorAlreadyRunning   = errors.New("janitor is already running")   // Returned when the janitor has already been started
+	ErrCacheFull               = errors.New("cache is full")                // Returned when a write is rejected because the cache is full
+	ErrReclaimerAlreadyRunning = errors.New("reclaimer is already running") // Returned when the reclaimer has already been started
+	ErrCacheAlreadyRegistered  = errors.New("cache is already registered")  // Returned when a cache is already registered under the same name
+	ErrRefresherAlreadyRunning = errors.New("refresher is already running") // Returned when the refresher has already been started
+	ErrDraining                = errors.New("cache is draining")            // Returned when a write is rejected because the cache is draining
+	ErrLockTimeout             = errors.New("lock timeout")                 // Returned when the lock could not be acquired in time by TryGet or TrySet
+	ErrKeyTooLong              = errors.New("key is too long")              // Returned when a write is rejected because its key is longer than the MaxKeyLength
 )
 
 // Cache is the core struct of gocache which contains the data as well as all relevant configuration fields
+//
+// The zero value is an empty cache ready to use, with no MaxSize, no MaxMemoryUsage and the FirstInFirstOut eviction
+// policy. Unlike the caches created by NewCache, it stores nil pointers as they are (see
+// WithForceNilInterfaceOnNilPointer). A Cache must not be copied after first use.
 type Cache struct {
+	// name is the name of the cache, which the background goroutines of the cache are labeled with in profiles
+	name string
+
 	// maxSize is the maximum amount of entries that can be in the c at any given time
 	// By default, this is set to DefaultMaxSize
 	maxSize int
@@ -46,15 +61,55 @@ type Cache struct {
 	// based on maximum memory usage
 	maxMemoryUsage int
 
+	// memoryLowWatermark is the memoryUsage down to which entries are evicted once the memoryUsage exceeds the
+	// maxMemoryUsage
+	// By default, this is 0, meaning that entries are only evicted until the memoryUsage no longer exceeds the
+	// maxMemoryUsage
+	memoryLowWatermark int
+
 	// evictionPolicy is the eviction policy
 	evictionPolicy EvictionPolicy
 
+	// evictionPacing is the maximum number of entries evicted inline by a single write when the memoryUsage exceeds
+	// the maxMemoryUsage, the rest being evicted in the background
+	// By default, this is 0, meaning that every eviction happens inline
+	evictionPacing int
+
+	// reclaiming is whether a background goroutine is evicting the entries that paced writes did not evict
+	reclaiming bool
+
+	// fullBehavior determines whether writes to a full cache evict existing entries or are rejected
+	// By default, this is set to EvictTail
+	fullBehavior FullBehavior
+
 	// stats is the object that contains c statistics/metrics
-	stats *Statistics
+	stats Statistics
 
 	// entries is the content of the c
 	entries map[string]*Entry
 
+	// tags indexes the keys of the entries whose value is a Tagger by tag
+	// It is only allocated once the first Tagger is written
+	tags map[string]map[string]struct{}
+
+	// setSubscriptions are the callbacks registered through OnSetPattern
+	setSubscriptions []setSubscription
+
+	// hookWorkers runs the callbacks registered through OnSetPattern
+	hookWorkers hookWorkerPool
+
+	// tombstones contains the entries deleted through SoftDelete until their purge time
+	// It is only allocated once the first entry is soft deleted
+	tombstones map[string]*DeletedEntry
+
+	// initialCapacity is the number of entries that the cache pre-allocates room for
+	// By default, this is 0, meaning that nothing is pre-allocated unless a maxSize is set
+	initialCapacity int
+
+	// preallocatedEntries is a slab of entries handed out by newEntry until it is exhausted, so that the
+	// first initialCapacity entries created do not each require their own allocation
+	preallocatedEntries []Entry
+
 	// mutex is the lock for making concurrent operations on the c
 	mutex sync.RWMutex
 
@@ -67,9 +122,120 @@ type Cache struct {
 	// freqs is used to count how frequent is the entry used
 	freqs *list.List
 
+	// k is the number of accesses tracked per entry by the LRUK eviction policy
+	k int
+
+	// lruk is the heap of entries ordered by their K-th most recent access, only used by the LRUK eviction policy
+	lruk lrukHeap
+
+	// preciseExpiration is whether expired entries are deleted within expirationEpsilon of their expiration
+	preciseExpiration bool
+
+	// expirationEpsilon is how long after their expiration entries are deleted if preciseExpiration is enabled
+	expirationEpsilon time.Duration
+
+	// ttlBucketGranularity is the granularity the expiration times are rounded up to, or 0 if they aren't rounded
+	ttlBucketGranularity time.Duration
+
+	// expirationWheel contains the entries that expire within each slot of expirationEpsilon, or of
+	// ttlBucketGranularity if it is set, by slot, if preciseExpiration is enabled or ttlBucketGranularity is set
+	expirationWheel map[int64]*expirationSlot
+
+	// expirationSlots is the heap of the slots of the expirationWheel, so that the background goroutine deleting their
+	// entries can sleep until the next one has passed
+	expirationSlots expirationSlotHeap
+
+	// lastExpiredSlot is the last slot of the expirationWheel whose entries were deleted
+	lastExpiredSlot int64
+
+	// stopExpiring is closed to stop the background goroutine deleting the entries of the expirationWheel, or nil if
+	// it isn't running
+	stopExpiring chan struct{}
+
+	// wakeExpiring wakes the background goroutine deleting the entries of the expirationWheel up when the earliest
+	// slot changes, or is nil if it isn't running
+	wakeExpiring chan struct{}
+
+	// checksums is whether a checksum is persisted along with each value and verified when it is loaded
+	checksums bool
+
+	// corruptionObserver is the Hooks passed to WithHooks, if it implements CorruptionObserver
+	corruptionObserver CorruptionObserver
+
+	// refreshObserver is the Hooks passed to WithHooks, if it implements RefreshObserver
+	refreshObserver RefreshObserver
+
+	// failureBaseTTL is how long the first error returned by the refresh function of GetOrRefresh is cached for, or 0
+	// if errors are not cached
+	failureBaseTTL time.Duration
+
+	// failureMaxTTL is the maximum duration errors returned by the refresh function of GetOrRefresh are cached for
+	failureMaxTTL time.Duration
+
+	// failures are the cached errors returned by the refresh function of GetOrRefresh, by key
+	failures map[string]*failure
+
+	// neverCachePatterns are the patterns of the keys that are never cached (see WithNeverCachePatterns)
+	neverCachePatterns []string
+
+	// admissionFilter decides whether new entries are cached, or nil if every entry is (see WithAdmissionFilter)
+	admissionFilter func(key string, value interface{}, size int) bool
+
+	// identical decides whether a write is skipped because the value is identical to the one already cached, or nil if
+	// no write is (see WithSkipIdenticalWrites)
+	identical func(current, value interface{}) bool
+
+	// maxKeyLength is the maximum length of the keys written to the cache, or NoMaxKeyLength (see WithMaxKeyLength)
+	maxKeyLength int
+
+	// lockTimeout is how long TryGet and TrySet wait for the lock before giving up (see WithLockTimeout)
+	lockTimeout time.Duration
+
+	// draining is whether Drain was called, in which case writes are rejected
+	draining bool
+
+	// listMaxLength is the maximum number of elements of the lists created through LPush and RPush, or 0 if they
+	// are unbounded
+	listMaxLength int
+
+	// migrations are the functions upgrading values from each schema version to the next (see WithMigrations)
+	migrations map[int]MigrateFunc
+
+	// schemaVersion is the schema version written along with the entries persisted by the cache, which is one more
+	// than the highest version a migration is registered for
+	schemaVersion int
+
+	// sampleEntries contains every entry of the cache in no particular order, so that SampleKeys can pick entries
+	// at random without iterating over the entire cache
+	sampleEntries []*Entry
+
+	// scanBuckets contains the keys of the entries of the cache, by the bucket derived from the hash of their key
+	// that Scan iterates over. The buckets are only allocated once an entry is added to the cache, and each bucket
+	// once an entry is added to it
+	scanBuckets []map[string]struct{}
+
+	// lrukClock is the logical clock used to order the accesses tracked by the LRUK eviction policy
+	lrukClock uint64
+
+	// sieveHand is the next entry considered for eviction by the Sieve eviction policy, or nil to start from the tail
+	sieveHand *Entry
+
 	// stopJanitor is the channel used to stop the janitor
 	stopJanitor chan bool
 
+	// stopReclaimer is the channel used to stop the reclaimer
+	stopReclaimer chan bool
+
+	// wakeReclaimer is the channel used by writes to notify the reclaimer that the memoryUsage is above the
+	// reclaimerWatermark
+	wakeReclaimer chan struct{}
+
+	// stopRefresher is the channel used to stop the refresher
+	stopRefresher chan bool
+
+	// reclaimerWatermark is the memoryUsage above which the reclaimer evicts entries
+	reclaimerWatermark int
+
 	// memoryUsage is the approximate memory usage of the c (dataset only) in bytes
 	memoryUsage int
 
@@ -81,6 +247,72 @@ type Cache struct {
 	// will still show as nil, which means that if you don't cast the interface after
 	// retrieving it, a nil check will return that the value is not false.
 	forceNilInterfaceOnNilPointer bool
+
+	// serveStaleMax is how long after their expiration entries are retained so that GetOrRefresh can serve them
+	// when refreshing them fails
+	// By default, this is 0, meaning that expired values are never served
+	serveStaleMax time.Duration
+
+	// statsSamplingRate is the fraction of hits and misses that are counted in the statistics
+	// By default, this is 1, meaning that every hit and miss is counted, which is also what 0 means for the zero
+	// value of Cache
+	statsSamplingRate float64
+
+	// usageAlertThreshold is the fraction of the MaxSize or MaxMemoryUsage above which usageAlert is called
+	usageAlertThreshold float64
+
+	// usageAlert is the function called when the usage crosses usageAlertThreshold, or nil (see WithUsageAlert)
+	usageAlert func(report UsageReport)
+
+	// lastUsageAlert is the unix time in nanoseconds at which usageAlert was last called, or 0 if it never was
+	lastUsageAlert int64
+
+	// traces are the destinations of the events of the keys traced through TraceKey, by key, or nil if no key is
+	traces map[string][]*keyTrace
+
+	// slowOpThreshold is the duration above which operations are recorded as slow, or 0 if they are not recorded
+	slowOpThreshold time.Duration
+
+	// slowOpStacks is whether the stack trace of slow operations is recorded along with them
+	slowOpStacks bool
+
+	// slowOpsMutex protects slowOps and slowOpsRecorded, so that slow operations are recorded without the lock
+	slowOpsMutex sync.Mutex
+
+	// slowOps is a ring buffer of the last SlowOpCapacity slow operations
+	slowOps []SlowOp
+
+	// slowOpsRecorded is the number of slow operations recorded since the cache was created or ResetSlowOps was called
+	slowOpsRecorded uint64
+
+	// loadLatencies is a ring buffer of the durations of the last LoadLatencySamples loads
+	loadLatencies []time.Duration
+
+	// random is the source of randomness set through WithRandSource, or nil to use the global source of math/rand
+	random *lockedRand
+
+	// statsSampler is the state of the pseudo-random number generator deciding which hits and misses are counted
+	statsSampler uint64
+
+	// keyObfuscation is the key of the HMAC that keys are stored as, if any (see WithKeyObfuscation)
+	keyObfuscation []byte
+
+	// auditLog is where the operations are recorded, if any
+	auditLog *auditLog
+
+	// hooks are the callbacks invoked by the cache, if any
+	hooks Hooks
+
+	// evictionVetoer is the hooks, if they implement EvictionVetoer
+	evictionVetoer EvictionVetoer
+
+	// raceAssertions determines whether the cache's internal invariants are verified after every mutation
+	raceAssertions bool
+}
+
+// Name returns the name of the cache
+func (c *Cache) Name() string {
+	return c.name
 }
 
 // MaxSize returns the maximum amount of keys that can be present in the cache before
@@ -89,17 +321,55 @@ func (c *Cache) MaxSize() int {
 	return c.maxSize
 }
 
+// InitialCapacity returns the number of entries the cache pre-allocated room for
+func (c *Cache) InitialCapacity() int {
+	return c.initialCapacity
+}
+
 // MaxMemoryUsage returns the configured maxMemoryUsage of the cache
 func (c *Cache) MaxMemoryUsage() int {
 	return c.maxMemoryUsage
 }
 
+// MemoryLowWatermark returns the memory usage down to which entries are evicted once the MaxMemoryUsage is exceeded,
+// or 0 if entries are only evicted until the memory usage no longer exceeds the MaxMemoryUsage
+func (c *Cache) MemoryLowWatermark() int {
+	return c.memoryLowWatermark
+}
+
 // EvictionPolicy returns the EvictionPolicy of the Cache
 func (c *Cache) EvictionPolicy() EvictionPolicy {
 	return c.evictionPolicy
 }
 
+// EvictionPacing returns the maximum number of entries evicted inline by a single write
+func (c *Cache) EvictionPacing() int {
+	return c.evictionPacing
+}
+
+// FullBehavior returns the FullBehavior of the Cache
+func (c *Cache) FullBehavior() FullBehavior {
+	return c.fullBehavior
+}
+
+// ServeStaleMax returns how long after their expiration entries may be served by GetOrRefresh
+func (c *Cache) ServeStaleMax() time.Duration {
+	return c.serveStaleMax
+}
+
+// StatsSampling returns the fraction of hits and misses that are counted in the statistics
+func (c *Cache) StatsSampling() float64 {
+	if c.statsSamplingRate <= 0 {
+		// Only the zero value of Cache has no rate, and it counts every hit and miss
+		return 1
+	}
+	return c.statsSamplingRate
+}
+
 // Stats returns statistics from the cache
+//
+// If WithStatsSampling is used, Hits and Misses are extrapolated from the hits and misses that were counted, and are
+// therefore approximate.
 func (c *Cache) Stats() Statistics {
 	c.mutex.RLock()
 	stats := Statistics{
@@ -107,6 +377,27 @@ func (c *Cache) Stats() Statistics {
 		ExpiredKeys: c.stats.ExpiredKeys,
 		Hits:        c.stats.Hits,
 		Misses:      c.stats.Misses,
+		StaleServes: c.stats.StaleServes,
+
+		CorruptedEntries: c.stats.CorruptedEntries,
+
+		NotAdmitted: c.stats.NotAdmitted,
+		LongKeys:    c.stats.LongKeys,
+
+		IdenticalWrites: c.stats.IdenticalWrites,
+		NetworkErrors:   c.stats.NetworkErrors,
+
+		FirstSeen:  c.stats.FirstSeen,
+		Duplicates: c.stats.Duplicates,
+
+		Loads:          c.stats.Loads,
+		CoalescedLoads: c.stats.CoalescedLoads,
+		LoadsInFlight:  c.stats.LoadsInFlight,
+	}
+	stats.LoadLatencyP50, stats.LoadLatencyP90, stats.LoadLatencyP99 = c.loadLatencyPercentiles()
+	if c.statsSamplingRate > 0 && c.statsSamplingRate < 1 {
+		stats.Hits = uint64(float64(stats.Hits)/c.statsSamplingRate + 0.5)
+		stats.Misses = uint64(float64(stats.Misses)/c.statsSamplingRate + 0.5)
 	}
 	c.mutex.RUnlock()
 	return stats
@@ -132,6 +423,23 @@ func WithMaxMemoryUsage(maxMemoryUsageInBytes int) func(c *Cache) {
 	}
 }
 
+// WithMemoryWatermarks sets the MaxMemoryUsage to the high watermark, and makes the cache evict entries until the memory
+// usage is no longer above the low watermark whenever the high watermark is exceeded, rather than stopping as soon as
+// the memory usage is back under the MaxMemoryUsage. This frees enough room for many writes at once, which avoids
+// evicting entries on nearly every write when the memory usage hovers around the MaxMemoryUsage.
+//
+// A low watermark of 0 or less, or one that isn't below the high watermark, means that entries are only evicted until
+// the memory usage no longer exceeds the high watermark, which is the default behavior of WithMaxMemoryUsage.
+func WithMemoryWatermarks(high, low int) func(c *Cache) {
+	return func(c *Cache) {
+		WithMaxMemoryUsage(high)(c)
+		if low < 0 || low >= c.maxMemoryUsage {
+			low = 0
+		}
+		c.memoryLowWatermark = low
+	}
+}
+
 // WithMaxSize sets the maximum amount of entries that can be in the cache at any given time
 // A maxSize of 0 or less means infinite
 func WithMaxSize(maxSize int) func(c *Cache) {
@@ -139,13 +447,31 @@ func WithMaxSize(maxSize int) func(c *Cache) {
 		if maxSize < 0 {
 			maxSize = NoMaxSize
 		}
-		if maxSize != NoMaxSize && c.Count() == 0 {
+		if maxSize != NoMaxSize && maxSize > c.initialCapacity && c.Count() == 0 {
 			c.entries = make(map[string]*Entry, maxSize)
 		}
 		c.maxSize = maxSize
 	}
 }
 
+// WithInitialCapacity pre-allocates room for the given number of entries, regardless of the maxSize.
+//
+// This is useful to avoid the cost of repeatedly growing the cache during warm-up, especially when the
+// maxSize is NoMaxSize, in which case nothing would otherwise be pre-allocated.
+// A capacity of 0 or less means nothing is pre-allocated.
+func WithInitialCapacity(capacity int) func(c *Cache) {
+	return func(c *Cache) {
+		if capacity < 0 {
+			capacity = 0
+		}
+		c.initialCapacity = capacity
+		if c.Count() == 0 {
+			c.entries = make(map[string]*Entry, capacity)
+			c.preallocatedEntries = make([]Entry, capacity)
+		}
+	}
+}
+
 // WithEvictionPolicy sets eviction algorithm.
 // Defaults to FirstInFirstOut (FIFO)
 func WithEvictionPolicy(policy EvictionPolicy) func(c *Cache) {
@@ -157,6 +483,73 @@ func WithEvictionPolicy(policy EvictionPolicy) func(c *Cache) {
 	}
 }
 
+// WithEvictionPacing caps the number of entries a single write may evict inline when it pushes the memory usage above
+// the MaxMemoryUsage, for instance because a large value was inserted. The remaining evictions are done by a
+// background goroutine that releases the lock between batches, which smooths out the latency of writes.
+//
+// As a result, the memory usage may briefly exceed MaxMemoryUsage.
+// A maxPerOp of 0 or less means that every eviction happens inline, which is the default.
+func WithEvictionPacing(maxPerOp int) func(c *Cache) {
+	return func(c *Cache) {
+		if maxPerOp < 0 {
+			maxPerOp = 0
+		}
+		c.evictionPacing = maxPerOp
+	}
+}
+
+// WithFullBehavior sets what happens when a write would push the cache above its MaxSize or MaxMemoryUsage.
+// Defaults to EvictTail
+//
+// If set to RejectWrites, Set-like functions will return ErrCacheFull rather than evict existing entries.
+func WithFullBehavior(fullBehavior FullBehavior) func(c *Cache) {
+	return func(c *Cache) {
+		c.fullBehavior = fullBehavior
+	}
+}
+
+// WithServeStaleMax sets how long after its expiration an entry may still be returned by GetOrRefresh if refreshing
+// it fails, similarly to how DNS resolvers serve stale answers when the upstream is unreachable.
+//
+// Expired entries remain invisible to Get and similar functions, but they are only deleted once they have been
+// expired for longer than serveStaleMax, so they still count towards MaxSize and MaxMemoryUsage until then.
+// Defaults to 0, meaning that expired entries are never served
+func WithServeStaleMax(serveStaleMax time.Duration) func(c *Cache) {
+	return func(c *Cache) {
+		if serveStaleMax < 0 {
+			serveStaleMax = 0
+		}
+		c.serveStaleMax = serveStaleMax
+	}
+}
+
+// WithName sets the name of the cache, which the goroutines of the janitor, the reclaimer and the other background
+// workers of the cache are labeled with (as the "gocache.cache" pprof label, alongside a "gocache.role" label), so that
+// the profiles of services with many caches attribute the CPU used by each cache correctly.
+// Defaults to an empty string
+func WithName(name string) func(c *Cache) {
+	return func(c *Cache) {
+		c.name = name
+	}
+}
+
+// WithStatsSampling sets the fraction of hits and misses that are counted in the statistics, between 0 (exclusive)
+// and 1. For instance, with a rate of 0.01, each hit and miss has a 1% chance of being counted, and Stats
+// extrapolates the number of hits and misses from the ones that were counted.
+//
+// This reduces the number of writes to the statistics of extremely hot caches, at the cost of their accuracy.
+// A rate that isn't between 0 (exclusive) and 1 is treated as 1.
+// Defaults to 1, meaning that every hit and miss is counted
+func WithStatsSampling(rate float64) func(c *Cache) {
+	return func(c *Cache) {
+		if rate <= 0 || rate > 1 {
+			rate = 1
+		}
+		c.statsSamplingRate = rate
+		c.statsSampler = c.seed()
+	}
+}
+
 // WithForceNilInterfaceOnNilPointer sets whether all Set-like functions should set a value as nil if the
 // interface passed has a nil value but not a nil type.
 //
@@ -205,7 +598,10 @@ func NewCache(opts ...func(*Cache)) *Cache {
 	c := &Cache{
 		maxSize:                       DefaultMaxSize,
 		evictionPolicy:                FirstInFirstOut,
-		stats:                         &Statistics{},
+		fullBehavior:                  EvictTail,
+		k:                             DefaultK,
+		statsSamplingRate:             1,
+		expirationEpsilon:             DefaultExpirationEpsilon,
 		entries:                       make(map[string]*Entry),
 		mutex:                         sync.RWMutex{},
 		stopJanitor:                   nil,
@@ -249,3 +249,9 @@ func BenchmarkCache_WithForceNilInterfaceOnNilPointerWithConcurrency(b *testing.
 		}
 	}
 }
+
+func BenchmarkKey(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		Key("user", n, "profile")
+	}
+}
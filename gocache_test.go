@@ -2,8 +2,10 @@ package gocache
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewCache(t *testing.T) {
@@ -47,6 +49,35 @@ func TestCache_Stats(t *testing.T) {
 	}
 }
 
+func TestCache_WithStatsSampling(t *testing.T) {
+	cache := NewCache(WithStatsSampling(0.1))
+	if cache.StatsSampling() != 0.1 {
+		t.Errorf("expected a sampling rate of 0.1, got %f", cache.StatsSampling())
+	}
+	cache.Set("key", "value")
+	for i := 0; i < 10000; i++ {
+		cache.Get("key")
+		cache.Get("missing")
+	}
+	// The statistics are extrapolated from roughly 1000 hits and 1000 misses, so they should be within 20% of the
+	// actual numbers
+	stats := cache.Stats()
+	if stats.Hits < 8000 || stats.Hits > 12000 {
+		t.Errorf("expected about 10000 hits, got %d", stats.Hits)
+	}
+	if stats.Misses < 8000 || stats.Misses > 12000 {
+		t.Errorf("expected about 10000 misses, got %d", stats.Misses)
+	}
+}
+
+func TestCache_WithStatsSamplingAndInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, 2} {
+		if cache := NewCache(WithStatsSampling(rate)); cache.StatsSampling() != 1 {
+			t.Errorf("expected a rate of %f to be treated as 1, got %f", rate, cache.StatsSampling())
+		}
+	}
+}
+
 func TestCache_WithMaxSize(t *testing.T) {
 	cache := NewCache(WithMaxSize(1234))
 	if cache.MaxSize() != 1234 {
@@ -61,6 +92,35 @@ func TestCache_WithMaxSizeAndNegativeValue(t *testing.T) {
 	}
 }
 
+func TestCache_WithInitialCapacity(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithInitialCapacity(3))
+	if cache.InitialCapacity() != 3 {
+		t.Error("expected cache to have an initial capacity of 3")
+	}
+	for i := 0; i < 5; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	if len(cache.preallocatedEntries) != 0 {
+		t.Error("expected all pre-allocated entries to have been used")
+	}
+	for i := 0; i < 5; i++ {
+		if value, ok := cache.Get(strconv.Itoa(i)); !ok || value != i {
+			t.Errorf("expected key %d to have value %d, got %v", i, i, value)
+		}
+	}
+	cache.Delete("1")
+	if cache.Count() != 4 || cache.head.Key != "4" || cache.tail.Key != "0" {
+		t.Error("expected entries taken from the pre-allocated slab to behave like any other entry")
+	}
+}
+
+func TestCache_WithInitialCapacityAndNegativeValue(t *testing.T) {
+	cache := NewCache(WithInitialCapacity(-10))
+	if cache.InitialCapacity() != 0 {
+		t.Error("expected cache to have no initial capacity")
+	}
+}
+
 func TestCache_WithMaxMemoryUsage(t *testing.T) {
 	const ValueSize = Kilobyte
 	cache := NewCache(WithMaxSize(0), WithMaxMemoryUsage(Kilobyte*64))
@@ -195,9 +255,9 @@ func TestCache_WithForceNilInterfaceOnNilPointer(t *testing.T) {
 
 func TestEvictionWhenThereIsNothingToEvict(t *testing.T) {
 	cache := NewCache()
-	cache.evict()
-	cache.evict()
-	cache.evict()
+	cache.evict(nil)
+	cache.evict(nil)
+	cache.evict(nil)
 }
 
 func TestCache(t *testing.T) {
@@ -235,3 +295,86 @@ func TestCache(t *testing.T) {
 		t.Error("expected 5 to exist")
 	}
 }
+
+// FuzzCacheOps drives a random sequence of operations through the cache and compares the result of each operation
+// with a map used as a model of what the cache should contain
+func FuzzCacheOps(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 1, 2, 0, 3, 1, 4, 2})
+	f.Add([]byte{1, 0, 0, 1, 0, 2, 0, 3, 1, 2, 2, 1, 3, 0, 5, 0})
+	f.Add([]byte{2, 0, 7, 0, 7, 1, 7, 1, 4, 1, 6, 3, 6, 3, 0, 3})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		if len(ops) == 0 {
+			return
+		}
+		evictionPolicy := EvictionPolicy(ops[0] % 3)
+		cache := NewCache(WithMaxSize(NoMaxSize), WithEvictionPolicy(evictionPolicy), WithRaceAssertions(true))
+		model := make(map[string]int)
+		// expired contains the keys that have expired, but that may not have been deleted from the cache yet
+		expired := make(map[string]bool)
+		for i := 1; i+1 < len(ops); i += 2 {
+			key := strconv.Itoa(int(ops[i+1] % 8))
+			switch ops[i] % 8 {
+			case 0:
+				cache.Set(key, i)
+				model[key] = i
+				delete(expired, key)
+			case 1:
+				cache.SetWithTTL(key, i, time.Hour)
+				model[key] = i
+				delete(expired, key)
+			case 2:
+				// A TTL of 0 deletes the key if it exists, and doesn't create it otherwise
+				cache.SetWithTTL(key, i, 0)
+				delete(model, key)
+				delete(expired, key)
+			case 3:
+				value, ok := cache.Get(key)
+				expectedValue, expectedOk := model[key]
+				if ok != expectedOk || (ok && value != expectedValue) {
+					t.Fatalf("op %d: Get(%s) returned (%v, %v), expected (%v, %v)", i, key, value, ok, expectedValue, expectedOk)
+				}
+				delete(expired, key)
+			case 4:
+				_, exists := model[key]
+				exists = exists || expired[key]
+				if deleted := cache.Delete(key); deleted != exists {
+					t.Fatalf("op %d: Delete(%s) returned %v, expected %v", i, key, deleted, exists)
+				}
+				delete(model, key)
+				delete(expired, key)
+			case 5:
+				// A negative TTL makes the key expire immediately
+				_, exists := model[key]
+				if altered := cache.Expire(key, -time.Second); altered != exists {
+					t.Fatalf("op %d: Expire(%s) returned %v, expected %v", i, key, altered, exists)
+				}
+				if exists {
+					delete(model, key)
+					expired[key] = true
+				}
+			case 6:
+				value, ok := cache.Take(key)
+				expectedValue, expectedOk := model[key]
+				if ok != expectedOk || (ok && value != expectedValue) {
+					t.Fatalf("op %d: Take(%s) returned (%v, %v), expected (%v, %v)", i, key, value, ok, expectedValue, expectedOk)
+				}
+				delete(model, key)
+				delete(expired, key)
+			case 7:
+				all := cache.GetAll()
+				if len(all) != len(model) {
+					t.Fatalf("op %d: GetAll returned %d entries, expected %d", i, len(all), len(model))
+				}
+				for k, v := range model {
+					if all[k] != v {
+						t.Fatalf("op %d: GetAll returned %v for key %s, expected %v", i, all[k], k, v)
+					}
+				}
+				expired = make(map[string]bool)
+			}
+			if cache.Count() != len(model)+len(expired) {
+				t.Fatalf("op %d: cache has %d entries, expected %d", i, cache.Count(), len(model)+len(expired))
+			}
+		}
+	})
+}
@@ -0,0 +1,279 @@
+// Package gocachetest provides helpers for testing code that uses a gocache.Cacher.
+//
+// Fake is an in-memory implementation of gocache.Cacher which records the calls made to it, and whose Get, Set and
+// Delete can be scripted to simulate specific behaviors, such as a cache that always misses or rejects writes:
+//
+//	fake := gocachetest.NewFake()
+//	fake.SetFunc = func(key string, value interface{}, ttl time.Duration) error {
+//		return gocache.ErrCacheFull
+//	}
+//	service := NewService(fake)
+//	service.DoSomething()
+//	if calls := fake.Calls(); len(calls) != 2 {
+//		t.Error("expected 2 calls to the cache, got", calls)
+//	}
+//
+// Flaky wraps any gocache.Cacher, including a *gocache.Cache, and injects latency, misses and dropped writes with
+// configurable probabilities, to validate that an application handles the failure modes of its cache:
+//
+//	flaky := gocachetest.NewFlaky(gocache.NewCache(), gocachetest.FaultConfig{MissProbability: 0.2, Seed: 42})
+package gocachetest
+
+import (
+	"sync"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+// Call is a call made to a Fake
+type Call struct {
+	// Method is the name of the method called, such as "Get" or "SetWithTTL"
+	Method string
+
+	// Key is the key passed to the method, if any
+	Key string
+
+	// Value is the value passed to the method, if any
+	Value interface{}
+
+	// TTL is the TTL passed to the method, if any
+	TTL time.Duration
+}
+
+// fakeEntry is an entry of a Fake
+type fakeEntry struct {
+	value interface{}
+
+	// expiresAt is the time at which the entry expires, or the zero time if it never expires
+	expiresAt time.Time
+}
+
+// Fake is an in-memory implementation of gocache.Cacher for tests
+//
+// By default, it behaves like a cache without maximum size. Setting GetFunc, SetFunc or DeleteFunc replaces the
+// default behavior of the corresponding methods, including when they are called by the other methods, such as
+// GetByKeys or GetOrRefresh. The functions must be set before the Fake is used.
+type Fake struct {
+	// GetFunc, if set, is called instead of retrieving the value from the Fake
+	GetFunc func(key string) (interface{}, bool)
+
+	// SetFunc, if set, is called instead of storing the value in the Fake. The ttl is NoExpiration for Set.
+	SetFunc func(key string, value interface{}, ttl time.Duration) error
+
+	// DeleteFunc, if set, is called instead of deleting the key from the Fake
+	DeleteFunc func(key string) bool
+
+	mutex   sync.Mutex
+	entries map[string]fakeEntry
+	calls   []Call
+	stats   gocache.Statistics
+}
+
+var _ gocache.Cacher = (*Fake)(nil)
+
+// NewFake creates an empty Fake. The zero value of Fake is also ready to use.
+func NewFake() *Fake {
+	return &Fake{entries: make(map[string]fakeEntry)}
+}
+
+// Calls returns the calls made to the Fake so far, in order
+func (f *Fake) Calls() []Call {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Reset forgets the calls made to the Fake so far, as well as its entries and statistics
+func (f *Fake) Reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls = nil
+	f.entries = make(map[string]fakeEntry)
+	f.stats = gocache.Statistics{}
+}
+
+// record records a call made to the Fake
+func (f *Fake) record(call Call) {
+	f.mutex.Lock()
+	f.calls = append(f.calls, call)
+	f.mutex.Unlock()
+}
+
+// Get retrieves the value of a key
+func (f *Fake) Get(key string) (interface{}, bool) {
+	f.record(Call{Method: "Get", Key: key})
+	return f.get(key)
+}
+
+func (f *Fake) get(key string) (interface{}, bool) {
+	var value interface{}
+	var ok bool
+	if f.GetFunc != nil {
+		value, ok = f.GetFunc(key)
+	} else {
+		f.mutex.Lock()
+		var entry fakeEntry
+		if entry, ok = f.entries[key]; ok && !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+			delete(f.entries, key)
+			f.stats.ExpiredKeys++
+			ok = false
+		}
+		value = entry.value
+		f.mutex.Unlock()
+	}
+	f.mutex.Lock()
+	if ok {
+		f.stats.Hits++
+	} else {
+		value = nil
+		f.stats.Misses++
+	}
+	f.mutex.Unlock()
+	return value, ok
+}
+
+// GetByKeys retrieves the values of multiple keys, with nil for the keys that don't exist
+func (f *Fake) GetByKeys(keys []string) map[string]interface{} {
+	f.record(Call{Method: "GetByKeys"})
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		values[key], _ = f.get(key)
+	}
+	return values
+}
+
+// GetOrRefresh retrieves the value of a key, or calls refresh and caches the value it returns if it doesn't exist
+func (f *Fake) GetOrRefresh(key string, ttl time.Duration, refresh func(key string) (interface{}, error)) (interface{}, error) {
+	f.record(Call{Method: "GetOrRefresh", Key: key, TTL: ttl})
+	if value, ok := f.get(key); ok {
+		return value, nil
+	}
+	value, err := refresh(key)
+	if err != nil {
+		return nil, err
+	}
+	return value, f.set(key, value, ttl)
+}
+
+// Set stores a value without expiration
+func (f *Fake) Set(key string, value interface{}) error {
+	f.record(Call{Method: "Set", Key: key, Value: value, TTL: gocache.NoExpiration})
+	return f.set(key, value, gocache.NoExpiration)
+}
+
+// SetWithTTL stores a value with a TTL. Like with Cache, a TTL of 0 means that the value is not stored at all.
+func (f *Fake) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	f.record(Call{Method: "SetWithTTL", Key: key, Value: value, TTL: ttl})
+	return f.set(key, value, ttl)
+}
+
+func (f *Fake) set(key string, value interface{}, ttl time.Duration) error {
+	if f.SetFunc != nil {
+		return f.SetFunc(key, value, ttl)
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if ttl != gocache.NoExpiration && ttl <= 0 {
+		delete(f.entries, key)
+		return nil
+	}
+	if f.entries == nil {
+		f.entries = make(map[string]fakeEntry)
+	}
+	entry := fakeEntry{value: value}
+	if ttl != gocache.NoExpiration {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	f.entries[key] = entry
+	return nil
+}
+
+// Delete deletes a key, and returns whether it existed
+func (f *Fake) Delete(key string) bool {
+	f.record(Call{Method: "Delete", Key: key})
+	return f.delete(key)
+}
+
+func (f *Fake) delete(key string) bool {
+	if f.DeleteFunc != nil {
+		return f.DeleteFunc(key)
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_, ok := f.entries[key]
+	delete(f.entries, key)
+	return ok
+}
+
+// DeleteAll deletes multiple keys, and returns the number of keys that existed
+func (f *Fake) DeleteAll(keys []string) int {
+	f.record(Call{Method: "DeleteAll"})
+	deleted := 0
+	for _, key := range keys {
+		if f.delete(key) {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// TTL returns the time until a key expires
+func (f *Fake) TTL(key string) (time.Duration, error) {
+	f.record(Call{Method: "TTL", Key: key})
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	entry, ok := f.entries[key]
+	if !ok {
+		return 0, gocache.ErrKeyDoesNotExist
+	}
+	if entry.expiresAt.IsZero() {
+		return 0, gocache.ErrKeyHasNoExpiration
+	}
+	ttl := time.Until(entry.expiresAt)
+	if ttl <= 0 {
+		return 0, gocache.ErrKeyDoesNotExist
+	}
+	return ttl, nil
+}
+
+// Expire sets the TTL of an existing key, and returns whether it existed
+func (f *Fake) Expire(key string, ttl time.Duration) bool {
+	f.record(Call{Method: "Expire", Key: key, TTL: ttl})
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	entry, ok := f.entries[key]
+	if !ok || (!entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt)) {
+		return false
+	}
+	entry.expiresAt = time.Time{}
+	if ttl != gocache.NoExpiration {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	f.entries[key] = entry
+	return true
+}
+
+// Count returns the number of entries, including the ones that have expired but haven't been retrieved since
+func (f *Fake) Count() int {
+	f.record(Call{Method: "Count"})
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.entries)
+}
+
+// Clear deletes every entry
+func (f *Fake) Clear() {
+	f.record(Call{Method: "Clear"})
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.entries = make(map[string]fakeEntry)
+}
+
+// Stats returns the hits, misses and expired keys counted by the Fake
+func (f *Fake) Stats() gocache.Statistics {
+	f.record(Call{Method: "Stats"})
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.stats
+}
@@ -0,0 +1,109 @@
+package gocachetest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+func TestFake(t *testing.T) {
+	fake := NewFake()
+	fake.Set("key", "value")
+	fake.SetWithTTL("expiring", "value", 30*time.Millisecond)
+	if value, ok := fake.Get("key"); !ok || value != "value" {
+		t.Errorf("expected value, got %v", value)
+	}
+	if _, ok := fake.Get("missing"); ok {
+		t.Error("expected a miss")
+	}
+	if _, err := fake.TTL("key"); err != gocache.ErrKeyHasNoExpiration {
+		t.Error("expected ErrKeyHasNoExpiration, got", err)
+	}
+	if ttl, err := fake.TTL("expiring"); err != nil || ttl <= 0 {
+		t.Errorf("expected a positive TTL, got %s (%v)", ttl, err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := fake.Get("expiring"); ok {
+		t.Error("expected the key to have expired")
+	}
+	if values := fake.GetByKeys([]string{"key", "missing"}); len(values) != 2 || values["key"] != "value" || values["missing"] != nil {
+		t.Error("expected map[key:value missing:<nil>], got", values)
+	}
+	if !fake.Delete("key") || fake.Delete("key") {
+		t.Error("expected the key to have been deleted once")
+	}
+	if stats := fake.Stats(); stats.Hits != 2 || stats.Misses != 3 || stats.ExpiredKeys != 1 {
+		t.Errorf("expected 2 hits, 3 misses and 1 expired key, got %+v", stats)
+	}
+	if fake.Count() != 0 {
+		t.Error("expected no entry left, got", fake.Count())
+	}
+}
+
+func TestFake_GetOrRefresh(t *testing.T) {
+	fake := NewFake()
+	calls := 0
+	refresh := func(key string) (interface{}, error) {
+		calls++
+		return "fresh", nil
+	}
+	fake.GetOrRefresh("key", time.Hour, refresh)
+	if value, err := fake.GetOrRefresh("key", time.Hour, refresh); err != nil || value != "fresh" || calls != 1 {
+		t.Errorf("expected the refreshed value to have been cached, got %v (%v) after %d calls", value, err, calls)
+	}
+	failing := func(key string) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+	if _, err := fake.GetOrRefresh("other", time.Hour, failing); err == nil {
+		t.Error("expected the error of refresh")
+	}
+}
+
+func TestFake_Scripted(t *testing.T) {
+	fake := NewFake()
+	fake.GetFunc = func(key string) (interface{}, bool) {
+		return "scripted", true
+	}
+	fake.SetFunc = func(key string, value interface{}, ttl time.Duration) error {
+		return gocache.ErrCacheFull
+	}
+	fake.DeleteFunc = func(key string) bool {
+		return true
+	}
+	if err := fake.Set("key", "value"); err != gocache.ErrCacheFull {
+		t.Error("expected ErrCacheFull, got", err)
+	}
+	if value, ok := fake.Get("anything"); !ok || value != "scripted" {
+		t.Error("expected the scripted value, got", value)
+	}
+	if value, err := fake.GetOrRefresh("key", time.Hour, nil); err != nil || value != "scripted" {
+		t.Errorf("expected GetOrRefresh to use GetFunc, got %v (%v)", value, err)
+	}
+	if deleted := fake.DeleteAll([]string{"a", "b"}); deleted != 2 {
+		t.Error("expected DeleteAll to use DeleteFunc, got", deleted)
+	}
+	if fake.Count() != 0 {
+		t.Error("expected nothing to have been stored")
+	}
+}
+
+func TestFake_Calls(t *testing.T) {
+	var fake Fake
+	var cacher gocache.Cacher = &fake
+	cacher.SetWithTTL("key", "value", time.Minute)
+	cacher.Get("key")
+	cacher.Expire("key", time.Hour)
+	cacher.Clear()
+	calls := fake.Calls()
+	expected := "[{SetWithTTL key value 1m0s} {Get key <nil> 0s} {Expire key <nil> 1h0m0s} {Clear  <nil> 0s}]"
+	if fmt.Sprint(calls) != expected {
+		t.Errorf("expected %s, got %v", expected, calls)
+	}
+	fake.Reset()
+	if len(fake.Calls()) != 0 {
+		t.Error("expected the calls to have been forgotten")
+	}
+}
@@ -0,0 +1,165 @@
+package gocachetest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+// FaultConfig configures the faults injected by a Flaky cache
+type FaultConfig struct {
+	// Latency is the artificial latency added to the calls that are delayed
+	Latency time.Duration
+
+	// LatencyProbability is the probability, between 0 and 1, that a call is delayed by Latency
+	LatencyProbability float64
+
+	// MissProbability is the probability, between 0 and 1, that a retrieval misses even if the key is in the cache
+	MissProbability float64
+
+	// DropWriteProbability is the probability, between 0 and 1, that a write is silently dropped while reporting
+	// success
+	DropWriteProbability float64
+
+	// Seed is the seed of the source of randomness deciding which calls fail, so that failures can be reproduced.
+	// A seed of 0 means that the source is seeded with the current time.
+	Seed int64
+}
+
+// Flaky is a gocache.Cacher that wraps another one and injects faults into the calls made to it, which is useful to
+// validate that an application still behaves correctly when its cache is slow, misses or loses writes
+type Flaky struct {
+	cache  gocache.Cacher
+	config FaultConfig
+
+	// mutex guards random, as rand.Rand is not safe for concurrent use
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+var _ gocache.Cacher = (*Flaky)(nil)
+
+// NewFlaky wraps a cache, such as a *gocache.Cache or a Fake, into a Flaky that injects the faults described by the
+// configuration passed as parameter
+//
+// Retrievals that miss because of MissProbability do not reach the wrapped cache, nor do dropped writes. On a miss,
+// GetOrRefresh calls refresh and writes the value it returns, which is also subject to DropWriteProbability.
+func NewFlaky(cache gocache.Cacher, config FaultConfig) *Flaky {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Flaky{cache: cache, config: config, random: rand.New(rand.NewSource(seed))}
+}
+
+// happens returns whether an event with the given probability happens
+func (f *Flaky) happens(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.random.Float64() < probability
+}
+
+// delay sleeps for the Latency with the LatencyProbability
+func (f *Flaky) delay() {
+	if f.config.Latency > 0 && f.happens(f.config.LatencyProbability) {
+		time.Sleep(f.config.Latency)
+	}
+}
+
+// Get retrieves the value of a key, unless it misses on purpose
+func (f *Flaky) Get(key string) (interface{}, bool) {
+	f.delay()
+	if f.happens(f.config.MissProbability) {
+		return nil, false
+	}
+	return f.cache.Get(key)
+}
+
+// GetByKeys retrieves the values of multiple keys, each of which may miss on purpose
+func (f *Flaky) GetByKeys(keys []string) map[string]interface{} {
+	f.delay()
+	values := f.cache.GetByKeys(keys)
+	for key := range values {
+		if f.happens(f.config.MissProbability) {
+			values[key] = nil
+		}
+	}
+	return values
+}
+
+// GetOrRefresh retrieves the value of a key, or calls refresh and writes the value it returns if it misses
+func (f *Flaky) GetOrRefresh(key string, ttl time.Duration, refresh func(key string) (interface{}, error)) (interface{}, error) {
+	if value, ok := f.Get(key); ok {
+		return value, nil
+	}
+	value, err := refresh(key)
+	if err != nil {
+		return nil, err
+	}
+	return value, f.SetWithTTL(key, value, ttl)
+}
+
+// Set stores a value without expiration, unless the write is dropped on purpose
+func (f *Flaky) Set(key string, value interface{}) error {
+	f.delay()
+	if f.happens(f.config.DropWriteProbability) {
+		return nil
+	}
+	return f.cache.Set(key, value)
+}
+
+// SetWithTTL stores a value with a TTL, unless the write is dropped on purpose
+func (f *Flaky) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	f.delay()
+	if f.happens(f.config.DropWriteProbability) {
+		return nil
+	}
+	return f.cache.SetWithTTL(key, value, ttl)
+}
+
+// Delete deletes a key
+func (f *Flaky) Delete(key string) bool {
+	f.delay()
+	return f.cache.Delete(key)
+}
+
+// DeleteAll deletes multiple keys
+func (f *Flaky) DeleteAll(keys []string) int {
+	f.delay()
+	return f.cache.DeleteAll(keys)
+}
+
+// TTL returns the time until a key expires
+func (f *Flaky) TTL(key string) (time.Duration, error) {
+	f.delay()
+	return f.cache.TTL(key)
+}
+
+// Expire sets the TTL of an existing key
+func (f *Flaky) Expire(key string, ttl time.Duration) bool {
+	f.delay()
+	return f.cache.Expire(key, ttl)
+}
+
+// Count returns the number of entries of the wrapped cache
+func (f *Flaky) Count() int {
+	f.delay()
+	return f.cache.Count()
+}
+
+// Clear deletes every entry of the wrapped cache
+func (f *Flaky) Clear() {
+	f.delay()
+	f.cache.Clear()
+}
+
+// Stats returns the statistics of the wrapped cache, which do not include the misses injected by the Flaky
+func (f *Flaky) Stats() gocache.Statistics {
+	f.delay()
+	return f.cache.Stats()
+}
@@ -0,0 +1,84 @@
+package gocachetest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+func TestNewFlaky(t *testing.T) {
+	flaky := NewFlaky(gocache.NewCache(), FaultConfig{})
+	flaky.Set("key", "value")
+	if value, ok := flaky.Get("key"); !ok || value != "value" {
+		t.Error("expected a Flaky without faults to behave like the wrapped cache, got", value)
+	}
+}
+
+func TestFlaky_MissProbability(t *testing.T) {
+	cache := gocache.NewCache()
+	cache.Set("key", "value")
+	flaky := NewFlaky(cache, FaultConfig{MissProbability: 0.5, Seed: 42})
+	misses := 0
+	for i := 0; i < 1000; i++ {
+		if _, ok := flaky.Get("key"); !ok {
+			misses++
+		}
+	}
+	if misses < 400 || misses > 600 {
+		t.Error("expected about 500 misses, got", misses)
+	}
+	if _, ok := NewFlaky(cache, FaultConfig{MissProbability: 1}).GetByKeys([]string{"key"})["key"].(string); ok {
+		t.Error("expected GetByKeys to miss")
+	}
+}
+
+func TestFlaky_DropWriteProbability(t *testing.T) {
+	cache := gocache.NewCache()
+	flaky := NewFlaky(cache, FaultConfig{DropWriteProbability: 0.5, Seed: 42})
+	for i := 0; i < 1000; i++ {
+		if err := flaky.SetWithTTL(fmt.Sprint(i), i, time.Hour); err != nil {
+			t.Fatal("expected dropped writes to report success, got", err)
+		}
+	}
+	if count := cache.Count(); count < 400 || count > 600 {
+		t.Error("expected about 500 writes to have been kept, got", count)
+	}
+	refreshes := 0
+	always := NewFlaky(cache, FaultConfig{DropWriteProbability: 1})
+	for i := 0; i < 2; i++ {
+		always.GetOrRefresh("refreshed", time.Hour, func(key string) (interface{}, error) {
+			refreshes++
+			return "value", nil
+		})
+	}
+	if refreshes != 2 {
+		t.Error("expected the refreshed value not to have been cached, got", refreshes, "refreshes")
+	}
+}
+
+func TestFlaky_Latency(t *testing.T) {
+	flaky := NewFlaky(NewFake(), FaultConfig{Latency: 20 * time.Millisecond, LatencyProbability: 1})
+	start := time.Now()
+	flaky.Get("key")
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Error("expected the call to have been delayed, took", elapsed)
+	}
+}
+
+func TestFlaky_Seed(t *testing.T) {
+	results := func() string {
+		flaky := NewFlaky(NewFake(), FaultConfig{MissProbability: 0.5, Seed: 7})
+		flaky.Set("key", "value")
+		outcome := ""
+		for i := 0; i < 20; i++ {
+			_, ok := flaky.Get("key")
+			outcome += fmt.Sprint(ok)
+		}
+		return outcome
+	}
+	if results() != results() {
+		t.Error("expected the same seed to inject the same faults")
+	}
+}
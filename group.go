@@ -0,0 +1,117 @@
+package gocache
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Group is a typed view over the entries of a shared cache whose key starts with the prefix of the group, as returned
+// by TypedGroup
+//
+// Every group shares the capacity, the eviction policy and the statistics of the underlying cache. Its keys are the
+// keys of the underlying cache without the prefix of the group.
+type Group[V any] struct {
+	cache  *Cache
+	prefix string
+}
+
+// TypedGroup returns a view over the entries of a cache whose key starts with the given prefix followed by
+// DefaultKeySeparator, which only stores values of type V. This lets each Go type, or each component of a service,
+// get its own typed and namespaced cache, while sharing the capacity of a single underlying cache.
+//
+// If the prefix is empty, the name of V is used instead, e.g. "main.User" for a group of User values.
+// Entries of the underlying cache whose value isn't a V, for instance because they were set directly through the
+// cache, count as missing from the group. Note that if WithKeyObfuscation is used, the functions iterating over the
+// keys of the group, such as Keys, Count and Clear, never find any entry.
+func TypedGroup[V any](cache *Cache, prefix string) *Group[V] {
+	if prefix == "" {
+		prefix = reflect.TypeOf((*V)(nil)).Elem().String()
+	}
+	return &Group[V]{cache: cache, prefix: prefix + DefaultKeySeparator}
+}
+
+// Cache returns the underlying cache
+func (group *Group[V]) Cache() *Cache {
+	return group.cache
+}
+
+// Key returns the key of the underlying cache a key of the group is stored under
+func (group *Group[V]) Key(key string) string {
+	return group.prefix + key
+}
+
+// Get retrieves the value of a key of the group
+// If there is no such entry, or if its value isn't a V, the value returned will be the zero value of V and the
+// boolean will be false.
+func (group *Group[V]) Get(key string) (V, bool) {
+	value, ok := group.cache.Get(group.Key(key))
+	typed, isV := value.(V)
+	return typed, ok && isV
+}
+
+// Set creates or updates a key of the group with a given value
+//
+// Returns ErrCacheFull if the underlying cache is full and its FullBehavior is RejectWrites
+func (group *Group[V]) Set(key string, value V) error {
+	return group.cache.Set(group.Key(key), value)
+}
+
+// SetWithTTL creates or updates a key of the group with a given value and expiration time (-1 is NoExpiration)
+//
+// Returns ErrCacheFull if the underlying cache is full and its FullBehavior is RejectWrites
+func (group *Group[V]) SetWithTTL(key string, value V, ttl time.Duration) error {
+	return group.cache.SetWithTTL(group.Key(key), value, ttl)
+}
+
+// GetOrRefresh retrieves the value of a key of the group, or calls refresh to retrieve a fresh value if the key
+// doesn't exist, has expired, or doesn't hold a V, in which case the fresh value is cached with the TTL passed as
+// parameter (see Cache.GetOrRefresh). The key passed to refresh is the key of the group.
+func (group *Group[V]) GetOrRefresh(key string, ttl time.Duration, refresh func(key string) (V, error)) (V, error) {
+	value, err := group.cache.GetOrRefresh(group.Key(key), ttl, func(string) (interface{}, error) {
+		return refresh(key)
+	})
+	if typed, isV := value.(V); isV || err != nil {
+		return typed, err
+	}
+	// The key holds a value that isn't a V, which must be replaced
+	fresh, err := refresh(key)
+	if err != nil {
+		return fresh, err
+	}
+	return fresh, group.SetWithTTL(key, fresh, ttl)
+}
+
+// Delete removes a key from the group
+//
+// Returns false if the key did not exist.
+func (group *Group[V]) Delete(key string) bool {
+	return group.cache.Delete(group.Key(key))
+}
+
+// Keys returns the keys of the group that haven't expired, in no particular order
+func (group *Group[V]) Keys() []string {
+	var keys []string
+	group.cache.Range(func(key string, value interface{}) bool {
+		if _, isV := value.(V); isV && strings.HasPrefix(key, group.prefix) {
+			keys = append(keys, strings.TrimPrefix(key, group.prefix))
+		}
+		return true
+	})
+	return keys
+}
+
+// Count returns the number of keys of the group that haven't expired
+func (group *Group[V]) Count() int {
+	return len(group.Keys())
+}
+
+// Clear deletes every key of the group, leaving the other entries of the underlying cache untouched, and returns the
+// number of keys deleted
+func (group *Group[V]) Clear() int {
+	keys := group.Keys()
+	for i, key := range keys {
+		keys[i] = group.Key(key)
+	}
+	return group.cache.deleteAll(keys)
+}
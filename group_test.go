@@ -0,0 +1,94 @@
+package gocache
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+type groupUser struct {
+	Name string
+}
+
+func TestTypedGroup(t *testing.T) {
+	cache := NewCache()
+	users := TypedGroup[groupUser](cache, "user")
+	counts := TypedGroup[int](cache, "")
+	if users.Key("42") != "user:42" || counts.Key("visits") != "int:visits" {
+		t.Fatalf("expected the keys to be prefixed, got %s and %s", users.Key("42"), counts.Key("visits"))
+	}
+	users.Set("42", groupUser{Name: "John"})
+	users.SetWithTTL("43", groupUser{Name: "Jane"}, time.Hour)
+	counts.Set("visits", 10)
+	if user, ok := users.Get("42"); !ok || user.Name != "John" {
+		t.Errorf("expected John, got %v", user)
+	}
+	if count, ok := counts.Get("visits"); !ok || count != 10 {
+		t.Errorf("expected 10, got %v", count)
+	}
+	if _, ok := users.Get("visits"); ok {
+		t.Error("expected the groups not to share keys")
+	}
+	if value, ok := cache.Get("user:42"); !ok || value.(groupUser).Name != "John" {
+		t.Errorf("expected the entry to be in the underlying cache, got %v", value)
+	}
+	keys := users.Keys()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "42" || keys[1] != "43" || users.Count() != 2 {
+		t.Errorf("expected the keys of the group to be 42 and 43, got %v", keys)
+	}
+	if !users.Delete("43") || users.Delete("43") {
+		t.Error("expected the key to have been deleted once")
+	}
+	if deleted := users.Clear(); deleted != 1 || cache.Count() != 1 {
+		t.Errorf("expected only the keys of the group to have been cleared, got %d deleted and %d left", deleted, cache.Count())
+	}
+	if users.Cache() != cache {
+		t.Error("expected the underlying cache to be returned")
+	}
+}
+
+func TestTypedGroupWithValueOfAnotherType(t *testing.T) {
+	cache := NewCache()
+	users := TypedGroup[groupUser](cache, "user")
+	cache.Set("user:42", "not a user")
+	if _, ok := users.Get("42"); ok {
+		t.Error("expected a value of another type to count as missing")
+	}
+	if users.Count() != 0 {
+		t.Error("expected a value of another type not to be counted")
+	}
+	user, err := users.GetOrRefresh("42", time.Hour, func(key string) (groupUser, error) {
+		return groupUser{Name: "John " + key}, nil
+	})
+	if err != nil || user.Name != "John 42" {
+		t.Errorf("expected the value of another type to have been refreshed, got %v, %v", user, err)
+	}
+	if user, ok := users.Get("42"); !ok || user.Name != "John 42" {
+		t.Errorf("expected the refreshed value to have been cached, got %v", user)
+	}
+}
+
+func TestTypedGroup_GetOrRefresh(t *testing.T) {
+	users := TypedGroup[groupUser](NewCache(), "user")
+	calls := 0
+	refresh := func(key string) (groupUser, error) {
+		calls++
+		if key == "broken" {
+			return groupUser{}, errors.New("broken")
+		}
+		return groupUser{Name: key}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if user, err := users.GetOrRefresh("john", time.Hour, refresh); err != nil || user.Name != "john" {
+			t.Errorf("expected john, got %v, %v", user, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected refresh to have been called once, got %d", calls)
+	}
+	if _, err := users.GetOrRefresh("broken", time.Hour, refresh); err == nil {
+		t.Error("expected the error of refresh to have been returned")
+	}
+}
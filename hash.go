@@ -0,0 +1,103 @@
+package gocache
+
+// hash is the value of the keys created through HSet
+type hash struct {
+	fields map[string]interface{}
+
+	// cost is the approximate size of the fields and of their values in bytes
+	cost int
+}
+
+func newHash() *hash {
+	return &hash{fields: make(map[string]interface{})}
+}
+
+// CacheCost returns the approximate size of the hash in bytes
+func (h *hash) CacheCost() int {
+	return h.cost
+}
+
+// Len returns the number of fields of the hash
+func (h *hash) Len() int {
+	return len(h.fields)
+}
+
+// HSet sets a field of the hash stored under a key, creating the hash without expiration if it doesn't exist, and
+// returns whether the field is new.
+//
+// The whole hash is a single entry, so it expires and is evicted as a whole. Use Expire to set its TTL.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through HSet
+func (c *Cache) HSet(key, field string, value interface{}) (bool, error) {
+	created := false
+	err := modifyCollection(c, key, newHash, func(h *hash) (bool, error) {
+		if previous, ok := h.fields[field]; ok {
+			h.cost -= toBytes(field) + toBytes(previous)
+		} else {
+			created = true
+		}
+		h.fields[field] = value
+		h.cost += toBytes(field) + toBytes(value)
+		return true, nil
+	})
+	return created, err
+}
+
+// HGet retrieves a field of the hash stored under a key
+// If the key or the field doesn't exist, the value returned will be nil and the boolean will be false.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through HSet
+func (c *Cache) HGet(key, field string) (interface{}, bool, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	h, ok, err := lookupCollection[*hash](c, key)
+	if !ok {
+		return nil, false, err
+	}
+	value, ok := h.fields[field]
+	return value, ok, nil
+}
+
+// HGetAll retrieves every field of the hash stored under a key, along with their values
+// If the key doesn't exist or has expired, the map returned will be empty.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through HSet
+func (c *Cache) HGetAll(key string) (map[string]interface{}, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	h, ok, err := lookupCollection[*hash](c, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	fields := make(map[string]interface{}, len(h.fields))
+	for field, value := range h.fields {
+		fields[field] = value
+	}
+	return fields, nil
+}
+
+// HDel deletes fields of the hash stored under a key, and returns the number of fields that existed. The key is
+// deleted once the hash has no fields left.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through HSet
+func (c *Cache) HDel(key string, fields ...string) (int, error) {
+	deleted := 0
+	err := modifyCollection(c, key, nil, func(h *hash) (bool, error) {
+		for _, field := range fields {
+			value, ok := h.fields[field]
+			if !ok {
+				continue
+			}
+			delete(h.fields, field)
+			h.cost -= toBytes(field) + toBytes(value)
+			deleted++
+		}
+		return deleted > 0, nil
+	})
+	return deleted, err
+}
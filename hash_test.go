@@ -0,0 +1,77 @@
+package gocache
+
+import "testing"
+
+func TestCache_HSet(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(Megabyte))
+	if created, err := cache.HSet("user:1", "name", "john"); err != nil || !created {
+		t.Errorf("expected the field to have been created, got %v (%v)", created, err)
+	}
+	if created, err := cache.HSet("user:1", "name", "jane"); err != nil || created {
+		t.Errorf("expected the field to have been updated, got %v (%v)", created, err)
+	}
+	cache.HSet("user:1", "age", 42)
+	if value, ok, err := cache.HGet("user:1", "name"); err != nil || !ok || value != "jane" {
+		t.Errorf("expected jane, got %v, %v (%v)", value, ok, err)
+	}
+	if value, ok, err := cache.HGet("user:1", "email"); err != nil || ok || value != nil {
+		t.Errorf("expected the field not to exist, got %v, %v (%v)", value, ok, err)
+	}
+	if _, ok, err := cache.HGet("user:2", "name"); err != nil || ok {
+		t.Errorf("expected the key not to exist, got %v (%v)", ok, err)
+	}
+	expectedUsage := toBytes("user:1") + toBytes("name") + toBytes("jane") + toBytes("age") + toBytes(42) + 32
+	if cache.MemoryUsage() != expectedUsage {
+		t.Errorf("expected the memory usage to be %d, got %d", expectedUsage, cache.MemoryUsage())
+	}
+}
+
+func TestCache_HGetAll(t *testing.T) {
+	cache := NewCache()
+	cache.HSet("key", "a", 1)
+	cache.HSet("key", "b", 2)
+	fields, err := cache.HGetAll("key")
+	if err != nil || len(fields) != 2 || fields["a"] != 1 || fields["b"] != 2 {
+		t.Errorf("expected map[a:1 b:2], got %v (%v)", fields, err)
+	}
+	fields["c"] = 3
+	if _, ok, _ := cache.HGet("key", "c"); ok {
+		t.Error("expected the map returned to be a copy")
+	}
+	if fields, err := cache.HGetAll("missing"); err != nil || fields == nil || len(fields) != 0 {
+		t.Errorf("expected an empty map, got %v (%v)", fields, err)
+	}
+}
+
+func TestCache_HDel(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(Megabyte))
+	cache.HSet("key", "a", 1)
+	cache.HSet("key", "b", 2)
+	if deleted, err := cache.HDel("key", "a", "c"); err != nil || deleted != 1 {
+		t.Errorf("expected 1 field to have been deleted, got %d (%v)", deleted, err)
+	}
+	if _, ok, _ := cache.HGet("key", "a"); ok {
+		t.Error("expected a to have been deleted")
+	}
+	cache.HDel("key", "b")
+	if cache.Count() != 0 || cache.MemoryUsage() != 0 {
+		t.Error("expected the key to have been deleted once the hash had no fields left")
+	}
+}
+
+func TestCache_HSetWithWrongType(t *testing.T) {
+	cache := NewCache()
+	cache.SAdd("key", "member")
+	if _, err := cache.HSet("key", "field", "value"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, _, err := cache.HGet("key", "field"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.HGetAll("key"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.HDel("key", "field"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+}
@@ -0,0 +1,133 @@
+package gocache
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// hitRatioCacheSize is the maximum number of entries of the caches the hit ratio traces are driven through
+const hitRatioCacheSize = 100
+
+// hitRatioPolicyNames are the names of the eviction policies whose hit ratios are compared
+//
+// New eviction policies should be added here, along with their expected relative hit ratios in
+// hitRatioExpectations, so that refactors can't silently degrade the quality of their evictions.
+var hitRatioPolicyNames = map[EvictionPolicy]string{
+	FirstInFirstOut:   "FirstInFirstOut",
+	LeastRecentlyUsed: "LeastRecentlyUsed",
+	LeastFrequentUsed: "LeastFrequentUsed",
+	LRUK:              "LRUK",
+	MostRecentlyUsed:  "MostRecentlyUsed",
+	Sieve:             "Sieve",
+}
+
+// hitRatioMargin is the difference by which a policy's hit ratio must exceed another's to be considered better
+const hitRatioMargin = 0.02
+
+// hitRatioExpectations are the expected relative hit ratios of the eviction policies for each trace.
+// If better is true, the hit ratio of higher must exceed that of lower by at least hitRatioMargin, otherwise it must
+// merely not be lower.
+var hitRatioExpectations = []struct {
+	trace         string
+	higher, lower EvictionPolicy
+	better        bool
+}{
+	// Policies that take the frequency of accesses into account keep the hot set when keys are each accessed once
+	{trace: "sequential-scan", higher: LeastFrequentUsed, lower: LeastRecentlyUsed, better: true},
+	{trace: "sequential-scan", higher: LRUK, lower: LeastRecentlyUsed, better: true},
+	{trace: "sequential-scan", higher: Sieve, lower: LeastRecentlyUsed, better: true},
+	{trace: "sequential-scan", higher: LeastRecentlyUsed, lower: FirstInFirstOut},
+	// Popular keys must be retained over unpopular ones
+	{trace: "zipfian", higher: LeastRecentlyUsed, lower: FirstInFirstOut, better: true},
+	{trace: "zipfian", higher: LeastFrequentUsed, lower: LeastRecentlyUsed, better: true},
+	{trace: "zipfian", higher: LRUK, lower: LeastRecentlyUsed, better: true},
+	{trace: "zipfian", higher: Sieve, lower: LeastRecentlyUsed, better: true},
+	{trace: "zipfian", higher: LeastRecentlyUsed, lower: MostRecentlyUsed, better: true},
+	// Evicting the most recently used key is the only way not to evict the key that is needed next in a loop
+	{trace: "loop", higher: MostRecentlyUsed, lower: LeastRecentlyUsed, better: true},
+	{trace: "loop", higher: MostRecentlyUsed, lower: FirstInFirstOut, better: true},
+	{trace: "loop", higher: MostRecentlyUsed, lower: Sieve, better: true},
+}
+
+// trace is a canonical sequence of keys accessed by a workload
+type trace struct {
+	name string
+	keys []string
+}
+
+// sequentialScanTrace returns a trace of a hot set of keys accessed repeatedly, interrupted by a scan of keys that
+// are each accessed only once
+func sequentialScanTrace() trace {
+	var keys []string
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 5; i++ {
+			for n := 0; n < hitRatioCacheSize/2; n++ {
+				keys = append(keys, fmt.Sprintf("hot-%d", n))
+			}
+		}
+		for n := 0; n < hitRatioCacheSize; n++ {
+			keys = append(keys, fmt.Sprintf("scan-%d-%d", round, n))
+		}
+	}
+	return trace{name: "sequential-scan", keys: keys}
+}
+
+// zipfianTrace returns a trace of keys whose popularity follows a zipfian distribution, which is typical of caches
+// sitting in front of a database
+func zipfianTrace() trace {
+	zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, hitRatioCacheSize*10)
+	keys := make([]string, 50000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", zipf.Uint64())
+	}
+	return trace{name: "zipfian", keys: keys}
+}
+
+// loopTrace returns a trace of the same keys accessed in a loop, with more keys than fit in the cache
+func loopTrace() trace {
+	var keys []string
+	for pass := 0; pass < 20; pass++ {
+		for n := 0; n < hitRatioCacheSize*3/2; n++ {
+			keys = append(keys, fmt.Sprintf("key-%d", n))
+		}
+	}
+	return trace{name: "loop", keys: keys}
+}
+
+// hitRatio drives a trace through a cache using the given eviction policy, setting every key that misses, and returns
+// the ratio of accesses that were hits
+func hitRatio(t *testing.T, policy EvictionPolicy, tr trace) float64 {
+	cache := NewCache(WithMaxSize(hitRatioCacheSize), WithEvictionPolicy(policy))
+	for _, key := range tr.keys {
+		if _, ok := cache.Get(key); !ok {
+			cache.Set(key, key)
+		}
+	}
+	if cache.Count() > hitRatioCacheSize {
+		t.Fatalf("%s: expected at most %d entries with %s, got %d", tr.name, hitRatioCacheSize, hitRatioPolicyNames[policy], cache.Count())
+	}
+	stats := cache.Stats()
+	return float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+}
+
+func TestHitRatios(t *testing.T) {
+	hitRatios := make(map[string]map[EvictionPolicy]float64)
+	for _, tr := range []trace{sequentialScanTrace(), zipfianTrace(), loopTrace()} {
+		hitRatios[tr.name] = make(map[EvictionPolicy]float64)
+		for policy, name := range hitRatioPolicyNames {
+			hitRatios[tr.name][policy] = hitRatio(t, policy, tr)
+			t.Logf("%s: %s has a hit ratio of %.3f", tr.name, name, hitRatios[tr.name][policy])
+		}
+	}
+	for _, expectation := range hitRatioExpectations {
+		higher := hitRatios[expectation.trace][expectation.higher]
+		lower := hitRatios[expectation.trace][expectation.lower]
+		higherName, lowerName := hitRatioPolicyNames[expectation.higher], hitRatioPolicyNames[expectation.lower]
+		if expectation.better && higher < lower+hitRatioMargin {
+			t.Errorf("%s: expected %s (%.3f) to have a hit ratio higher than %s (%.3f) by at least %.2f", expectation.trace, higherName, higher, lowerName, lower, hitRatioMargin)
+		} else if higher < lower {
+			t.Errorf("%s: expected %s (%.3f) not to have a hit ratio lower than %s (%.3f)", expectation.trace, higherName, higher, lowerName, lower)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package gocache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HookWorkerPoolSize is the maximum number of goroutines running asynchronous callbacks, such as the ones registered
+// through OnSetPattern, at the same time
+const HookWorkerPoolSize = 4
+
+// Hooks is a set of callbacks invoked by the cache, which can be used to attach logging, metrics or replication
+// without the cache having to know about any of them.
+//
+// BeforeSet, AfterSet, BeforeGet and AfterGet are called without holding the cache's lock, so they may call methods
+// of the cache. OnEvict and OnExpire, however, are called while the lock is held, because evictions and expirations
+// happen in the middle of other operations, which means that they must not call any method of the cache or they will
+// deadlock. They should also return quickly, as the cache is blocked until they do.
+//
+// NoopHooks can be embedded to only implement some of the callbacks.
+type Hooks interface {
+	// BeforeSet is called before a key is created or updated
+	BeforeSet(key string, value interface{}, ttl time.Duration)
+
+	// AfterSet is called after a key has been created or updated, with the error returned to the caller, if any
+	AfterSet(key string, value interface{}, ttl time.Duration, err error)
+
+	// BeforeGet is called before a key is retrieved
+	BeforeGet(key string)
+
+	// AfterGet is called after a key has been retrieved, with the value and whether it was found
+	AfterGet(key string, value interface{}, found bool)
+
+	// OnEvict is called when an entry is evicted to make room for other entries
+	OnEvict(key string, value interface{})
+
+	// OnExpire is called when an expired entry is deleted
+	OnExpire(key string, value interface{})
+}
+
+// NoopHooks is an implementation of Hooks that does nothing
+type NoopHooks struct{}
+
+func (NoopHooks) BeforeSet(string, interface{}, time.Duration)       {}
+func (NoopHooks) AfterSet(string, interface{}, time.Duration, error) {}
+func (NoopHooks) BeforeGet(string)                                   {}
+func (NoopHooks) AfterGet(string, interface{}, bool)                 {}
+func (NoopHooks) OnEvict(string, interface{})                        {}
+func (NoopHooks) OnExpire(string, interface{})                       {}
+
+// WithHooks sets the Hooks invoked by the cache.
+// Defaults to nil, meaning that no hooks are invoked
+func WithHooks(hooks Hooks) func(c *Cache) {
+	return func(c *Cache) {
+		c.hooks = hooks
+		c.evictionVetoer, _ = hooks.(EvictionVetoer)
+		c.corruptionObserver, _ = hooks.(CorruptionObserver)
+		c.refreshObserver, _ = hooks.(RefreshObserver)
+	}
+}
+
+// EvictionDecision is the decision of an EvictionVetoer on whether an entry is evicted
+type EvictionDecision int
+
+const (
+	// Evict lets the entry be evicted
+	Evict EvictionDecision = iota
+
+	// Retain vetoes the eviction of the entry
+	Retain
+)
+
+// EvictionVetoer can be implemented by Hooks to veto the eviction of entries that domain logic knows to be
+// expensive, but that the eviction policy cannot tell apart from the others.
+//
+// BeforeEvict is called before an entry is evicted to make room for other entries. If it returns Retain, the entry
+// is given a second chance: it is left where it is in the eviction order, which is where every eviction policy looks
+// for the next entry to evict, and another entry is evicted instead. The penalty is that an entry can only be
+// retained once until it is set again, so unless it is accessed in the meantime, it is the next entry evicted, and
+// BeforeEvict is not called again. Expired entries are evicted without BeforeEvict being called.
+//
+// Like OnEvict, BeforeEvict is called while the lock is held, so it must not call any method of the cache and should
+// return quickly.
+type EvictionVetoer interface {
+	BeforeEvict(key string, value interface{}) EvictionDecision
+}
+
+// onEvict invokes the OnEvict hook, if any
+//
+// The caller must hold the lock.
+func (c *Cache) onEvict(entry *Entry) {
+	if c.traced(entry.Key) {
+		c.trace(entry.Key, "evict")
+	}
+	if c.hooks != nil {
+		c.hooks.OnEvict(entry.Key, entry.Value)
+	}
+	if c.audits(OpEvict) {
+		c.audit(context.Background(), OpEvict, AuditRecord{Key: entry.Key, Size: toBytes(entry.Value)})
+	}
+}
+
+// onExpire invokes the OnExpire hook, if any
+//
+// The caller must hold the lock.
+func (c *Cache) onExpire(entry *Entry) {
+	if c.traced(entry.Key) {
+		c.trace(entry.Key, "expire")
+	}
+	if c.hooks != nil {
+		c.hooks.OnExpire(entry.Key, entry.Value)
+	}
+	if c.audits(OpExpire) {
+		c.audit(context.Background(), OpExpire, AuditRecord{Key: entry.Key, Size: toBytes(entry.Value)})
+	}
+}
+
+// hookWorkerPool runs asynchronous callbacks on at most HookWorkerPoolSize goroutines, which are only started when
+// there are callbacks to run and exit as soon as there are none left, so that an idle cache has no goroutine running
+//
+// Callbacks are started in the order they are submitted, but since they may run concurrently, they may complete in
+// any order.
+type hookWorkerPool struct {
+	mutex   sync.Mutex
+	queue   []func()
+	workers int
+}
+
+// submit queues a callback to be run by one of the workers, starting a worker labeled with the name of the cache if
+// fewer than HookWorkerPoolSize are running. It never blocks, which means that it may be called while holding the
+// cache's lock.
+func (pool *hookWorkerPool) submit(cacheName string, callback func()) {
+	pool.mutex.Lock()
+	pool.queue = append(pool.queue, callback)
+	if pool.workers < HookWorkerPoolSize {
+		pool.workers++
+		goLabeled(cacheName, "hooks", pool.work)
+	}
+	pool.mutex.Unlock()
+}
+
+// work runs the queued callbacks until there are none left
+func (pool *hookWorkerPool) work() {
+	for {
+		pool.mutex.Lock()
+		if len(pool.queue) == 0 {
+			pool.workers--
+			pool.mutex.Unlock()
+			return
+		}
+		callback := pool.queue[0]
+		pool.queue[0] = nil
+		pool.queue = pool.queue[1:]
+		pool.mutex.Unlock()
+		callback()
+	}
+}
+
+// idle returns whether there is no callback queued or running
+func (pool *hookWorkerPool) idle() bool {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	return pool.workers == 0
+}
@@ -0,0 +1,190 @@
+package gocache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordingHooks struct {
+	NoopHooks
+	events []string
+}
+
+func (h *recordingHooks) BeforeSet(key string, value interface{}, ttl time.Duration) {
+	h.events = append(h.events, fmt.Sprintf("BeforeSet %s=%v", key, value))
+}
+
+func (h *recordingHooks) AfterSet(key string, value interface{}, ttl time.Duration, err error) {
+	h.events = append(h.events, fmt.Sprintf("AfterSet %s=%v err=%v", key, value, err))
+}
+
+func (h *recordingHooks) BeforeGet(key string) {
+	h.events = append(h.events, fmt.Sprintf("BeforeGet %s", key))
+}
+
+func (h *recordingHooks) AfterGet(key string, value interface{}, found bool) {
+	h.events = append(h.events, fmt.Sprintf("AfterGet %s=%v found=%v", key, value, found))
+}
+
+func (h *recordingHooks) OnEvict(key string, value interface{}) {
+	h.events = append(h.events, fmt.Sprintf("OnEvict %s=%v", key, value))
+}
+
+func (h *recordingHooks) OnExpire(key string, value interface{}) {
+	h.events = append(h.events, fmt.Sprintf("OnExpire %s=%v", key, value))
+}
+
+func TestCache_WithHooks(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithMaxSize(1), WithHooks(hooks))
+	cache.Set("1", "a")
+	cache.SetWithTTL("2", "b", time.Millisecond)
+	cache.Get("1")
+	time.Sleep(2 * time.Millisecond)
+	cache.Get("2")
+	expectedEvents := []string{
+		"BeforeSet 1=a",
+		"AfterSet 1=a err=<nil>",
+		"BeforeSet 2=b",
+		"OnEvict 1=a",
+		"AfterSet 2=b err=<nil>",
+		"BeforeGet 1",
+		"AfterGet 1=<nil> found=false",
+		"BeforeGet 2",
+		"OnExpire 2=b",
+		"AfterGet 2=<nil> found=false",
+	}
+	if len(hooks.events) != len(expectedEvents) {
+		t.Fatalf("expected events %v, got %v", expectedEvents, hooks.events)
+	}
+	for i := range expectedEvents {
+		if hooks.events[i] != expectedEvents[i] {
+			t.Errorf("expected event #%d to be %q, got %q", i, expectedEvents[i], hooks.events[i])
+		}
+	}
+}
+
+func TestCache_WithHooksWhenSetIsRejected(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithMaxSize(1), WithFullBehavior(RejectWrites), WithHooks(hooks))
+	cache.Set("1", "a")
+	cache.Set("2", "b")
+	if last := hooks.events[len(hooks.events)-1]; last != "AfterSet 2=b err=cache is full" {
+		t.Error("expected AfterSet to be called with ErrCacheFull, got", last)
+	}
+}
+
+func TestCache_WithHooksWhenJanitorDeletesExpiredEntry(t *testing.T) {
+	expired := make(chan string, 1)
+	cache := NewCache(WithHooks(&expireHooks{expired: expired}))
+	cache.SetWithTTL("1", "a", time.Millisecond)
+	cache.StartJanitor()
+	defer cache.StopJanitor()
+	select {
+	case key := <-expired:
+		if key != "1" {
+			t.Error("expected key 1 to have expired, got", key)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected OnExpire to have been called by the janitor")
+	}
+}
+
+type expireHooks struct {
+	NoopHooks
+	expired chan string
+}
+
+func (h *expireHooks) OnExpire(key string, value interface{}) {
+	h.expired <- key
+}
+
+type vetoHooks struct {
+	NoopHooks
+	expensive map[string]bool
+	vetoed    []string
+}
+
+func (h *vetoHooks) BeforeEvict(key string, _ interface{}) EvictionDecision {
+	if h.expensive[key] {
+		h.vetoed = append(h.vetoed, key)
+		return Retain
+	}
+	return Evict
+}
+
+func TestCache_WithHooksThatVetoEvictions(t *testing.T) {
+	for _, policy := range []EvictionPolicy{FirstInFirstOut, LeastRecentlyUsed, LeastFrequentUsed, LRUK, MostRecentlyUsed, Sieve} {
+		hooks := &vetoHooks{expensive: map[string]bool{"expensive": true}}
+		cache := NewCache(WithMaxSize(2), WithEvictionPolicy(policy), WithHooks(hooks), WithRaceAssertions(true))
+		if policy == MostRecentlyUsed {
+			cache.Set("cheap", "value")
+			cache.Set("expensive", "value")
+		} else {
+			cache.Set("expensive", "value")
+			cache.Set("cheap", "value")
+		}
+		cache.Set("new", "value")
+		if _, ok := cache.Get("expensive"); !ok {
+			t.Errorf("policy %d: expected expensive to have been retained", policy)
+		}
+		if _, ok := cache.Get("cheap"); ok {
+			t.Errorf("policy %d: expected cheap to have been evicted instead", policy)
+		}
+		if len(hooks.vetoed) != 1 {
+			t.Errorf("policy %d: expected 1 veto, got %d", policy, len(hooks.vetoed))
+		}
+	}
+}
+
+func TestCache_WithHooksThatVetoEvictionsOnlyRetainsOnce(t *testing.T) {
+	hooks := &vetoHooks{expensive: map[string]bool{"expensive": true}}
+	cache := NewCache(WithMaxSize(2), WithHooks(hooks), WithRaceAssertions(true))
+	cache.Set("expensive", "value")
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	// expensive was already retained once, so it is evicted this time
+	cache.Set("3", "value")
+	if _, ok := cache.Get("expensive"); ok {
+		t.Error("expected expensive to have been evicted the second time")
+	}
+	// Setting the entry again gives it a new second chance
+	cache.Set("expensive", "value")
+	cache.Set("4", "value")
+	cache.Set("5", "value")
+	if _, ok := cache.Get("expensive"); !ok {
+		t.Error("expected expensive to have been retained after being set again")
+	}
+	if len(hooks.vetoed) != 2 {
+		t.Errorf("expected 2 vetoes, got %d", len(hooks.vetoed))
+	}
+}
+
+func TestCache_WithHooksThatVetoEvictionsOfExpiredEntries(t *testing.T) {
+	hooks := &vetoHooks{expensive: map[string]bool{"expensive": true}}
+	cache := NewCache(WithMaxSize(2), WithHooks(hooks), WithRaceAssertions(true))
+	cache.SetWithTTL("expensive", "value", time.Millisecond)
+	cache.Set("cheap", "value")
+	time.Sleep(5 * time.Millisecond)
+	cache.Set("new", "value")
+	if len(hooks.vetoed) != 0 {
+		t.Errorf("expected the expired entry not to have been vetoed, got %v", hooks.vetoed)
+	}
+	if _, ok := cache.Get("cheap"); !ok {
+		t.Error("expected cheap to have been kept, since the expired entry was evicted instead")
+	}
+}
+
+func TestCache_WithHooksAndGetByKeys(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithHooks(hooks))
+	cache.Set("1", "a")
+	hooks.events = nil
+	cache.GetByKeys([]string{"1", "2"})
+	expected := []string{"BeforeGet 1", "AfterGet 1=a found=true", "BeforeGet 2", "AfterGet 2=<nil> found=false"}
+	if !reflect.DeepEqual(hooks.events, expected) {
+		t.Errorf("expected %v, got %v", expected, hooks.events)
+	}
+}
@@ -0,0 +1,112 @@
+package gocache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SaveHotKeys writes the topN hottest entries to the file at the given path, in the same format as ExportKeys, so that
+// LoadHotKeys can restore them after a restart. This provides a fast warm-up without having to persist the entire
+// cache.
+//
+// Entries are ranked by the number of times they were retrieved, and entries retrieved the same number of times by
+// their position in the eviction order, the entries closest to the head (e.g. the most recently used ones if the
+// eviction policy is LeastRecentlyUsed) coming first. Expired entries are skipped, and a topN of 0 or less means
+// that every entry is saved.
+//
+// The file is written to a temporary file which replaces the file at the given path once complete, so an interrupted
+// save never leaves a truncated file behind. Like ExportKeys, values must be encodable by encoding/json.
+// Returns the number of entries saved.
+func (c *Cache) SaveHotKeys(path string, topN int) (int, error) {
+	c.mutex.RLock()
+	entries := make([]*Entry, 0, len(c.entries))
+	for entry := c.head; entry != nil; entry = entry.next {
+		if !entry.Expired() {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].accessCount > entries[j].accessCount
+	})
+	if topN > 0 && len(entries) > topN {
+		entries = entries[:topN]
+	}
+	hottest := make([]ExportedEntry, len(entries))
+	for i, entry := range entries {
+		hottest[i] = c.newExportedEntry(entry)
+	}
+	c.mutex.RUnlock()
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	numberOfEntriesSaved, err := c.writeExportedEntries(file, hottest)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	return numberOfEntriesSaved, os.Rename(file.Name(), path)
+}
+
+// LoadHotKeys restores the entries saved by SaveHotKeys from the file at the given path, along with their expiration
+// time. Entries that have expired since they were saved are skipped.
+//
+// The entries are set from the coldest to the hottest, so that the hottest entries are the last to be evicted if the
+// cache cannot hold all of them. Entries saved with an older schema version are upgraded by the migrations registered
+// through WithMigrations, and entries that cannot be upgraded are skipped, as well as entries whose checksum does not
+// match their value (see WithChecksums). Note that values are decoded by encoding/json, which means that, for instance, numbers
+// are restored as float64 and structs as map[string]interface{}.
+//
+// Returns the number of entries restored. If the cache rejects a write (see RejectWrites), the load stops and
+// ErrCacheFull is returned along with the number of entries restored until then.
+func (c *Cache) LoadHotKeys(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var entries []ExportedEntry
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for decoder.More() {
+		var entry rawExportedEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return 0, err
+		}
+		if err := c.verifyChecksum(entry); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(entry.Value, &entry.ExportedEntry.Value); err != nil {
+			return 0, err
+		}
+		entries = append(entries, entry.ExportedEntry)
+	}
+	numberOfEntriesRestored := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		value, err := c.migrate(entry)
+		if err != nil {
+			continue
+		}
+		ttl, expiration := time.Duration(NoExpiration), int64(NoExpiration)
+		if entry.ExpiresAt != nil {
+			if ttl = time.Until(*entry.ExpiresAt); ttl <= 0 {
+				continue
+			}
+			expiration = entry.ExpiresAt.UnixNano()
+		}
+		// The keys were saved as they are stored, so they must not be obfuscated again
+		if err := c.setStoredWithHooks(context.Background(), entry.Key, entry.Key, value, ttl, expiration, 0, nil); err != nil {
+			return numberOfEntriesRestored, err
+		}
+		numberOfEntriesRestored++
+	}
+	return numberOfEntriesRestored, nil
+}
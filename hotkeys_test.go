@@ -0,0 +1,68 @@
+package gocache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_SaveHotKeysAndLoadHotKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotkeys.jsonl")
+	cache := NewCache(WithEvictionPolicy(LeastRecentlyUsed))
+	for n := 0; n < 10; n++ {
+		cache.SetWithTTL(fmt.Sprintf("%02d", n), fmt.Sprintf("value-%02d", n), time.Hour)
+	}
+	cache.Set("no-expiration", "value")
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	for n := 0; n < 3; n++ {
+		cache.Get("05")
+	}
+	cache.Get("07")
+	cache.Get("07")
+	cache.Get("02")
+	time.Sleep(time.Millisecond)
+	numberOfEntriesSaved, err := cache.SaveHotKeys(path, 4)
+	if err != nil || numberOfEntriesSaved != 4 {
+		t.Fatalf("expected 4 entries to have been saved, got %d and %v", numberOfEntriesSaved, err)
+	}
+	restoredCache := NewCache(WithEvictionPolicy(LeastRecentlyUsed))
+	numberOfEntriesRestored, err := restoredCache.LoadHotKeys(path)
+	if err != nil || numberOfEntriesRestored != 4 {
+		t.Fatalf("expected 4 entries to have been restored, got %d and %v", numberOfEntriesRestored, err)
+	}
+	// The 3 entries that were retrieved are the hottest, followed by the most recently set entry
+	for _, key := range []string{"05", "07", "02", "no-expiration"} {
+		if _, ok := restoredCache.Get(key); !ok {
+			t.Errorf("expected %s to have been restored", key)
+		}
+	}
+	if ttl, err := restoredCache.TTL("05"); err != nil || ttl < 59*time.Minute {
+		t.Errorf("expected 05 to have been restored with its TTL, got %s and %v", ttl, err)
+	}
+	if _, err := restoredCache.TTL("no-expiration"); err != ErrKeyHasNoExpiration {
+		t.Errorf("expected no-expiration to have been restored without expiration, got %v", err)
+	}
+	if entries, _ := filepath.Glob(path + ".*"); len(entries) != 0 {
+		t.Errorf("expected the temporary file to have been removed, got %v", entries)
+	}
+}
+
+func TestCache_LoadHotKeysSkipsExpiredEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotkeys.jsonl")
+	os.WriteFile(path, []byte(`{"key":"expired","value":"v","expiresAt":"2000-01-01T00:00:00Z"}`+"\n"+`{"key":"fresh","value":"v"}`+"\n"), 0o644)
+	cache := NewCache()
+	if numberOfEntriesRestored, err := cache.LoadHotKeys(path); err != nil || numberOfEntriesRestored != 1 {
+		t.Errorf("expected 1 entry to have been restored, got %d and %v", numberOfEntriesRestored, err)
+	}
+	if _, ok := cache.Get("expired"); ok {
+		t.Error("expected the expired entry not to have been restored")
+	}
+}
+
+func TestCache_LoadHotKeysWhenFileDoesNotExist(t *testing.T) {
+	if _, err := NewCache().LoadHotKeys(filepath.Join(t.TempDir(), "missing.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
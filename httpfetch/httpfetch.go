@@ -0,0 +1,243 @@
+// Package httpfetch provides an HTTP client which caches the body of GET responses in a gocache.Cache, along with
+// their ETag and Last-Modified validators, so that expired responses are revalidated with a conditional request
+// instead of being downloaded again.
+//
+// How long a response is fresh for is taken from its Cache-Control max-age directive or its Expires header, and
+// falls back to the default TTL (see WithDefaultTTL) if it has neither. Once a response is no longer fresh, it is kept
+// in the cache for the revalidation window (see WithRevalidationWindow), during which it is revalidated rather than
+// fetched again. Responses with Cache-Control: no-store are never cached.
+//
+// Usage:
+//
+//	fetcher := httpfetch.New(gocache.NewCache(gocache.WithMaxMemoryUsage(50*gocache.Megabyte)))
+//	body, err := fetcher.Get(ctx, "https://example.com/config.json")
+package httpfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+const (
+	// KeyPrefix is the prefix of the keys used to store response bodies in the cache
+	KeyPrefix = "httpfetch:"
+
+	// DefaultTTL is how long a response without Cache-Control max-age or Expires header is fresh for if WithDefaultTTL
+	// is not used
+	DefaultTTL = time.Minute
+
+	// DefaultRevalidationWindow is how long a response is kept in the cache once it is no longer fresh if
+	// WithRevalidationWindow is not used
+	DefaultRevalidationWindow = time.Hour
+
+	// MetadataETag is the name of the metadata holding the ETag of a cached response
+	// The metadata is only attached to the entries for inspection through GetMetadata, the Fetcher itself relying on
+	// the validators stored along with the body, so that they cannot be paired with the body of another response.
+	MetadataETag = "etag"
+
+	// MetadataLastModified is the name of the metadata holding the Last-Modified header of a cached response
+	MetadataLastModified = "last-modified"
+
+	// MetadataFreshUntil is the name of the metadata holding the time, in unix nanoseconds, until which a cached
+	// response is served without being revalidated
+	MetadataFreshUntil = "fresh-until"
+)
+
+// ErrUnexpectedStatus is returned, wrapped with the status of the response, when the server responds with a status
+// other than 200 OK or, when revalidating, 304 Not Modified
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+// response is the value cached for a URL, which holds the body of the response along with its validators, so that
+// they are always read and written together
+type response struct {
+	body         []byte
+	etag         string
+	lastModified string
+	freshUntil   int64
+}
+
+// CacheCost returns the approximate size of the response in bytes (see gocache.Coster)
+func (r *response) CacheCost() int {
+	return len(r.body) + len(r.etag) + len(r.lastModified) + 8
+}
+
+// Fetcher fetches the body of HTTP GET responses, serving them from the cache when possible
+type Fetcher struct {
+	client             *http.Client
+	cache              *gocache.Cache
+	defaultTTL         time.Duration
+	revalidationWindow time.Duration
+}
+
+// Option is an option for New
+type Option func(fetcher *Fetcher)
+
+// WithHTTPClient sets the http.Client used to send the requests. Defaults to http.DefaultClient
+func WithHTTPClient(client *http.Client) Option {
+	return func(fetcher *Fetcher) {
+		fetcher.client = client
+	}
+}
+
+// WithDefaultTTL sets how long a response without Cache-Control max-age or Expires header is fresh for.
+// Defaults to DefaultTTL
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(fetcher *Fetcher) {
+		fetcher.defaultTTL = ttl
+	}
+}
+
+// WithRevalidationWindow sets how long a response is kept in the cache once it is no longer fresh, so that it can
+// be revalidated with a conditional request. Defaults to DefaultRevalidationWindow
+func WithRevalidationWindow(window time.Duration) Option {
+	return func(fetcher *Fetcher) {
+		fetcher.revalidationWindow = window
+	}
+}
+
+// New creates a Fetcher which caches the body of the responses in the given cache
+func New(cache *gocache.Cache, opts ...Option) *Fetcher {
+	fetcher := &Fetcher{
+		client:             http.DefaultClient,
+		cache:              cache,
+		defaultTTL:         DefaultTTL,
+		revalidationWindow: DefaultRevalidationWindow,
+	}
+	for _, opt := range opts {
+		opt(fetcher)
+	}
+	return fetcher
+}
+
+// Get returns the body of the response to a GET request to the given URL
+//
+// If the response is cached and still fresh, no request is sent. If it is cached but no longer fresh, a conditional
+// request is sent with its validators, and if the server responds with 304 Not Modified, the cached body is returned
+// and stays fresh for as long as the headers of the new response say. Otherwise, the response is fetched and cached.
+//
+// Note that concurrent calls for the same URL that isn't fresh will each send a request.
+func (fetcher *Fetcher) Get(ctx context.Context, url string) ([]byte, error) {
+	key := KeyPrefix + url
+	value, cached := fetcher.cache.Get(key)
+	var cachedResponse *response
+	if cached {
+		cachedResponse = value.(*response)
+		if time.Now().UnixNano() < cachedResponse.freshUntil {
+			return copyBody(cachedResponse.body), nil
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		if cachedResponse.etag != "" {
+			request.Header.Set("If-None-Match", cachedResponse.etag)
+		}
+		if cachedResponse.lastModified != "" {
+			request.Header.Set("If-Modified-Since", cachedResponse.lastModified)
+		}
+	}
+	loaded := fetcher.cache.LoadStarted()
+	defer loaded()
+	httpResponse, err := fetcher.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+	var body []byte
+	switch {
+	case httpResponse.StatusCode == http.StatusNotModified && cached:
+		body = cachedResponse.body
+		// A 304 Not Modified response may omit the validators, in which case the ones of the cached response still
+		// apply
+		if httpResponse.Header.Get("ETag") == "" {
+			httpResponse.Header.Set("ETag", cachedResponse.etag)
+		}
+		if httpResponse.Header.Get("Last-Modified") == "" {
+			httpResponse.Header.Set("Last-Modified", cachedResponse.lastModified)
+		}
+	case httpResponse.StatusCode == http.StatusOK:
+		if body, err = io.ReadAll(httpResponse.Body); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, httpResponse.Status)
+	}
+	fetcher.store(key, body, httpResponse.Header)
+	return copyBody(body), nil
+}
+
+// store caches the body of a response along with its validators, unless its headers forbid it
+func (fetcher *Fetcher) store(key string, body []byte, header http.Header) {
+	ttl, cacheable := fetcher.freshness(header)
+	if !cacheable {
+		fetcher.cache.Delete(key)
+		return
+	}
+	cachedResponse := &response{
+		body:         body,
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+		freshUntil:   time.Now().Add(ttl).UnixNano(),
+	}
+	metadata := map[string]string{MetadataFreshUntil: strconv.FormatInt(cachedResponse.freshUntil, 10)}
+	if cachedResponse.etag != "" {
+		metadata[MetadataETag] = cachedResponse.etag
+	}
+	if cachedResponse.lastModified != "" {
+		metadata[MetadataLastModified] = cachedResponse.lastModified
+	}
+	_ = fetcher.cache.SetWithMetadata(key, cachedResponse, ttl+fetcher.revalidationWindow, metadata)
+}
+
+// freshness returns how long a response with the given headers is fresh for, and whether it may be cached at all
+func (fetcher *Fetcher) freshness(header http.Header) (time.Duration, bool) {
+	maxAge := -1
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			return 0, false
+		case directive == "no-cache":
+			// The response may be cached, but it must be revalidated every time
+			maxAge = 0
+		case strings.HasPrefix(directive, "max-age=") && maxAge != 0:
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && seconds >= 0 {
+				maxAge = seconds
+			}
+		}
+	}
+	if maxAge >= 0 {
+		return time.Duration(maxAge) * time.Second, true
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// An invalid Expires header means that the response has already expired
+			return 0, true
+		}
+		now := time.Now()
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			now = date
+		}
+		if ttl := expiresAt.Sub(now); ttl > 0 {
+			return ttl, true
+		}
+		return 0, true
+	}
+	return fetcher.defaultTTL, true
+}
+
+// copyBody returns a copy of a cached body, as the caller is allowed to modify the slice returned
+func copyBody(body []byte) []byte {
+	return append([]byte(nil), body...)
+}
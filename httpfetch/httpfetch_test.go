@@ -0,0 +1,173 @@
+package httpfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+func TestFetcher_Get(t *testing.T) {
+	var requests, conditionalRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditionalRequests, 1)
+			w.Header().Set("Cache-Control", "max-age=3600")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	cache := gocache.NewCache()
+	fetcher := New(cache)
+	body, err := fetcher.Get(context.Background(), server.URL)
+	if err != nil || string(body) != "hello" {
+		t.Fatalf("expected hello, got %s and %v", body, err)
+	}
+	metadata, ok := cache.GetMetadata(KeyPrefix + server.URL)
+	if !ok || metadata[MetadataETag] != `"v1"` {
+		t.Errorf("expected the ETag to have been stored, got %v", metadata)
+	}
+	// The response must be revalidated because of no-cache, and the 304 Not Modified makes it fresh for an hour
+	if body, err = fetcher.Get(context.Background(), server.URL); err != nil || string(body) != "hello" {
+		t.Fatalf("expected hello, got %s and %v", body, err)
+	}
+	if conditionalRequests != 1 {
+		t.Errorf("expected 1 conditional request, got %d", conditionalRequests)
+	}
+	if body, err = fetcher.Get(context.Background(), server.URL); err != nil || string(body) != "hello" {
+		t.Fatalf("expected hello, got %s and %v", body, err)
+	}
+	if requests != 2 {
+		t.Errorf("expected the fresh response to be served from the cache, got %d requests", requests)
+	}
+	if metadata, _ := cache.GetMetadata(KeyPrefix + server.URL); metadata[MetadataETag] != `"v1"` {
+		t.Errorf("expected the ETag to have been kept after revalidation, got %v", metadata)
+	}
+	if ttl, _ := cache.TTL(KeyPrefix + server.URL); ttl < time.Hour+DefaultRevalidationWindow-time.Minute {
+		t.Errorf("expected the TTL to have been updated from the 304 Not Modified response, got %s", ttl)
+	}
+}
+
+func TestFetcher_GetWithLastModified(t *testing.T) {
+	lastModified := time.Unix(1, 0).UTC().Format(http.TimeFormat)
+	var conditionalRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			atomic.AddInt32(&conditionalRequests, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	fetcher := New(gocache.NewCache(), WithDefaultTTL(0))
+	for i := 0; i < 3; i++ {
+		if body, err := fetcher.Get(context.Background(), server.URL); err != nil || string(body) != "hello" {
+			t.Fatalf("expected hello, got %s and %v", body, err)
+		}
+	}
+	if conditionalRequests != 2 {
+		t.Errorf("expected 2 conditional requests, got %d", conditionalRequests)
+	}
+}
+
+func TestFetcher_GetWhenResponseMustNotBeStored(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "no-store, max-age=3600")
+		w.Write([]byte("secret"))
+	}))
+	defer server.Close()
+	cache := gocache.NewCache()
+	fetcher := New(cache)
+	fetcher.Get(context.Background(), server.URL)
+	fetcher.Get(context.Background(), server.URL)
+	if requests != 2 || cache.Count() != 0 {
+		t.Errorf("expected the response not to have been cached, got %d requests and %d entries", requests, cache.Count())
+	}
+}
+
+func TestFetcher_GetWithExpiresHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now().UTC()
+		w.Header().Set("Date", now.Format(http.TimeFormat))
+		w.Header().Set("Expires", now.Add(2*time.Hour).Format(http.TimeFormat))
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	cache := gocache.NewCache()
+	fetcher := New(cache, WithRevalidationWindow(0))
+	fetcher.Get(context.Background(), server.URL)
+	if ttl, _ := cache.TTL(KeyPrefix + server.URL); ttl < time.Hour || ttl > 2*time.Hour {
+		t.Errorf("expected the TTL to be taken from the Expires header, got %s", ttl)
+	}
+}
+
+func TestFetcher_GetWithUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	cache := gocache.NewCache()
+	fetcher := New(cache)
+	if _, err := fetcher.Get(context.Background(), server.URL); !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("expected ErrUnexpectedStatus, got %v", err)
+	}
+	if cache.Count() != 0 {
+		t.Error("expected the response not to have been cached")
+	}
+}
+
+// interleavingHooks calls a function right after the first retrieval of a cached key, which allows a concurrent call to
+// update the cache between the reads of the Fetcher
+type interleavingHooks struct {
+	gocache.NoopHooks
+	interleaved func()
+	done        int32
+}
+
+func (h *interleavingHooks) AfterGet(key string, value interface{}, found bool) {
+	// The interleaved function retrieves the key too, so it must only be called once
+	if found && atomic.CompareAndSwapInt32(&h.done, 0, 1) {
+		h.interleaved()
+	}
+}
+
+func TestFetcher_GetWhenResponseIsUpdatedConcurrently(t *testing.T) {
+	var version int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, atomic.LoadInt32(&version))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(etag))
+	}))
+	defer server.Close()
+	hooks := &interleavingHooks{}
+	fetcher := New(gocache.NewCache(gocache.WithHooks(hooks)), WithDefaultTTL(0))
+	fetcher.Get(context.Background(), server.URL)
+	atomic.StoreInt32(&version, 2)
+	hooks.interleaved = func() {
+		// The concurrent call caches v2 while the other one is revalidating v1
+		fetcher.Get(context.Background(), server.URL)
+	}
+	// If the body of v1 had been paired with the ETag of v2, the server would keep confirming the wrong body
+	for i := 0; i < 2; i++ {
+		if body, err := fetcher.Get(context.Background(), server.URL); err != nil || string(body) != `"v2"` {
+			t.Errorf("expected \"v2\", got %s and %v", body, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package gocache
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+const (
+	// hyperLogLogPrecision is the number of bits of the hash of an item used to pick its register, which gives a
+	// standard error of 1.04/sqrt(2^14), or 0.81%, like Redis
+	hyperLogLogPrecision = 14
+
+	// hyperLogLogRegisters is the number of registers of a HyperLogLog
+	hyperLogLogRegisters = 1 << hyperLogLogPrecision
+)
+
+// hyperLogLog is the value of the keys created through PFAdd
+//
+// It estimates the number of unique items added to it in a constant amount of memory, by keeping track of the
+// longest run of leading zeros of the hashes of the items in each of its registers.
+type hyperLogLog struct {
+	registers [hyperLogLogRegisters]uint8
+
+	// used is the number of registers that aren't 0
+	used int
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{}
+}
+
+// CacheCost returns the approximate size of the HyperLogLog in bytes
+func (hll *hyperLogLog) CacheCost() int {
+	return hyperLogLogRegisters + 8
+}
+
+// Len returns the number of registers that aren't 0, which is only 0 if no item was added
+func (hll *hyperLogLog) Len() int {
+	return hll.used
+}
+
+// add adds an item to the HyperLogLog, and returns whether a register was updated
+func (hll *hyperLogLog) add(item string) bool {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(item))
+	// FNV doesn't mix the high bits of short inputs well enough for them to be used as the index of the register,
+	// hence the finalizer of MurmurHash3
+	hash := hasher.Sum64()
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	index := hash >> (64 - hyperLogLogPrecision)
+	// The bit set past the precision bits caps the rank, so that a hash with no other bit set doesn't overflow
+	rank := uint8(bits.LeadingZeros64(hash<<hyperLogLogPrecision|1<<(hyperLogLogPrecision-1)) + 1)
+	if rank <= hll.registers[index] {
+		return false
+	}
+	if hll.registers[index] == 0 {
+		hll.used++
+	}
+	hll.registers[index] = rank
+	return true
+}
+
+// count returns the estimated number of unique items added to the HyperLogLog
+func (hll *hyperLogLog) count() uint64 {
+	sum := 0.0
+	for _, register := range hll.registers {
+		sum += 1 / float64(uint64(1)<<register)
+	}
+	m := float64(hyperLogLogRegisters)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if zeros := hyperLogLogRegisters - hll.used; estimate <= 2.5*m && zeros > 0 {
+		// The raw estimate is biased for small cardinalities, for which linear counting is more accurate
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// PFAdd adds items to the HyperLogLog stored under a key, creating it without expiration if it doesn't exist, and
+// returns whether the estimated number of unique items may have changed.
+//
+// A HyperLogLog estimates the number of unique items added to it, with a standard error of 0.81%, while only using
+// 16KB of memory regardless of the number of items, which makes it suitable for counting daily active users per
+// key, for instance. The items themselves cannot be retrieved. The whole HyperLogLog is a single entry, so it expires
+// and is evicted as a whole. Use Expire to set its TTL.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through PFAdd
+func (c *Cache) PFAdd(key string, items ...string) (bool, error) {
+	updated := false
+	err := modifyCollection(c, key, newHyperLogLog, func(hll *hyperLogLog) (bool, error) {
+		for _, item := range items {
+			if hll.add(item) {
+				updated = true
+			}
+		}
+		return updated, nil
+	})
+	return updated, err
+}
+
+// PFCount returns the estimated number of unique items added to the HyperLogLog stored under a key through PFAdd,
+// or 0 if the key doesn't exist or has expired
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through PFAdd
+func (c *Cache) PFCount(key string) (uint64, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	hll, ok, err := lookupCollection[*hyperLogLog](c, key)
+	if !ok {
+		return 0, err
+	}
+	return hll.count(), nil
+}
@@ -0,0 +1,62 @@
+package gocache
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCache_PFAdd(t *testing.T) {
+	cache := NewCache()
+	if updated, err := cache.PFAdd("visitors", "alice", "bob"); err != nil || !updated {
+		t.Errorf("expected the HyperLogLog to have been updated, got %v (%v)", updated, err)
+	}
+	if updated, err := cache.PFAdd("visitors", "alice"); err != nil || updated {
+		t.Errorf("expected adding an item that was already added not to update the HyperLogLog, got %v (%v)", updated, err)
+	}
+	if count, err := cache.PFCount("visitors"); err != nil || count != 2 {
+		t.Errorf("expected a count of 2, got %d (%v)", count, err)
+	}
+	if count, err := cache.PFCount("missing"); err != nil || count != 0 {
+		t.Errorf("expected a count of 0, got %d (%v)", count, err)
+	}
+	if updated, _ := cache.PFAdd("empty"); updated || cache.Count() != 1 {
+		t.Error("expected no HyperLogLog to have been created without items")
+	}
+}
+
+func TestCache_PFCountAccuracy(t *testing.T) {
+	cache := NewCache()
+	for _, cardinality := range []int{100, 10000, 200000} {
+		key := fmt.Sprintf("key-%d", cardinality)
+		for i := 0; i < cardinality; i++ {
+			cache.PFAdd(key, fmt.Sprintf("user-%d", i), fmt.Sprintf("user-%d", i/2))
+		}
+		count, _ := cache.PFCount(key)
+		// 3 standard errors
+		if relativeError := math.Abs(float64(count)-float64(cardinality)) / float64(cardinality); relativeError > 0.0243 {
+			t.Errorf("expected a count of about %d, got %d (%.2f%% off)", cardinality, count, relativeError*100)
+		}
+	}
+}
+
+func TestCache_PFAddMemoryUsage(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(Megabyte))
+	for i := 0; i < 10000; i++ {
+		cache.PFAdd("key", fmt.Sprint(i))
+	}
+	if expected := toBytes("key") + hyperLogLogRegisters + 8 + 32; cache.MemoryUsage() != expected {
+		t.Errorf("expected the memory usage to be %d regardless of the number of items, got %d", expected, cache.MemoryUsage())
+	}
+}
+
+func TestCache_PFAddWithWrongType(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	if _, err := cache.PFAdd("key", "a"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.PFCount("key"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+}
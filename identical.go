@@ -0,0 +1,47 @@
+package gocache
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// WithSkipIdenticalWrites makes the Set-like functions skip the writes of a value that is identical to the value
+// already cached under the same key, as determined by the comparator passed as parameter, so that the idempotent
+// writes of refresh jobs neither extend the TTL of the entry, nor count as accessing it, nor notify the callbacks
+// registered through OnSetPattern. Skipped writes are counted in Statistics.IdenticalWrites.
+//
+// If the comparator is nil, IdenticalValues is used. The comparator is called while the lock of the cache is held,
+// so it must be fast and must not call any method of the cache. Writes are never skipped if the entry has expired, and
+// the hooks are invoked regardless of whether the write was skipped.
+//
+// Defaults to false, meaning that every write is applied
+func WithSkipIdenticalWrites(comparator func(current, value interface{}) bool) func(c *Cache) {
+	return func(c *Cache) {
+		if comparator == nil {
+			comparator = IdenticalValues
+		}
+		c.identical = comparator
+	}
+}
+
+// IdenticalValues returns whether two values are identical, comparing []byte values byte by byte, values of
+// comparable types with ==, and other values, such as slices and maps, with reflect.DeepEqual
+func IdenticalValues(a, b interface{}) (identical bool) {
+	if aBytes, ok := a.([]byte); ok {
+		bBytes, ok := b.([]byte)
+		return ok && bytes.Equal(aBytes, bBytes)
+	}
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return a == nil && b == nil
+	}
+	if !reflect.TypeOf(a).Comparable() {
+		return reflect.DeepEqual(a, b)
+	}
+	// Comparing structs with interface fields holding uncomparable values panics, even though their type is comparable
+	defer func() {
+		if recover() != nil {
+			identical = reflect.DeepEqual(a, b)
+		}
+	}()
+	return a == b
+}
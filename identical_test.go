@@ -0,0 +1,90 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithSkipIdenticalWrites(t *testing.T) {
+	cache := NewCache(WithMaxSize(2), WithEvictionPolicy(LeastRecentlyUsed), WithSkipIdenticalWrites(nil))
+	cache.SetWithTTL("key", []byte("value"), time.Minute)
+	cache.Set("other", "value")
+	if err := cache.SetWithTTL("key", []byte("value"), time.Hour); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if ttl, _ := cache.TTL("key"); ttl > time.Minute {
+		t.Errorf("expected the identical write not to have extended the TTL, got %s", ttl)
+	}
+	// Since the identical write didn't count as accessing the key, it is still the least recently used
+	cache.Set("third", "value")
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected the identical write not to have repositioned the entry")
+	}
+	cache.Set("other", "updated")
+	if value, _ := cache.Get("other"); value != "updated" {
+		t.Errorf("expected a different value to have been written, got %v", value)
+	}
+	if identicalWrites := cache.Stats().IdenticalWrites; identicalWrites != 1 {
+		t.Error("expected 1 identical write, got", identicalWrites)
+	}
+}
+
+func TestWithSkipIdenticalWritesWithComparator(t *testing.T) {
+	type version struct {
+		ID   int
+		Body string
+	}
+	cache := NewCache(WithSkipIdenticalWrites(func(current, value interface{}) bool {
+		return current.(version).ID == value.(version).ID
+	}))
+	cache.Set("key", version{ID: 1, Body: "first"})
+	cache.Set("key", version{ID: 1, Body: "same version"})
+	if value, _ := cache.Get("key"); value.(version).Body != "first" {
+		t.Errorf("expected the write of the same version to have been skipped, got %v", value)
+	}
+	cache.Set("key", version{ID: 2, Body: "second"})
+	if value, _ := cache.Get("key"); value.(version).Body != "second" {
+		t.Errorf("expected the write of another version to have been applied, got %v", value)
+	}
+}
+
+func TestWithSkipIdenticalWritesWhenExpired(t *testing.T) {
+	cache := NewCache(WithSkipIdenticalWrites(nil))
+	cache.SetWithTTL("key", "value", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	cache.SetWithTTL("key", "value", time.Hour)
+	if _, ok := cache.Get("key"); !ok {
+		t.Error("expected the write of an expired entry not to have been skipped")
+	}
+	cache.SetWithTTL("key", "value", 0)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected a TTL of 0 to delete the entry, even if the value is identical")
+	}
+}
+
+func TestIdenticalValues(t *testing.T) {
+	type withSlice struct {
+		Values interface{}
+	}
+	scenarios := []struct {
+		a, b      interface{}
+		identical bool
+	}{
+		{"value", "value", true},
+		{"value", "other", false},
+		{1, 1, true},
+		{1, int64(1), false},
+		{[]byte("value"), []byte("value"), true},
+		{[]byte("value"), "value", false},
+		{[]string{"a"}, []string{"a"}, true},
+		{map[string]int{"a": 1}, map[string]int{"a": 2}, false},
+		{withSlice{[]int{1}}, withSlice{[]int{1}}, true},
+		{nil, nil, true},
+		{nil, "value", false},
+	}
+	for _, scenario := range scenarios {
+		if identical := IdenticalValues(scenario.a, scenario.b); identical != scenario.identical {
+			t.Errorf("expected IdenticalValues(%v, %v) to be %v, got %v", scenario.a, scenario.b, scenario.identical, identical)
+		}
+	}
+}
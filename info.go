@@ -0,0 +1,143 @@
+package gocache
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfoSections are the sections written by WriteInfo when no section is requested, in the order they are written
+var InfoSections = []string{"server", "memory", "stats", "keyspace"}
+
+// WriteInfo writes the state of the cache to the writer passed as parameter in the format of the reply to the Redis
+// INFO command, so that a RESP server or an HTTP admin endpoint exposing the cache can be monitored by existing Redis
+// dashboards and by checks parsing the output of redis-cli INFO.
+//
+// The sections passed as parameter are written in the order they are passed, and their names are case-insensitive.
+// Unknown sections are ignored, just like Redis does. If no section is passed, or if "default", "all" or "everything"
+// is passed, every section of InfoSections is written. The entries of the cache are all in the keyspace of db0.
+//
+// Like MemoryUsage, used_memory is always 0 if MaxMemoryUsage is set to NoMaxMemoryUsage. The state is captured
+// while holding the lock, but it is written after releasing it. Returns the error returned by the writer, if any.
+func (c *Cache) WriteInfo(w io.Writer, sections ...string) error {
+	var requested []string
+	for _, section := range sections {
+		switch section = strings.ToLower(section); section {
+		case "default", "all", "everything":
+			requested = append(requested, InfoSections...)
+		default:
+			requested = append(requested, section)
+		}
+	}
+	if len(requested) == 0 {
+		requested = InfoSections
+	}
+	stats := c.Stats()
+	var sb strings.Builder
+	now := time.Now().UnixNano()
+	c.mutex.RLock()
+	for _, section := range requested {
+		switch section {
+		case "server":
+			sb.WriteString("# Server\r\n")
+			writeInfoField(&sb, "redis_mode", "standalone")
+			writeInfoField(&sb, "gocache_name", c.name)
+			writeInfoField(&sb, "go_version", runtime.Version())
+			writeInfoField(&sb, "os", runtime.GOOS)
+			writeInfoField(&sb, "arch_bits", strconv.Itoa(strconv.IntSize))
+			writeInfoField(&sb, "process_id", strconv.Itoa(os.Getpid()))
+		case "memory":
+			sb.WriteString("# Memory\r\n")
+			writeInfoField(&sb, "used_memory", strconv.Itoa(c.memoryUsage))
+			writeInfoField(&sb, "used_memory_human", infoBytes(c.memoryUsage))
+			writeInfoField(&sb, "maxmemory", strconv.Itoa(c.maxMemoryUsage))
+			writeInfoField(&sb, "maxmemory_human", infoBytes(c.maxMemoryUsage))
+			writeInfoField(&sb, "maxmemory_policy", c.infoPolicy())
+			writeInfoField(&sb, "maxkeys", strconv.Itoa(c.maxSize))
+		case "stats":
+			sb.WriteString("# Stats\r\n")
+			writeInfoField(&sb, "keyspace_hits", strconv.FormatUint(stats.Hits, 10))
+			writeInfoField(&sb, "keyspace_misses", strconv.FormatUint(stats.Misses, 10))
+			writeInfoField(&sb, "expired_keys", strconv.FormatUint(stats.ExpiredKeys, 10))
+			writeInfoField(&sb, "evicted_keys", strconv.FormatUint(stats.EvictedKeys, 10))
+		case "keyspace":
+			sb.WriteString("# Keyspace\r\n")
+			keys, expires, totalTTL := 0, 0, int64(0)
+			for _, entry := range c.entries {
+				if entry.Expired() {
+					continue
+				}
+				keys++
+				if entry.Expiration != NoExpiration {
+					expires++
+					totalTTL += entry.Expiration - now
+				}
+			}
+			// Like Redis, the line of a database is omitted when it has no keys
+			if keys > 0 {
+				averageTTL := int64(0)
+				if expires > 0 {
+					averageTTL = totalTTL / int64(expires) / int64(time.Millisecond)
+				}
+				fmt.Fprintf(&sb, "db0:keys=%d,expires=%d,avg_ttl=%d\r\n", keys, expires, averageTTL)
+			}
+		default:
+			continue
+		}
+		sb.WriteString("\r\n")
+	}
+	c.mutex.RUnlock()
+	// Like Redis, sections are separated by an empty line, but the last one isn't followed by one
+	_, err := io.WriteString(w, strings.TrimSuffix(sb.String(), "\r\n"))
+	return err
+}
+
+// writeInfoField writes a field of a section of the output of WriteInfo
+func writeInfoField(sb *strings.Builder, name, value string) {
+	sb.WriteString(name)
+	sb.WriteByte(':')
+	sb.WriteString(value)
+	sb.WriteString("\r\n")
+}
+
+// infoBytes returns a human-readable representation of a number of bytes, in the format used by Redis
+func infoBytes(bytes int) string {
+	switch {
+	case bytes >= Gigabyte:
+		return strconv.FormatFloat(float64(bytes)/Gigabyte, 'f', 2, 64) + "G"
+	case bytes >= Megabyte:
+		return strconv.FormatFloat(float64(bytes)/Megabyte, 'f', 2, 64) + "M"
+	case bytes >= Kilobyte:
+		return strconv.FormatFloat(float64(bytes)/Kilobyte, 'f', 2, 64) + "K"
+	default:
+		return strconv.Itoa(bytes) + "B"
+	}
+}
+
+// infoPolicy returns the name of the eviction policy of the cache in the format of the maxmemory-policy of Redis
+// The policies that Redis doesn't have are named after the EvictionPolicy itself.
+//
+// The caller must hold the lock.
+func (c *Cache) infoPolicy() string {
+	if c.fullBehavior == RejectWrites {
+		return "noeviction"
+	}
+	switch c.evictionPolicy {
+	case LeastRecentlyUsed:
+		return "allkeys-lru"
+	case LeastFrequentUsed:
+		return "allkeys-lfu"
+	case LRUK:
+		return "allkeys-lru-k"
+	case MostRecentlyUsed:
+		return "allkeys-mru"
+	case Sieve:
+		return "allkeys-sieve"
+	default:
+		return "allkeys-fifo"
+	}
+}
@@ -0,0 +1,57 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_WriteInfo(t *testing.T) {
+	cache := NewCache(WithName("sessions"), WithEvictionPolicy(LeastRecentlyUsed), WithMaxMemoryUsage(2*Megabyte))
+	cache.Set("a", "value")
+	cache.SetWithTTL("b", "value", time.Hour)
+	cache.Get("a")
+	cache.Get("missing")
+	var sb strings.Builder
+	if err := cache.WriteInfo(&sb); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	info := sb.String()
+	for _, expected := range []string{
+		"# Server\r\n",
+		"gocache_name:sessions\r\n",
+		"\r\n\r\n# Memory\r\n",
+		"maxmemory:2097152\r\n",
+		"maxmemory_human:2.00M\r\n",
+		"maxmemory_policy:allkeys-lru\r\n",
+		"# Stats\r\n",
+		"keyspace_hits:1\r\n",
+		"keyspace_misses:1\r\n",
+		"# Keyspace\r\n",
+		"db0:keys=2,expires=1,avg_ttl=",
+	} {
+		if !strings.Contains(info, expected) {
+			t.Errorf("expected the info to contain %q, got:\n%s", expected, info)
+		}
+	}
+	if strings.HasSuffix(info, "\r\n\r\n") || !strings.HasSuffix(info, "\r\n") {
+		t.Errorf("expected the last section to end with a single line break, got %q", info)
+	}
+}
+
+func TestCache_WriteInfoWithSections(t *testing.T) {
+	cache := NewCache(WithFullBehavior(RejectWrites))
+	var sb strings.Builder
+	if err := cache.WriteInfo(&sb, "MEMORY", "unknown", "keyspace"); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	expected := "# Memory\r\nused_memory:0\r\nused_memory_human:0B\r\nmaxmemory:0\r\nmaxmemory_human:0B\r\nmaxmemory_policy:noeviction\r\nmaxkeys:100000\r\n\r\n# Keyspace\r\n"
+	if sb.String() != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, sb.String())
+	}
+	sb.Reset()
+	cache.WriteInfo(&sb, "all")
+	if strings.Count(sb.String(), "# ") != len(InfoSections) {
+		t.Errorf("expected every section to be written, got:\n%s", sb.String())
+	}
+}
@@ -37,7 +37,7 @@ func (c *Cache) StartJanitor() error {
 		return ErrJanitorAlreadyRunning
 	}
 	c.stopJanitor = make(chan bool)
-	go func() {
+	goLabeled(c.name, "janitor", func() {
 		// rather than starting from the tail on every run, we can try to start from the last traversed entry
 		var lastTraversedNode *Entry
 		totalNumberOfExpiredKeysInPreviousRunFromTailToHead := 0
@@ -70,13 +70,14 @@ func (c *Cache) StartJanitor() error {
 						// since we're walking from the tail to the head, we get the previous reference
 						var previous *Entry
 						steps++
-						if current.Expired() {
+						if current.expiredBeyond(c.staleGrace()) {
 							expiredEntriesFound++
 							// Because delete will remove the previous reference from the entry, we need to store the
 							// previous reference before we delete it
 							previous = current.previous
 							c.delete(current.Key)
 							c.stats.ExpiredKeys++
+							c.onExpire(current)
 						}
 						if current == c.head {
 							lastTraversedNode = nil
@@ -113,13 +114,15 @@ func (c *Cache) StartJanitor() error {
 						backOff = JanitorMaxShiftBackOff
 					}
 				}
+				c.purgeTombstones()
+				c.assertInvariants()
 				c.mutex.Unlock()
 			case <-c.stopJanitor:
 				c.stopJanitor <- true
 				return
 			}
 		}
-	}()
+	})
 	//if Debug {
 	//	go func() {
 	//		var m runtime.MemStats
@@ -0,0 +1,123 @@
+package gocache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultKeySeparator is the separator used by Key to join the parts of a key
+	DefaultKeySeparator = ":"
+
+	// keyEscape is the character prepended to occurrences of the separator and of itself within a part of a key
+	keyEscape = '\\'
+
+	// keyHashSeparator separates the truncated key from its hash when a key is hashed by KeyBuilder
+	keyHashSeparator = "#"
+)
+
+// DefaultKeyBuilder is the KeyBuilder used by Key
+var DefaultKeyBuilder = KeyBuilder{Separator: DefaultKeySeparator}
+
+// KeyBuilder builds cache keys out of multiple parts, so that every key in a codebase follows the same format
+type KeyBuilder struct {
+	// Separator is the string used to join the parts of a key
+	// Occurrences of the separator within a part are escaped, so that Key("a:b", "c") and Key("a", "b:c") differ
+	Separator string
+
+	// MaxLength is the maximum length of a key
+	// Keys longer than MaxLength are truncated and suffixed with the SHA-256 of the full key, which keeps them unique
+	// while preserving their prefix for pattern matching. 0 means there is no maximum length
+	MaxLength int
+}
+
+// Key joins the parts passed as parameter with DefaultKeySeparator, escaping any separator within the parts
+//
+// e.g.
+//     gocache.Key("user", 42, "profile") returns "user:42:profile"
+//     gocache.Key("user", "a:b") returns "user:a\:b"
+func Key(parts ...interface{}) string {
+	return DefaultKeyBuilder.Key(parts...)
+}
+
+// Key joins the parts passed as parameter with the KeyBuilder's Separator, escaping any separator within the parts,
+// and hashes the result if it is longer than the KeyBuilder's MaxLength
+func (builder KeyBuilder) Key(parts ...interface{}) string {
+	var sb strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			sb.WriteString(builder.Separator)
+		}
+		builder.writePart(&sb, formatKeyPart(part))
+	}
+	key := sb.String()
+	if builder.MaxLength > 0 && len(key) > builder.MaxLength {
+		return hashKey(key, builder.MaxLength)
+	}
+	return key
+}
+
+// writePart writes a part of a key, escaping the separator and the escape character
+func (builder KeyBuilder) writePart(sb *strings.Builder, part string) {
+	if builder.Separator == "" || (!strings.Contains(part, builder.Separator) && strings.IndexByte(part, keyEscape) == -1) {
+		sb.WriteString(part)
+		return
+	}
+	for i := 0; i < len(part); {
+		if part[i] == keyEscape {
+			sb.WriteByte(keyEscape)
+			sb.WriteByte(keyEscape)
+			i++
+		} else if strings.HasPrefix(part[i:], builder.Separator) {
+			sb.WriteByte(keyEscape)
+			sb.WriteString(builder.Separator)
+			i += len(builder.Separator)
+		} else {
+			sb.WriteByte(part[i])
+			i++
+		}
+	}
+}
+
+// formatKeyPart returns the string representation of a part of a key, avoiding fmt for the most common types
+func formatKeyPart(part interface{}) string {
+	switch p := part.(type) {
+	case string:
+		return p
+	case []byte:
+		return string(p)
+	case int:
+		return strconv.Itoa(p)
+	case int64:
+		return strconv.FormatInt(p, 10)
+	case int32:
+		return strconv.FormatInt(int64(p), 10)
+	case uint:
+		return strconv.FormatUint(uint64(p), 10)
+	case uint64:
+		return strconv.FormatUint(p, 10)
+	case uint32:
+		return strconv.FormatUint(uint64(p), 10)
+	case bool:
+		return strconv.FormatBool(p)
+	case fmt.Stringer:
+		return p.String()
+	default:
+		return fmt.Sprint(p)
+	}
+}
+
+// hashKey truncates a key so that, once suffixed with its SHA-256, it fits within maxLength
+// If maxLength is too small to fit anything but the hash, only the hash is returned
+func hashKey(key string, maxLength int) string {
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	prefixLength := maxLength - len(keyHashSeparator) - len(hash)
+	if prefixLength <= 0 {
+		return hash
+	}
+	return key[:prefixLength] + keyHashSeparator + hash
+}
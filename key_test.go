@@ -0,0 +1,56 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		parts    []interface{}
+		expected string
+	}{
+		{name: "strings", parts: []interface{}{"user", "john", "profile"}, expected: "user:john:profile"},
+		{name: "integers", parts: []interface{}{"user", 42, int64(-1), uint64(7)}, expected: "user:42:-1:7"},
+		{name: "bytes-and-bool", parts: []interface{}{[]byte("flag"), true}, expected: "flag:true"},
+		{name: "stringer", parts: []interface{}{"ttl", time.Second}, expected: "ttl:1s"},
+		{name: "separator-is-escaped", parts: []interface{}{"user", "a:b"}, expected: `user:a\:b`},
+		{name: "escape-is-escaped", parts: []interface{}{`a\`, "b"}, expected: `a\\:b`},
+		{name: "empty", parts: nil, expected: ""},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if key := Key(scenario.parts...); key != scenario.expected {
+				t.Errorf("expected %s, got %s", scenario.expected, key)
+			}
+		})
+	}
+	if Key("a:b", "c") == Key("a", "b:c") {
+		t.Error("expected keys with separators in different parts to differ")
+	}
+	if Key(`a\`, "b") == Key("a", `\b`) {
+		t.Error("expected keys with escape characters in different parts to differ")
+	}
+}
+
+func TestKeyBuilder_Key(t *testing.T) {
+	builder := KeyBuilder{Separator: "::", MaxLength: 80}
+	if key := builder.Key("user", "a::b", 1); key != `user::a\::b::1` {
+		t.Errorf("expected user::a\\::b::1, got %s", key)
+	}
+	long := builder.Key("user", strings.Repeat("x", 100))
+	if len(long) != 80 {
+		t.Errorf("expected hashed key to have a length of 80, got %d", len(long))
+	}
+	if !strings.HasPrefix(long, "user::xxx") || !MatchPattern("user::*", long) {
+		t.Error("expected hashed key to keep its prefix, got", long)
+	}
+	if long == builder.Key("user", strings.Repeat("x", 101)) {
+		t.Error("expected different long keys to have different hashes")
+	}
+	if key := (KeyBuilder{Separator: ":", MaxLength: 10}).Key("user", strings.Repeat("x", 100)); len(key) != 64 {
+		t.Errorf("expected only the hash to be returned when MaxLength is too small, got %s", key)
+	}
+}
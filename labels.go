@@ -0,0 +1,15 @@
+package gocache
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// goLabeled runs fn on a new goroutine labeled with the name of the cache it works for and its role, so that the
+// CPU used by the background goroutines of each cache can be told apart in profiles (see WithName)
+func goLabeled(cacheName, role string, fn func()) {
+	labels := pprof.Labels("gocache.cache", cacheName, "gocache.role", role)
+	go pprof.Do(context.Background(), labels, func(context.Context) {
+		fn()
+	})
+}
@@ -0,0 +1,32 @@
+package gocache
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_WithName(t *testing.T) {
+	cache := NewCache(WithName("sessions"))
+	if cache.Name() != "sessions" {
+		t.Errorf("expected sessions, got %s", cache.Name())
+	}
+	if err := cache.StartJanitor(); err != nil {
+		t.Fatal(err)
+	}
+	defer cache.StopJanitor()
+	// The labels are only applied once the goroutine has started running
+	var buf bytes.Buffer
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		buf.Reset()
+		if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(buf.String(), `"gocache.cache":"sessions"`) && strings.Contains(buf.String(), `"gocache.role":"janitor"`) {
+			return
+		}
+	}
+	t.Errorf("expected the janitor's goroutine to be labeled with the name of the cache and its role, got:\n%s", buf.String())
+}
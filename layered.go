@@ -0,0 +1,308 @@
+package gocache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PromotionMaxTrackedKeys is the maximum number of keys whose hits a PromoteAfterHits policy keeps track of at once.
+// Once it is reached, the hits of every key are forgotten, so that keys that are never promoted cannot grow the
+// policy's memory usage indefinitely.
+const PromotionMaxTrackedKeys = 10000
+
+// PromotionPolicy decides whether an entry found in the l2 cache of a LayeredCache is promoted to the l1 cache
+type PromotionPolicy interface {
+	// ShouldPromote is called on every hit in the l2 cache, and returns whether the entry should be promoted
+	ShouldPromote(key string) bool
+}
+
+// PromotionPolicyFunc is a function that can be used as a PromotionPolicy
+type PromotionPolicyFunc func(key string) bool
+
+// ShouldPromote calls f(key)
+func (f PromotionPolicyFunc) ShouldPromote(key string) bool {
+	return f(key)
+}
+
+// PromoteAlways returns a PromotionPolicy that promotes entries on every hit in the l2 cache
+func PromoteAlways() PromotionPolicy {
+	return PromotionPolicyFunc(func(string) bool {
+		return true
+	})
+}
+
+// PromoteEveryNth returns a PromotionPolicy that promotes the entry of every n-th hit in the l2 cache, regardless
+// of its key, which bounds the rate of writes to the l1 cache. An n lower than 1 is treated as 1.
+func PromoteEveryNth(n int) PromotionPolicy {
+	if n < 1 {
+		n = 1
+	}
+	var hits uint64
+	return PromotionPolicyFunc(func(string) bool {
+		return atomic.AddUint64(&hits, 1)%uint64(n) == 0
+	})
+}
+
+// PromoteAfterHits returns a PromotionPolicy that promotes an entry once it has been hit threshold times in the l2
+// cache, so that only frequently accessed entries make it to the l1 cache. A threshold lower than 1 is treated as 1.
+//
+// See PromotionMaxTrackedKeys
+func PromoteAfterHits(threshold int) PromotionPolicy {
+	if threshold < 1 {
+		threshold = 1
+	}
+	var mutex sync.Mutex
+	hits := make(map[string]int)
+	return PromotionPolicyFunc(func(key string) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if _, tracked := hits[key]; !tracked && len(hits) >= PromotionMaxTrackedKeys {
+			hits = make(map[string]int)
+		}
+		hits[key]++
+		if hits[key] < threshold {
+			return false
+		}
+		delete(hits, key)
+		return true
+	})
+}
+
+// LayeredCache arranges two caches in a hierarchy, typically a small l1 cache in front of a larger l2 cache.
+// Misses in the l1 cache fall through to the l2 cache, and hits in the l2 cache are promoted to the l1 cache
+// depending on the PromotionPolicy.
+//
+// Writes go to the l2 cache, which holds every entry, while the l1 cache only holds the entries that were promoted.
+// Each underlying cache keeps its own configuration, eviction policy and statistics.
+type LayeredCache struct {
+	// The counters are updated atomically, so they come first to be 64-bit aligned on 32-bit platforms
+	l1Hits      uint64
+	l2Hits      uint64
+	misses      uint64
+	promotions  uint64
+	originLoads uint64
+
+	l1      *Cache
+	l2      *Cache
+	promote PromotionPolicy
+}
+
+// TierStatistics are the statistics of a LayeredCache, which show the effectiveness of each cache of the hierarchy
+// separately, as returned by LayeredCache.Stats
+//
+// The Statistics of each underlying cache are still available through L1 and L2, but they cannot tell apart a miss
+// in the l1 cache that was a hit in the l2 cache from a miss in both.
+type TierStatistics struct {
+	// L1Hits is the number of keys retrieved through the LayeredCache that were found in the l1 cache
+	L1Hits uint64
+
+	// L2Hits is the number of keys retrieved through the LayeredCache that were found in the l2 cache only
+	L2Hits uint64
+
+	// Misses is the number of keys retrieved through the LayeredCache that were found in neither cache
+	Misses uint64
+
+	// Promotions is the number of entries of the l2 cache copied to the l1 cache (see PromotionPolicy)
+	Promotions uint64
+
+	// OriginLoads is the number of values loaded from the origin by LayeredCache.GetOrRefresh after a miss in both
+	// caches
+	OriginLoads uint64
+}
+
+// HitRatio returns the fraction of the keys retrieved through the LayeredCache that were found in either cache, or 0
+// if no key was retrieved
+func (stats TierStatistics) HitRatio() float64 {
+	total := stats.L1Hits + stats.L2Hits + stats.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(stats.L1Hits+stats.L2Hits) / float64(total)
+}
+
+// NewLayeredCache creates a LayeredCache from an l1 cache, an l2 cache and the PromotionPolicy deciding which hits
+// in the l2 cache are promoted to the l1 cache
+func NewLayeredCache(l1, l2 *Cache, promote PromotionPolicy) *LayeredCache {
+	return &LayeredCache{
+		l1:      l1,
+		l2:      l2,
+		promote: promote,
+	}
+}
+
+// L1 returns the cache that is looked up first
+func (lc *LayeredCache) L1() *Cache {
+	return lc.l1
+}
+
+// L2 returns the cache that misses in the l1 cache fall through to
+func (lc *LayeredCache) L2() *Cache {
+	return lc.l2
+}
+
+// Set creates or updates a key with a given value
+//
+// Returns ErrCacheFull if the l2 cache is full and its FullBehavior is RejectWrites
+func (lc *LayeredCache) Set(key string, value interface{}) error {
+	return lc.SetWithTTL(key, value, ttlOf(value))
+}
+
+// SetWithTTL creates or updates a key with a given value and expiration time in the l2 cache, and removes the key
+// from the l1 cache so that it never serves an outdated value once SetWithTTL returns. The key is promoted again on a
+// later hit.
+//
+// Returns ErrCacheFull if the l2 cache is full and its FullBehavior is RejectWrites
+func (lc *LayeredCache) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	// The key is removed from the l1 cache after the l2 cache is written, so that a concurrent promotion of the
+	// previous value is either removed here or detected by promoteToL1
+	err := lc.l2.SetWithTTL(key, value, ttl)
+	lc.l1.Delete(key)
+	return err
+}
+
+// Get retrieves an entry from the l1 cache or, if it isn't there, from the l2 cache, in which case the entry may be
+// promoted to the l1 cache with the same expiration time
+//
+// Because the l1 cache is looked up first, a key only stored in the l2 cache counts as a miss in the l1 cache's
+// statistics.
+func (lc *LayeredCache) Get(key string) (interface{}, bool) {
+	if value, ok := lc.l1.Get(key); ok {
+		atomic.AddUint64(&lc.l1Hits, 1)
+		return value, true
+	}
+	value, ok := lc.l2.Get(key)
+	if !ok {
+		atomic.AddUint64(&lc.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&lc.l2Hits, 1)
+	if lc.promote.ShouldPromote(key) {
+		lc.promoteToL1(key)
+	}
+	return value, true
+}
+
+// GetOrRefresh retrieves the value of a key from either cache like Get does, or calls refresh to load a fresh value
+// from the origin if the key is in neither cache, in which case the value returned by refresh is set with the TTL
+// passed as parameter like SetWithTTL does, and the load is counted in TierStatistics.OriginLoads.
+//
+// Returns the error returned by refresh, if any, or ErrCacheFull along with the fresh value if the l2 cache rejects
+// it because it is full and its FullBehavior is RejectWrites.
+func (lc *LayeredCache) GetOrRefresh(key string, ttl time.Duration, refresh func(key string) (interface{}, error)) (interface{}, error) {
+	if value, ok := lc.Get(key); ok {
+		return value, nil
+	}
+	atomic.AddUint64(&lc.originLoads, 1)
+	loaded := lc.l2.LoadStarted()
+	value, err := refresh(key)
+	loaded()
+	if err != nil {
+		return nil, err
+	}
+	return value, lc.SetWithTTL(key, value, ttl)
+}
+
+// Stats returns the statistics of the LayeredCache
+func (lc *LayeredCache) Stats() TierStatistics {
+	return TierStatistics{
+		L1Hits:      atomic.LoadUint64(&lc.l1Hits),
+		L2Hits:      atomic.LoadUint64(&lc.l2Hits),
+		Misses:      atomic.LoadUint64(&lc.misses),
+		Promotions:  atomic.LoadUint64(&lc.promotions),
+		OriginLoads: atomic.LoadUint64(&lc.originLoads),
+	}
+}
+
+// GetManyDetailed retrieves multiple entries from the l1 cache or, for those that aren't there, from the l2 cache,
+// along with the cache they were retrieved from (see Cache.GetManyDetailed). The entries found in the l2 cache may be
+// promoted to the l1 cache, just like Get does. Every key is returned in the map, regardless of whether it exists or
+// not.
+func (lc *LayeredCache) GetManyDetailed(keys []string) map[string]GetResult {
+	results := lc.l1.GetManyDetailed(keys)
+	var missing []string
+	for key, result := range results {
+		if result.Found {
+			atomic.AddUint64(&lc.l1Hits, 1)
+			result.Tier = TierL1
+			results[key] = result
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return results
+	}
+	for key, result := range lc.l2.GetManyDetailed(missing) {
+		if !result.Found {
+			atomic.AddUint64(&lc.misses, 1)
+			continue
+		}
+		atomic.AddUint64(&lc.l2Hits, 1)
+		result.Tier = TierL2
+		results[key] = result
+		if lc.promote.ShouldPromote(key) {
+			lc.promoteToL1(key)
+		}
+	}
+	return results
+}
+
+// promoteToL1 copies an entry of the l2 cache to the l1 cache, keeping its expiration time and metadata
+//
+// If the entry is updated or deleted in the l2 cache while it is being promoted, the promoted copy is removed from the
+// l1 cache, since it may be outdated.
+func (lc *LayeredCache) promoteToL1(key string) {
+	storageKey := lc.l2.storageKey(key)
+	lc.l2.mutex.RLock()
+	entry, ok := lc.l2.get(storageKey)
+	var value interface{}
+	var expiration, updatedAt int64
+	var metadata map[string]string
+	if ok {
+		value = entry.Value
+		expiration = entry.Expiration
+		updatedAt = entry.updatedAt
+		metadata = entry.metadata
+	}
+	lc.l2.mutex.RUnlock()
+	if !ok {
+		return
+	}
+	ttl := time.Duration(NoExpiration)
+	if expiration != NoExpiration {
+		if ttl = time.Until(time.Unix(0, expiration)); ttl < 1 {
+			return
+		}
+	}
+	// If the l1 cache rejects the entry because it is full, the entry is simply not promoted
+	if lc.l1.setWithHooks(context.Background(), key, value, ttl, expiration, 0, metadata) != nil {
+		return
+	}
+	lc.l2.mutex.RLock()
+	current, ok := lc.l2.get(storageKey)
+	unchanged := ok && current == entry && current.updatedAt == updatedAt
+	lc.l2.mutex.RUnlock()
+	if !unchanged {
+		lc.l1.Delete(key)
+		return
+	}
+	atomic.AddUint64(&lc.promotions, 1)
+}
+
+// Delete removes a key from both caches
+//
+// Returns false if the key did not exist.
+func (lc *LayeredCache) Delete(key string) bool {
+	// Like SetWithTTL, the l2 cache goes first so that a concurrent promotion cannot bring the key back to l1
+	deletedFromL2 := lc.l2.Delete(key)
+	deletedFromL1 := lc.l1.Delete(key)
+	return deletedFromL1 || deletedFromL2
+}
+
+// Clear deletes all entries from both caches
+func (lc *LayeredCache) Clear() {
+	lc.l2.Clear()
+	lc.l1.Clear()
+}
@@ -0,0 +1,200 @@
+package gocache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLayeredCache(t *testing.T) {
+	lc := NewLayeredCache(NewCache(WithMaxSize(2)), NewCache(), PromoteAlways())
+	lc.SetWithTTL("key", "value", time.Hour)
+	if lc.L1().Count() != 0 || lc.L2().Count() != 1 {
+		t.Fatalf("expected the entry to only be in l2, got %d entries in l1 and %d in l2", lc.L1().Count(), lc.L2().Count())
+	}
+	if value, ok := lc.Get("key"); !ok || value != "value" {
+		t.Fatalf("expected value, got %v", value)
+	}
+	if value, ok := lc.L1().Get("key"); !ok || value != "value" {
+		t.Fatalf("expected the entry to have been promoted to l1, got %v", value)
+	}
+	if l1Entry, l2Entry := lc.L1().entries["key"], lc.L2().entries["key"]; l1Entry.Expiration != l2Entry.Expiration {
+		t.Errorf("expected the promoted entry to keep its expiration time, got %d in l1 and %d in l2", l1Entry.Expiration, l2Entry.Expiration)
+	}
+	// Updating the key must not leave the outdated value in l1
+	lc.Set("key", "updated")
+	if value, _ := lc.Get("key"); value != "updated" {
+		t.Errorf("expected updated, got %v", value)
+	}
+	if !lc.Delete("key") {
+		t.Error("expected the key to have been deleted")
+	}
+	if _, ok := lc.Get("key"); ok {
+		t.Error("expected the key not to exist in either cache")
+	}
+}
+
+func TestLayeredCache_PromoteEveryNth(t *testing.T) {
+	lc := NewLayeredCache(NewCache(), NewCache(), PromoteEveryNth(3))
+	lc.Set("1", "value")
+	lc.Set("2", "value")
+	lc.Set("3", "value")
+	lc.Get("1")
+	lc.Get("2")
+	if lc.L1().Count() != 0 {
+		t.Errorf("expected nothing to have been promoted yet, got %d entries in l1", lc.L1().Count())
+	}
+	lc.Get("3")
+	if _, ok := lc.L1().Get("3"); !ok {
+		t.Error("expected the third hit to have been promoted")
+	}
+}
+
+func TestLayeredCache_PromoteAfterHits(t *testing.T) {
+	lc := NewLayeredCache(NewCache(), NewCache(), PromoteAfterHits(2))
+	lc.Set("hot", "value")
+	lc.Set("cold", "value")
+	lc.Get("hot")
+	lc.Get("cold")
+	lc.Get("hot")
+	if _, ok := lc.L1().Get("hot"); !ok {
+		t.Error("expected hot to have been promoted after 2 hits")
+	}
+	if _, ok := lc.L1().Get("cold"); ok {
+		t.Error("expected cold not to have been promoted after a single hit")
+	}
+}
+
+func TestLayeredCache_PromotionPolicyFunc(t *testing.T) {
+	lc := NewLayeredCache(NewCache(), NewCache(), PromotionPolicyFunc(func(key string) bool {
+		return key == "promoted"
+	}))
+	lc.Set("promoted", "value")
+	lc.Set("other", "value")
+	lc.Get("promoted")
+	lc.Get("other")
+	if lc.L1().Count() != 1 {
+		t.Errorf("expected 1 entry in l1, got %d", lc.L1().Count())
+	}
+	lc.Clear()
+	if lc.L1().Count() != 0 || lc.L2().Count() != 0 {
+		t.Error("expected both caches to be empty")
+	}
+}
+
+func TestLayeredCache_GetManyDetailed(t *testing.T) {
+	lc := NewLayeredCache(NewCache(), NewCache(), PromoteAlways())
+	lc.SetWithTTL("promoted", "value", time.Hour)
+	lc.Get("promoted")
+	lc.Set("l2", "value")
+	results := lc.GetManyDetailed([]string{"promoted", "l2", "missing"})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if result := results["promoted"]; !result.Found || result.Tier != TierL1 || result.ExpiresAt.IsZero() {
+		t.Errorf("expected promoted to come from l1 with an expiration, got %+v", result)
+	}
+	if result := results["l2"]; !result.Found || result.Tier != TierL2 || result.Value != "value" {
+		t.Errorf("expected l2 to come from l2, got %+v", result)
+	}
+	if result := results["missing"]; result.Found || result.Tier != NoTier {
+		t.Errorf("expected missing not to have been found, got %+v", result)
+	}
+	if _, ok := lc.L1().Get("l2"); !ok {
+		t.Error("expected the entry found in l2 to have been promoted to l1")
+	}
+}
+
+func TestLayeredCache_Stats(t *testing.T) {
+	lc := NewLayeredCache(NewCache(), NewCache(), PromoteAlways())
+	lc.Set("key", "value")
+	lc.Get("key")
+	lc.Get("key")
+	lc.Get("missing")
+	lc.GetManyDetailed([]string{"key", "missing"})
+	stats := lc.Stats()
+	if stats.L1Hits != 2 || stats.L2Hits != 1 || stats.Misses != 2 || stats.Promotions != 1 {
+		t.Errorf("expected 2 l1 hits, 1 l2 hit, 2 misses and 1 promotion, got %+v", stats)
+	}
+	if ratio := stats.HitRatio(); ratio != 0.6 {
+		t.Errorf("expected a hit ratio of 0.6, got %v", ratio)
+	}
+	if ratio := (TierStatistics{}).HitRatio(); ratio != 0 {
+		t.Errorf("expected a hit ratio of 0 without lookups, got %v", ratio)
+	}
+}
+
+func TestLayeredCache_GetOrRefresh(t *testing.T) {
+	lc := NewLayeredCache(NewCache(), NewCache(), PromoteAlways())
+	loads := 0
+	refresh := func(key string) (interface{}, error) {
+		loads++
+		if key == "broken" {
+			return nil, errors.New("origin unavailable")
+		}
+		return "loaded", nil
+	}
+	for i := 0; i < 3; i++ {
+		if value, err := lc.GetOrRefresh("key", time.Hour, refresh); err != nil || value != "loaded" {
+			t.Fatalf("expected loaded, got %v (%v)", value, err)
+		}
+	}
+	if _, err := lc.GetOrRefresh("broken", time.Hour, refresh); err == nil {
+		t.Error("expected the error of the refresh function")
+	}
+	if loads != 2 {
+		t.Errorf("expected the origin to have been loaded from twice, got %d", loads)
+	}
+	stats := lc.Stats()
+	if stats.OriginLoads != 2 || stats.L1Hits != 1 || stats.L2Hits != 1 || stats.Misses != 2 {
+		t.Errorf("expected 2 origin loads, 1 l1 hit, 1 l2 hit and 2 misses, got %+v", stats)
+	}
+	if lc.L2().Count() != 1 {
+		t.Errorf("expected only the loaded value to have been cached, got %d entries", lc.L2().Count())
+	}
+}
+
+func TestLayeredCache_PromotionDoesNotResurrectOutdatedValues(t *testing.T) {
+	var lc *LayeredCache
+	lc = NewLayeredCache(NewCache(), NewCache(), PromotionPolicyFunc(func(key string) bool {
+		// The key is updated between the hit in l2 and the promotion
+		lc.Set(key, "new")
+		return true
+	}))
+	lc.Set("key", "old")
+	lc.Get("key")
+	if value, _ := lc.Get("key"); value != "new" {
+		t.Errorf("expected new, got %v", value)
+	}
+}
+
+func TestLayeredCache_ConcurrentSetAndGet(t *testing.T) {
+	lc := NewLayeredCache(NewCache(), NewCache(), PromoteAlways())
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					lc.Get("key")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 2000; i++ {
+		lc.Set("key", i)
+		// Only this goroutine writes, so the value it just set must never be hidden by a promotion of an older one
+		if value, _ := lc.Get("key"); value != i {
+			t.Errorf("expected %d, got %v", i, value)
+			break
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
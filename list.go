@@ -0,0 +1,182 @@
+package gocache
+
+import "container/list"
+
+// NoListMaxLength means that the lists created through LPush and RPush have no maximum number of elements
+const NoListMaxLength = 0
+
+// valueList is the value of the keys created through LPush and RPush
+type valueList struct {
+	elements *list.List
+
+	// cost is the approximate size of the elements in bytes
+	cost int
+}
+
+func newValueList() *valueList {
+	return &valueList{elements: list.New()}
+}
+
+// CacheCost returns the approximate size of the list in bytes
+func (l *valueList) CacheCost() int {
+	return l.cost
+}
+
+// Len returns the number of elements of the list
+func (l *valueList) Len() int {
+	return l.elements.Len()
+}
+
+// remove removes an element from the list and returns its value
+func (l *valueList) remove(element *list.Element) interface{} {
+	value := l.elements.Remove(element)
+	l.cost -= toBytes(value) + 16
+	return value
+}
+
+// WithListMaxLength sets the maximum number of elements of the lists created through LPush and RPush. Pushing
+// elements to a list that is already full trims the elements at the other end of the list, so that lists can be used
+// as bounded buffers of the most recent events, such as the last 100 actions of each user.
+//
+// A maxLength of 0 or less means that lists are unbounded (NoListMaxLength), which is the default.
+func WithListMaxLength(maxLength int) func(c *Cache) {
+	return func(c *Cache) {
+		if maxLength < 0 {
+			maxLength = NoListMaxLength
+		}
+		c.listMaxLength = maxLength
+	}
+}
+
+// ListMaxLength returns the maximum number of elements of the lists created through LPush and RPush
+func (c *Cache) ListMaxLength() int {
+	return c.listMaxLength
+}
+
+// LPush inserts values at the head of the list stored under a key, in the order they are passed, so that the last
+// one ends up at the head, creating the list without expiration if it doesn't exist. Returns the length of the list.
+//
+// If the list then has more elements than the ListMaxLength, the elements at its tail are removed.
+// The whole list is a single entry, so it expires and is evicted as a whole. Use Expire to set its TTL.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through LPush or RPush
+func (c *Cache) LPush(key string, values ...interface{}) (int, error) {
+	return c.push(key, values, true)
+}
+
+// RPush is the same as LPush, but it inserts values at the tail of the list, and removes the elements at its head if
+// the list has more elements than the ListMaxLength
+func (c *Cache) RPush(key string, values ...interface{}) (int, error) {
+	return c.push(key, values, false)
+}
+
+// push inserts values at the head or at the tail of a list, and trims the other end of the list if it is too long
+func (c *Cache) push(key string, values []interface{}, head bool) (int, error) {
+	length := 0
+	err := modifyCollection(c, key, newValueList, func(l *valueList) (bool, error) {
+		for _, value := range values {
+			if head {
+				l.elements.PushFront(value)
+			} else {
+				l.elements.PushBack(value)
+			}
+			l.cost += toBytes(value) + 16
+		}
+		for c.listMaxLength != NoListMaxLength && l.Len() > c.listMaxLength {
+			if head {
+				l.remove(l.elements.Back())
+			} else {
+				l.remove(l.elements.Front())
+			}
+		}
+		length = l.Len()
+		return len(values) > 0, nil
+	})
+	return length, err
+}
+
+// LPop removes and returns the element at the head of the list stored under a key
+// If the key doesn't exist or has expired, the value returned will be nil and the boolean will be false.
+// The key is deleted once the list is empty.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through LPush or RPush
+func (c *Cache) LPop(key string) (interface{}, bool, error) {
+	return c.pop(key, true)
+}
+
+// RPop is the same as LPop, but it removes and returns the element at the tail of the list
+func (c *Cache) RPop(key string) (interface{}, bool, error) {
+	return c.pop(key, false)
+}
+
+// pop removes and returns the element at the head or at the tail of a list
+func (c *Cache) pop(key string, head bool) (interface{}, bool, error) {
+	var value interface{}
+	popped := false
+	err := modifyCollection(c, key, nil, func(l *valueList) (bool, error) {
+		if head {
+			value = l.remove(l.elements.Front())
+		} else {
+			value = l.remove(l.elements.Back())
+		}
+		popped = true
+		return true, nil
+	})
+	return value, popped, err
+}
+
+// LRange retrieves the elements of the list stored under a key from the start index to the stop index (inclusive),
+// from head to tail. Like with Redis, negative indexes are counted from the tail, with -1 being the last element, so
+// LRange(key, 0, -1) retrieves the whole list.
+// If the key doesn't exist or has expired, the slice returned will be empty.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through LPush or RPush
+func (c *Cache) LRange(key string, start, stop int) ([]interface{}, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	l, ok, err := lookupCollection[*valueList](c, key)
+	if !ok {
+		return nil, err
+	}
+	length := l.Len()
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop {
+		return nil, nil
+	}
+	values := make([]interface{}, 0, stop-start+1)
+	element := l.elements.Front()
+	for i := 0; i < start; i++ {
+		element = element.Next()
+	}
+	for i := start; i <= stop; i++ {
+		values = append(values, element.Value)
+		element = element.Next()
+	}
+	return values, nil
+}
+
+// LLen returns the number of elements of the list stored under a key, or 0 if the key doesn't exist or has expired
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through LPush or RPush
+func (c *Cache) LLen(key string) (int, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	l, ok, err := lookupCollection[*valueList](c, key)
+	if !ok {
+		return 0, err
+	}
+	return l.Len(), nil
+}
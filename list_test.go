@@ -0,0 +1,108 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCache_LPushAndRPop(t *testing.T) {
+	cache := NewCache()
+	if length, err := cache.LPush("events", "a", "b"); err != nil || length != 2 {
+		t.Errorf("expected a length of 2, got %d (%v)", length, err)
+	}
+	if length, _ := cache.LPush("events", "c"); length != 3 {
+		t.Error("expected a length of 3, got", length)
+	}
+	if values, _ := cache.LRange("events", 0, -1); fmt.Sprint(values) != "[c b a]" {
+		t.Error("expected [c b a], got", values)
+	}
+	for _, expected := range []string{"a", "b", "c"} {
+		if value, ok, err := cache.RPop("events"); err != nil || !ok || value != expected {
+			t.Errorf("expected %s, got %v, %v (%v)", expected, value, ok, err)
+		}
+	}
+	if value, ok, err := cache.RPop("events"); err != nil || ok || value != nil {
+		t.Errorf("expected nothing to have been popped, got %v, %v (%v)", value, ok, err)
+	}
+	if cache.Count() != 0 {
+		t.Error("expected the key to have been deleted once the list was empty")
+	}
+}
+
+func TestCache_RPushAndLPop(t *testing.T) {
+	cache := NewCache()
+	cache.RPush("queue", 1, 2, 3)
+	if value, ok, _ := cache.LPop("queue"); !ok || value != 1 {
+		t.Error("expected 1, got", value)
+	}
+	if length, _ := cache.LLen("queue"); length != 2 {
+		t.Error("expected a length of 2, got", length)
+	}
+}
+
+func TestCache_LRange(t *testing.T) {
+	cache := NewCache()
+	cache.RPush("key", 0, 1, 2, 3, 4)
+	scenarios := []struct {
+		start, stop int
+		expected    string
+	}{
+		{0, -1, "[0 1 2 3 4]"},
+		{1, 2, "[1 2]"},
+		{-2, -1, "[3 4]"},
+		{-10, 1, "[0 1]"},
+		{3, 10, "[3 4]"},
+		{3, 1, "[]"},
+		{5, 10, "[]"},
+	}
+	for _, scenario := range scenarios {
+		t.Run(fmt.Sprintf("%d-%d", scenario.start, scenario.stop), func(t *testing.T) {
+			values, err := cache.LRange("key", scenario.start, scenario.stop)
+			if err != nil || fmt.Sprint(values) != scenario.expected {
+				t.Errorf("expected %s, got %v (%v)", scenario.expected, values, err)
+			}
+		})
+	}
+	if values, err := cache.LRange("missing", 0, -1); err != nil || len(values) != 0 {
+		t.Errorf("expected no values, got %v (%v)", values, err)
+	}
+}
+
+func TestCache_WithListMaxLength(t *testing.T) {
+	cache := NewCache(WithListMaxLength(3), WithMaxMemoryUsage(Megabyte))
+	if cache.ListMaxLength() != 3 {
+		t.Error("expected the list max length to be 3, got", cache.ListMaxLength())
+	}
+	for i := 0; i < 10; i++ {
+		if length, _ := cache.LPush("recent", i); length > 3 {
+			t.Fatal("expected the list to have been trimmed, got a length of", length)
+		}
+	}
+	if values, _ := cache.LRange("recent", 0, -1); fmt.Sprint(values) != "[9 8 7]" {
+		t.Error("expected the oldest elements to have been trimmed, got", values)
+	}
+	cache.RPush("recent", 10)
+	if values, _ := cache.LRange("recent", 0, -1); fmt.Sprint(values) != "[8 7 10]" {
+		t.Error("expected the element at the head to have been trimmed, got", values)
+	}
+	if expected := toBytes("recent") + 3*(toBytes(0)+16) + 32; cache.MemoryUsage() != expected {
+		t.Errorf("expected the memory usage to be %d, got %d", expected, cache.MemoryUsage())
+	}
+}
+
+func TestCache_LPushWithWrongType(t *testing.T) {
+	cache := NewCache()
+	cache.HSet("key", "field", "value")
+	if _, err := cache.LPush("key", "a"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, _, err := cache.RPop("key"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.LRange("key", 0, -1); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.LLen("key"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+}
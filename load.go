@@ -0,0 +1,66 @@
+package gocache
+
+import (
+	"sort"
+	"time"
+)
+
+// LoadLatencySamples is the number of most recent loads the load latency percentiles of Statistics are computed from
+const LoadLatencySamples = 1024
+
+// LoadStarted records that a loader started loading a value after a miss, and returns a function that must be called
+// exactly once when the load has completed, whether it succeeded or not.
+//
+// GetOrRefresh already reports its loads, so this is only meant to be used by loaders built on top of the cache,
+// such as the memoize package, so that the effectiveness of their stampede protection shows in the Statistics.
+func (c *Cache) LoadStarted() (completed func()) {
+	start := time.Now()
+	c.mutex.Lock()
+	c.stats.LoadsInFlight++
+	c.mutex.Unlock()
+	return func() {
+		latency := time.Since(start)
+		c.mutex.Lock()
+		c.stats.LoadsInFlight--
+		c.recordLoadLatency(latency)
+		c.mutex.Unlock()
+	}
+}
+
+// recordLoadLatency records a completed load and its latency
+//
+// The caller must hold the lock.
+func (c *Cache) recordLoadLatency(latency time.Duration) {
+	if len(c.loadLatencies) < LoadLatencySamples {
+		c.loadLatencies = append(c.loadLatencies, latency)
+	} else {
+		c.loadLatencies[c.stats.Loads%LoadLatencySamples] = latency
+	}
+	c.stats.Loads++
+}
+
+// LoadCoalesced records that a caller waited for a load already in flight for the same key instead of starting its
+// own, which is what stampede protection is meant to achieve
+func (c *Cache) LoadCoalesced() {
+	c.mutex.Lock()
+	c.stats.CoalescedLoads++
+	c.mutex.Unlock()
+}
+
+// loadLatencyPercentiles returns the 50th, 90th and 99th percentiles of the latencies of the last loads
+//
+// The caller must hold the lock.
+func (c *Cache) loadLatencyPercentiles() (p50, p90, p99 time.Duration) {
+	if len(c.loadLatencies) == 0 {
+		return 0, 0, 0
+	}
+	latencies := make([]time.Duration, len(c.loadLatencies))
+	copy(latencies, c.loadLatencies)
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	percentile := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+	return percentile(0.50), percentile(0.90), percentile(0.99)
+}
@@ -0,0 +1,56 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_LoadStarted(t *testing.T) {
+	cache := NewCache()
+	completed := cache.LoadStarted()
+	if stats := cache.Stats(); stats.LoadsInFlight != 1 || stats.Loads != 0 {
+		t.Errorf("expected 1 load in flight and no load completed, got %d and %d", stats.LoadsInFlight, stats.Loads)
+	}
+	completed()
+	cache.LoadCoalesced()
+	stats := cache.Stats()
+	if stats.LoadsInFlight != 0 || stats.Loads != 1 || stats.CoalescedLoads != 1 {
+		t.Errorf("expected no load in flight, 1 load and 1 coalesced load, got %d, %d and %d", stats.LoadsInFlight, stats.Loads, stats.CoalescedLoads)
+	}
+}
+
+func TestCache_LoadLatencyPercentiles(t *testing.T) {
+	cache := NewCache()
+	// Only the last LoadLatencySamples latencies count, so the slow loads recorded first must not matter
+	for i := 0; i < 10; i++ {
+		cache.recordLoadLatency(time.Hour)
+	}
+	for i := 1; i <= LoadLatencySamples; i++ {
+		cache.recordLoadLatency(time.Duration(i) * time.Millisecond)
+	}
+	stats := cache.Stats()
+	if stats.Loads != LoadLatencySamples+10 {
+		t.Errorf("expected %d loads, got %d", LoadLatencySamples+10, stats.Loads)
+	}
+	if stats.LoadLatencyP50 < 500*time.Millisecond || stats.LoadLatencyP50 > 525*time.Millisecond {
+		t.Errorf("expected a median of about 512ms, got %s", stats.LoadLatencyP50)
+	}
+	if stats.LoadLatencyP99 < 1000*time.Millisecond || stats.LoadLatencyP99 > LoadLatencySamples*time.Millisecond {
+		t.Errorf("expected a 99th percentile of about 1014ms, got %s", stats.LoadLatencyP99)
+	}
+}
+
+func TestCache_GetOrRefreshReportsLoads(t *testing.T) {
+	cache := NewCache()
+	cache.GetOrRefresh("key", time.Hour, func(string) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		return "value", nil
+	})
+	cache.GetOrRefresh("key", time.Hour, func(string) (interface{}, error) {
+		t.Error("expected the cached value to be returned")
+		return nil, nil
+	})
+	if stats := cache.Stats(); stats.Loads != 1 || stats.LoadLatencyP50 < time.Millisecond {
+		t.Errorf("expected 1 load of at least 1ms, got %d loads with a median of %s", stats.Loads, stats.LoadLatencyP50)
+	}
+}
@@ -0,0 +1,127 @@
+package gocache
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// lockRetryMinInterval is how long TryGet and TrySet wait before trying to acquire the lock again the first time
+	lockRetryMinInterval = 10 * time.Microsecond
+
+	// lockRetryMaxInterval is the maximum duration TryGet and TrySet wait between two attempts to acquire the lock
+	lockRetryMaxInterval = time.Millisecond
+)
+
+// WithLockTimeout sets how long TryGet and TrySet wait for the lock of the cache before giving up with
+// ErrLockTimeout, which allows latency-critical callers to degrade gracefully rather than block while the lock is
+// held for a long time, such as during a GetAll on a large cache. The other functions are not affected.
+//
+// A timeout of 0 or less means that TryGet and TrySet give up immediately if the lock is held, which is the default.
+func WithLockTimeout(timeout time.Duration) func(c *Cache) {
+	return func(c *Cache) {
+		if timeout < 0 {
+			timeout = 0
+		}
+		c.lockTimeout = timeout
+	}
+}
+
+// LockTimeout returns how long TryGet and TrySet wait for the lock of the cache before giving up
+func (c *Cache) LockTimeout() time.Duration {
+	return c.lockTimeout
+}
+
+// TryGet is the same as Get, but it returns ErrLockTimeout instead of blocking if the lock of the cache cannot be
+// acquired within the LockTimeout, in which case the value returned will be nil and the boolean will be false.
+func (c *Cache) TryGet(key string) (interface{}, bool, error) {
+	if c.neverStored(key) {
+		if !c.tryLock() {
+			return nil, false, ErrLockTimeout
+		}
+		c.countMiss()
+		c.mutex.Unlock()
+		return nil, false, nil
+	}
+	key = c.storageKey(key)
+	defer c.endOp(OpGet, key, c.startOp())
+	if c.hooks != nil {
+		c.hooks.BeforeGet(key)
+	}
+	if !c.tryLock() {
+		// Like TrySet, the hooks are still invoked in pairs, so the timeout is reported to AfterGet as a miss, but it
+		// isn't recorded in the audit log
+		if c.hooks != nil {
+			c.hooks.AfterGet(key, nil, false)
+		}
+		return nil, false, ErrLockTimeout
+	}
+	value, ok := c.lookup(key)
+	c.mutex.Unlock()
+	c.afterGet(context.Background(), key, value, ok)
+	return value, ok, nil
+}
+
+// TrySet is the same as SetWithTTL, but it returns ErrLockTimeout instead of blocking if the lock of the cache cannot
+// be acquired within the LockTimeout, in which case the value is not cached.
+func (c *Cache) TrySet(key string, value interface{}, ttl time.Duration) error {
+	storageKey, err := c.writeKey(key)
+	if err != nil {
+		if err == errNeverCached {
+			return nil
+		}
+		return err
+	}
+	callerKey, key := key, storageKey
+	value = c.normalizeNil(value)
+	if c.hooks != nil {
+		c.hooks.BeforeSet(key, value, ttl)
+	}
+	err = ErrLockTimeout
+	if c.tryLock() {
+		err = c.setLocked(callerKey, key, value, expirationOf(ttl), 0, nil)
+		c.mutex.Unlock()
+	}
+	if c.hooks != nil {
+		c.hooks.AfterSet(key, value, ttl, err)
+	}
+	if err == nil && c.audits(OpSet) {
+		record := AuditRecord{Key: key, Size: toBytes(value)}
+		if ttl != NoExpiration {
+			record.TTL = ttl.String()
+		}
+		c.audit(context.Background(), OpSet, record)
+	}
+	return err
+}
+
+// tryLock acquires the lock of the cache, waiting for at most the LockTimeout, and returns whether it was acquired
+//
+// Since the lock cannot be waited for with a deadline, acquiring it is attempted repeatedly, with an exponential
+// backoff between attempts.
+func (c *Cache) tryLock() bool {
+	if c.mutex.TryLock() {
+		return true
+	}
+	if c.lockTimeout <= 0 {
+		return false
+	}
+	deadline := time.Now().Add(c.lockTimeout)
+	interval := lockRetryMinInterval
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+		if c.mutex.TryLock() {
+			return true
+		}
+		if interval *= 2; interval > lockRetryMaxInterval {
+			interval = lockRetryMaxInterval
+		}
+	}
+}
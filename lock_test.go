@@ -0,0 +1,98 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_TryGet(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	if value, ok, err := cache.TryGet("key"); err != nil || !ok || value != "value" {
+		t.Errorf("expected value, got %v, %v (%v)", value, ok, err)
+	}
+	if _, ok, err := cache.TryGet("missing"); err != nil || ok {
+		t.Errorf("expected a miss, got %v (%v)", ok, err)
+	}
+	cache.mutex.RLock()
+	start := time.Now()
+	if value, ok, err := cache.TryGet("key"); err != ErrLockTimeout || ok || value != nil {
+		t.Errorf("expected ErrLockTimeout, got %v, %v (%v)", value, ok, err)
+	}
+	cache.mutex.RUnlock()
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Error("expected TryGet to have given up immediately, took", elapsed)
+	}
+}
+
+func TestCache_TryGetWithHooksWhenLockTimesOut(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithHooks(hooks))
+	cache.mutex.Lock()
+	_, _, err := cache.TryGet("key")
+	cache.mutex.Unlock()
+	if err != ErrLockTimeout {
+		t.Error("expected ErrLockTimeout, got", err)
+	}
+	expected := "BeforeGet key,AfterGet key=<nil> found=false"
+	if events := strings.Join(hooks.events, ","); events != expected {
+		t.Errorf("expected %s, got %s", expected, events)
+	}
+}
+
+func TestCache_TryGetWithKeysThatAreNeverStored(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithHooks(hooks), WithKeyObfuscation([]byte("secret")), WithNeverCachePatterns("session:*"), WithMaxKeyLength(10))
+	for _, key := range []string{"session:1", strings.Repeat("a", 11)} {
+		if value, ok, err := cache.TryGet(key); err != nil || ok || value != nil {
+			t.Errorf("expected %s to miss, got %v, %v (%v)", key, value, ok, err)
+		}
+	}
+	if misses := cache.Stats().Misses; misses != 2 {
+		t.Error("expected 2 misses, got", misses)
+	}
+	if len(hooks.events) != 0 {
+		t.Errorf("expected the hooks not to have been invoked, got %v", hooks.events)
+	}
+}
+
+func TestCache_TrySet(t *testing.T) {
+	cache := NewCache()
+	if err := cache.TrySet("key", "value", time.Hour); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	if ttl, _ := cache.TTL("key"); ttl <= 0 {
+		t.Error("expected the key to have a TTL, got", ttl)
+	}
+	cache.mutex.Lock()
+	err := cache.TrySet("key", "new-value", time.Hour)
+	cache.mutex.Unlock()
+	if err != ErrLockTimeout {
+		t.Error("expected ErrLockTimeout, got", err)
+	}
+	if value, _ := cache.Get("key"); value != "value" {
+		t.Error("expected the value not to have been updated, got", value)
+	}
+}
+
+func TestCache_WithLockTimeout(t *testing.T) {
+	cache := NewCache(WithLockTimeout(30 * time.Millisecond))
+	if cache.LockTimeout() != 30*time.Millisecond {
+		t.Error("expected the lock timeout to be 30ms, got", cache.LockTimeout())
+	}
+	cache.Set("key", "value")
+	cache.mutex.Lock()
+	start := time.Now()
+	_, _, err := cache.TryGet("key")
+	if elapsed := time.Since(start); err != ErrLockTimeout || elapsed < 30*time.Millisecond {
+		t.Errorf("expected ErrLockTimeout after the lock timeout, got %v after %s", err, elapsed)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cache.mutex.Unlock()
+	}()
+	if value, ok, err := cache.TryGet("key"); err != nil || !ok || value != "value" {
+		t.Errorf("expected the lock to have been acquired once released, got %v, %v (%v)", value, ok, err)
+	}
+}
@@ -0,0 +1,149 @@
+package gocache
+
+import "container/heap"
+
+const (
+	// DefaultK is the K used by the LRUK eviction policy if WithK is not used
+	DefaultK = 2
+
+	// MaxK is the maximum K supported by the LRUK eviction policy, which bounds the access history kept per entry
+	MaxK = 16
+)
+
+// WithK sets the number of accesses tracked per entry by the LRUK eviction policy. K is clamped between 1 and MaxK,
+// and 1 makes LRUK equivalent to LeastRecentlyUsed.
+// Defaults to DefaultK
+func WithK(k int) func(c *Cache) {
+	return func(c *Cache) {
+		if k < 1 {
+			k = 1
+		} else if k > MaxK {
+			k = MaxK
+		}
+		c.k = k
+	}
+}
+
+// K returns the number of accesses tracked per entry by the LRUK eviction policy
+func (c *Cache) K() int {
+	return c.k
+}
+
+// accessHistory contains the last K access times of an entry, which the LRUK eviction policy orders entries by
+type accessHistory struct {
+	// times is a ring buffer of the logical times of the last K accesses
+	times []uint64
+
+	// count is the total number of accesses, which is used to find the position of the next access in times
+	count int
+
+	// index is the position of the entry in the lrukHeap
+	index int
+}
+
+// record records an access at the given logical time
+func (history *accessHistory) record(now uint64) {
+	history.times[history.count%len(history.times)] = now
+	history.count++
+}
+
+// kthAccess returns the logical time of the K-th most recent access, or 0 if there were fewer than K accesses,
+// meaning that entries that weren't accessed K times yet are the first to be evicted
+func (history *accessHistory) kthAccess() uint64 {
+	if history.count < len(history.times) {
+		return 0
+	}
+	return history.times[history.count%len(history.times)]
+}
+
+// lastAccess returns the logical time of the most recent access
+func (history *accessHistory) lastAccess() uint64 {
+	return history.times[(history.count-1)%len(history.times)]
+}
+
+// lrukHeap is a min-heap of entries ordered by the time of their K-th most recent access, and then by the time of
+// their most recent access, so that the root is the entry to evict under the LRUK eviction policy
+type lrukHeap []*Entry
+
+func (h lrukHeap) Len() int {
+	return len(h)
+}
+
+func (h lrukHeap) Less(i, j int) bool {
+	iKth, jKth := h[i].history.kthAccess(), h[j].history.kthAccess()
+	if iKth != jKth {
+		return iKth < jKth
+	}
+	return h[i].history.lastAccess() < h[j].history.lastAccess()
+}
+
+func (h lrukHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].history.index = i
+	h[j].history.index = j
+}
+
+func (h *lrukHeap) Push(x interface{}) {
+	entry := x.(*Entry)
+	entry.history.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *lrukHeap) Pop() interface{} {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// recordAccess records an access to the entry passed as parameter and updates its position in the lrukHeap, adding
+// it to the heap if it isn't in it yet. The caller must hold the lock.
+//
+// Accesses are timestamped with a logical clock rather than the wall clock, which is both cheaper and guarantees that
+// no two accesses happen at the same time.
+func (c *Cache) recordAccess(entry *Entry) {
+	c.lrukClock++
+	now := c.lrukClock
+	if entry.history == nil {
+		entry.history = &accessHistory{times: make([]uint64, c.k)}
+		entry.history.record(now)
+		heap.Push(&c.lruk, entry)
+		return
+	}
+	entry.history.record(now)
+	heap.Fix(&c.lruk, entry.history.index)
+}
+
+// removeFromLRUKHeap removes the entry passed as parameter from the lrukHeap. The caller must hold the lock.
+func (c *Cache) removeFromLRUKHeap(entry *Entry) {
+	if entry.history != nil {
+		heap.Remove(&c.lruk, entry.history.index)
+		entry.history = nil
+	}
+}
+
+// nextLRUKVictim returns the entry to evict under the LRUK eviction policy, skipping the entries that cannot be
+// evicted at the given unix time in nanoseconds, or nil if no entry can be evicted. The caller must hold the lock.
+func (c *Cache) nextLRUKVictim(now int64) *Entry {
+	if len(c.lruk) == 0 {
+		return nil
+	}
+	if c.evictable(c.lruk[0], now) {
+		return c.lruk[0]
+	}
+	// Entries that cannot be evicted are popped until one that can is found, and then pushed back
+	skipped := []*Entry{heap.Pop(&c.lruk).(*Entry)}
+	var victim *Entry
+	for len(c.lruk) > 0 {
+		if c.evictable(c.lruk[0], now) {
+			victim = c.lruk[0]
+			break
+		}
+		skipped = append(skipped, heap.Pop(&c.lruk).(*Entry))
+	}
+	for _, entry := range skipped {
+		heap.Push(&c.lruk, entry)
+	}
+	return victim
+}
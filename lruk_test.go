@@ -0,0 +1,83 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCache_LRUKResistsScans(t *testing.T) {
+	cache := NewCache(WithMaxSize(3), WithEvictionPolicy(LRUK), WithK(2), WithRaceAssertions(true))
+	cache.Set("hot1", "value")
+	cache.Set("hot2", "value")
+	cache.Get("hot1")
+	cache.Get("hot2")
+	// A scan of keys that are each accessed only once must not evict the keys accessed regularly
+	for n := 0; n < 10; n++ {
+		cache.Set(fmt.Sprintf("scan-%d", n), "value")
+	}
+	if _, ok := cache.Get("hot1"); !ok {
+		t.Error("expected hot1 not to have been evicted by the scan")
+	}
+	if _, ok := cache.Get("hot2"); !ok {
+		t.Error("expected hot2 not to have been evicted by the scan")
+	}
+	if cache.Count() != 3 {
+		t.Errorf("expected 3 entries, got %d", cache.Count())
+	}
+	if cache.Stats().EvictedKeys != 9 {
+		t.Errorf("expected 9 evictions, got %d", cache.Stats().EvictedKeys)
+	}
+}
+
+func TestCache_LRUKEvictsOldestKthAccess(t *testing.T) {
+	cache := NewCache(WithMaxSize(2), WithEvictionPolicy(LRUK), WithK(2), WithRaceAssertions(true))
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	cache.Get("1")
+	cache.Get("2")
+	cache.Get("2")
+	cache.Get("1")
+	// Although 1 was accessed last, its second most recent access is older than 2's, so it is the one evicted
+	cache.Set("3", "value")
+	if _, ok := cache.Get("1"); ok {
+		t.Error("expected 1 to have been evicted")
+	}
+	if _, ok := cache.Get("2"); !ok {
+		t.Error("expected 2 not to have been evicted")
+	}
+}
+
+func TestCache_LRUKWithKOfOne(t *testing.T) {
+	cache := NewCache(WithMaxSize(2), WithEvictionPolicy(LRUK), WithK(0), WithRaceAssertions(true))
+	if cache.K() != 1 {
+		t.Fatalf("expected K to be clamped to 1, got %d", cache.K())
+	}
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	cache.Get("1")
+	cache.Set("3", "value")
+	if _, ok := cache.Get("2"); ok {
+		t.Error("expected 2 to have been evicted, since it is the least recently used")
+	}
+	if NewCache(WithK(100)).K() != MaxK {
+		t.Error("expected K to be clamped to MaxK")
+	}
+}
+
+func TestCache_LRUKWithDeletesAndClear(t *testing.T) {
+	cache := NewCache(WithMaxSize(10), WithEvictionPolicy(LRUK), WithRaceAssertions(true))
+	for n := 0; n < 10; n++ {
+		cache.Set(fmt.Sprint(n), "value")
+	}
+	cache.Delete("3")
+	cache.Take("4")
+	cache.SetWithTTL("5", "value", 0)
+	if len(cache.lruk) != 7 {
+		t.Errorf("expected deleted entries to have been removed from the heap, got %d entries in it", len(cache.lruk))
+	}
+	cache.Clear()
+	cache.Set("new", "value")
+	if len(cache.lruk) != 1 {
+		t.Errorf("expected the heap to have been reset by Clear, got %d entries in it", len(cache.lruk))
+	}
+}
@@ -0,0 +1,227 @@
+// Package memoize memoizes arbitrary functions in a gocache.Cache, keyed by their serialized arguments.
+//
+// Concurrent calls with the same arguments are coalesced, so that the underlying function is only called once even
+// if many goroutines miss the cache at the same time.
+//
+// Arguments are encoded into the key with FmtEncoder by default, which can be changed with WithEncoder, and arguments
+// implementing Keyer are always encoded with their MemoKey method. If the underlying function panics, the panic is
+// recovered and returned as a *PanicError to every caller waiting for it, and it is only cached if WithPanicCaching
+// is used.
+//
+// Usage:
+//
+//	getUser := memoize.CachedFunc(cache, func(id int) (*User, error) {
+//		return db.GetUser(id)
+//	}, time.Minute)
+//	user, err := getUser(42)
+package memoize
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+const (
+	// KeyPrefix is the prefix of the keys used to store results in the cache
+	KeyPrefix = "memoize:"
+)
+
+// lastFunctionID is used to give every memoized function its own namespace in the cache
+var lastFunctionID uint64
+
+// Keyer is implemented by arguments that encode themselves into the key of the results they produce, such as
+// arguments whose identity is a subset of their fields, regardless of the Encoder used
+type Keyer interface {
+	MemoKey() string
+}
+
+// Encoder encodes an argument into the key of the results it produces, which must be the same for equal arguments
+type Encoder func(arg interface{}) (string, error)
+
+// FmtEncoder encodes arguments with the %#v verb of fmt, which is the default. Note that pointers are encoded as
+// their address rather than as the value they point to.
+func FmtEncoder(arg interface{}) (string, error) {
+	return fmt.Sprintf("%#v", arg), nil
+}
+
+// JSONEncoder encodes arguments as JSON, which encodes the value pointers point to and the keys of maps in order,
+// but ignores unexported fields
+func JSONEncoder(arg interface{}) (string, error) {
+	encoded, err := json.Marshal(arg)
+	return string(encoded), err
+}
+
+// PanicError is the error returned when the memoized function panics
+type PanicError struct {
+	// Value is the value the function panicked with
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine that panicked
+	Stack []byte
+}
+
+// Error returns the value the function panicked with
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("memoize: function panicked: %v", e.Value)
+}
+
+// Option is an option for CachedFunc and CachedFunc2
+type Option func(options *options)
+
+type options struct {
+	encoder         Encoder
+	panicCachingTTL time.Duration
+}
+
+// WithEncoder sets the Encoder used to encode the arguments that don't implement Keyer. Defaults to FmtEncoder
+func WithEncoder(encoder Encoder) Option {
+	return func(options *options) {
+		options.encoder = encoder
+	}
+}
+
+// WithPanicCaching makes the *PanicError returned when the memoized function panics be cached for the TTL passed as
+// parameter, so that a function which panics for some arguments is not called again with them until the TTL expires.
+// By default, panics are not cached, just like errors.
+func WithPanicCaching(ttl time.Duration) Option {
+	return func(options *options) {
+		options.panicCachingTTL = ttl
+	}
+}
+
+// CachedFunc returns a function which returns the result of fn for the given argument from the cache if possible, and
+// calls fn and caches its result for the given TTL otherwise. Errors are never cached.
+//
+// If the argument cannot be encoded, fn is called without using the cache.
+func CachedFunc[A, R any](cache *gocache.Cache, fn func(A) (R, error), ttl time.Duration, opts ...Option) func(A) (R, error) {
+	m := newMemoizer[R](cache, ttl, opts)
+	return func(a A) (R, error) {
+		return m.call(func() (R, error) {
+			return fn(a)
+		}, a)
+	}
+}
+
+// CachedFunc2 is the same as CachedFunc, but for functions taking two arguments
+func CachedFunc2[A, B, R any](cache *gocache.Cache, fn func(A, B) (R, error), ttl time.Duration, opts ...Option) func(A, B) (R, error) {
+	m := newMemoizer[R](cache, ttl, opts)
+	return func(a A, b B) (R, error) {
+		return m.call(func() (R, error) {
+			return fn(a, b)
+		}, a, b)
+	}
+}
+
+// memoizer caches the results of a single function
+type memoizer[R any] struct {
+	cache   *gocache.Cache
+	ttl     time.Duration
+	prefix  string
+	options options
+
+	// mutex guards calls
+	mutex sync.Mutex
+
+	// calls are the calls currently in flight, by key
+	calls map[string]*call[R]
+}
+
+// call is a call in flight, which other callers with the same key wait for
+type call[R any] struct {
+	done   chan struct{}
+	result R
+	err    error
+}
+
+func newMemoizer[R any](cache *gocache.Cache, ttl time.Duration, opts []Option) *memoizer[R] {
+	m := &memoizer[R]{
+		cache:   cache,
+		ttl:     ttl,
+		prefix:  fmt.Sprintf("%s%d:", KeyPrefix, atomic.AddUint64(&lastFunctionID, 1)),
+		options: options{encoder: FmtEncoder},
+		calls:   make(map[string]*call[R]),
+	}
+	for _, opt := range opts {
+		opt(&m.options)
+	}
+	return m
+}
+
+// key encodes the arguments into the key of the results they produce
+func (m *memoizer[R]) key(args ...interface{}) (string, error) {
+	key := m.prefix
+	for i, arg := range args {
+		if i > 0 {
+			key += "\x00"
+		}
+		if keyer, ok := arg.(Keyer); ok {
+			key += keyer.MemoKey()
+			continue
+		}
+		encoded, err := m.options.encoder(arg)
+		if err != nil {
+			return "", err
+		}
+		key += encoded
+	}
+	return key, nil
+}
+
+// call returns the cached result for the given arguments, or calls fn to compute it
+func (m *memoizer[R]) call(fn func() (R, error), args ...interface{}) (R, error) {
+	key, err := m.key(args...)
+	if err != nil {
+		return safeCall(fn)
+	}
+	if value, ok := m.cache.Get(key); ok {
+		if panicErr, ok := value.(*PanicError); ok {
+			var zero R
+			return zero, panicErr
+		}
+		// A nil pointer or interface returned by fn is cached as a nil interface, which is not an R
+		result, _ := value.(R)
+		return result, nil
+	}
+	m.mutex.Lock()
+	if inFlight, ok := m.calls[key]; ok {
+		m.mutex.Unlock()
+		m.cache.LoadCoalesced()
+		<-inFlight.done
+		return inFlight.result, inFlight.err
+	}
+	c := &call[R]{done: make(chan struct{})}
+	m.calls[key] = c
+	m.mutex.Unlock()
+	defer func() {
+		m.mutex.Lock()
+		delete(m.calls, key)
+		m.mutex.Unlock()
+		close(c.done)
+	}()
+	loaded := m.cache.LoadStarted()
+	c.result, c.err = safeCall(fn)
+	loaded()
+	if c.err == nil {
+		_ = m.cache.SetWithTTL(key, c.result, m.ttl)
+	} else if panicErr, ok := c.err.(*PanicError); ok && m.options.panicCachingTTL > 0 {
+		_ = m.cache.SetWithTTL(key, panicErr, m.options.panicCachingTTL)
+	}
+	return c.result, c.err
+}
+
+// safeCall calls fn, and returns a *PanicError if it panics
+func safeCall[R any](fn func() (R, error)) (result R, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			var zero R
+			result, err = zero, &PanicError{Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
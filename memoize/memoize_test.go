@@ -0,0 +1,221 @@
+package memoize
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+func TestCachedFunc(t *testing.T) {
+	calls := 0
+	double := CachedFunc(gocache.NewCache(), func(n int) (int, error) {
+		calls++
+		return n * 2, nil
+	}, time.Hour)
+	for i := 0; i < 3; i++ {
+		if result, err := double(21); err != nil || result != 42 {
+			t.Errorf("expected 42, got %d and %v", result, err)
+		}
+	}
+	if result, _ := double(1); result != 2 {
+		t.Errorf("expected 2, got %d", result)
+	}
+	if calls != 2 {
+		t.Errorf("expected the function to have been called once per distinct argument, got %d calls", calls)
+	}
+}
+
+func TestCachedFuncWithNilResult(t *testing.T) {
+	type user struct{}
+	calls := 0
+	find := CachedFunc(gocache.NewCache(), func(id int) (*user, error) {
+		calls++
+		return nil, nil
+	}, time.Hour)
+	for i := 0; i < 3; i++ {
+		if result, err := find(1); err != nil || result != nil {
+			t.Errorf("expected a nil result, got %v and %v", result, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the nil result to have been cached, got %d calls", calls)
+	}
+}
+
+func TestCachedFuncDoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	fn := CachedFunc(gocache.NewCache(), func(n int) (int, error) {
+		calls++
+		return 0, errors.New("failed")
+	}, time.Hour)
+	fn(1)
+	if _, err := fn(1); err == nil {
+		t.Error("expected an error")
+	}
+	if calls != 2 {
+		t.Errorf("expected errors not to be cached, got %d calls", calls)
+	}
+}
+
+func TestCachedFuncCoalescesConcurrentCalls(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	cache := gocache.NewCache()
+	fn := CachedFunc(cache, func(key string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return key, nil
+	}, time.Hour)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := fn("key"); err != nil || result != "key" {
+				t.Errorf("expected key, got %s and %v", result, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("expected concurrent calls to be coalesced into one, got %d calls", calls)
+	}
+	if stats := cache.Stats(); stats.Loads != 1 || stats.CoalescedLoads != 9 || stats.LoadsInFlight != 0 {
+		t.Errorf("expected 1 load, 9 coalesced loads and no load in flight, got %d, %d and %d", stats.Loads, stats.CoalescedLoads, stats.LoadsInFlight)
+	}
+}
+
+func TestCachedFunc2(t *testing.T) {
+	cache := gocache.NewCache()
+	calls := 0
+	add := CachedFunc2(cache, func(a, b int) (int, error) {
+		calls++
+		return a + b, nil
+	}, time.Hour)
+	// A second memoized function sharing the same cache must not return the results of the first
+	subtract := CachedFunc2(cache, func(a, b int) (int, error) {
+		return a - b, nil
+	}, time.Hour)
+	add(1, 2)
+	if result, _ := add(1, 2); result != 3 {
+		t.Errorf("expected 3, got %d", result)
+	}
+	if result, _ := add(2, 1); result != 3 {
+		t.Errorf("expected 3, got %d", result)
+	}
+	if result, _ := subtract(1, 2); result != -1 {
+		t.Errorf("expected -1, got %d", result)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+type user struct {
+	ID   int
+	Name string
+}
+
+func (u user) MemoKey() string {
+	return fmt.Sprint(u.ID)
+}
+
+func TestCachedFuncWithKeyer(t *testing.T) {
+	calls := 0
+	fn := CachedFunc(gocache.NewCache(), func(u user) (string, error) {
+		calls++
+		return u.Name, nil
+	}, time.Hour)
+	fn(user{ID: 1, Name: "john"})
+	if name, _ := fn(user{ID: 1, Name: "jane"}); name != "john" || calls != 1 {
+		t.Errorf("expected arguments with the same MemoKey to share their result, got %s after %d calls", name, calls)
+	}
+}
+
+func TestCachedFuncWithJSONEncoder(t *testing.T) {
+	calls := 0
+	fn := CachedFunc(gocache.NewCache(), func(n *int) (int, error) {
+		calls++
+		return *n, nil
+	}, time.Hour, WithEncoder(JSONEncoder))
+	a, b := 42, 42
+	fn(&a)
+	if result, _ := fn(&b); result != 42 || calls != 1 {
+		t.Errorf("expected pointers to equal values to share their result, got %d after %d calls", result, calls)
+	}
+}
+
+func TestCachedFuncWithEncoderError(t *testing.T) {
+	calls := 0
+	fn := CachedFunc(gocache.NewCache(), func(c chan int) (int, error) {
+		calls++
+		return 1, nil
+	}, time.Hour, WithEncoder(JSONEncoder))
+	ch := make(chan int)
+	for i := 0; i < 2; i++ {
+		if result, err := fn(ch); err != nil || result != 1 {
+			t.Errorf("expected the function to have been called, got %d and %v", result, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected arguments that cannot be encoded to bypass the cache, got %d calls", calls)
+	}
+}
+
+func TestCachedFuncRecoversPanics(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	fn := CachedFunc(gocache.NewCache(), func(n int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		panic("boom")
+	}, time.Hour)
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := fn(1)
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+			t.Error("expected every caller to get a PanicError, got", err)
+		}
+	}
+	fn(1)
+	if calls != 2 {
+		t.Errorf("expected panics not to be cached by default, got %d calls", calls)
+	}
+}
+
+func TestCachedFuncWithPanicCaching(t *testing.T) {
+	calls := 0
+	fn := CachedFunc(gocache.NewCache(), func(n int) (int, error) {
+		calls++
+		panic("boom")
+	}, time.Hour, WithPanicCaching(50*time.Millisecond))
+	fn(1)
+	if _, err := fn(1); err == nil || calls != 1 {
+		t.Errorf("expected the panic to have been cached, got %v after %d calls", err, calls)
+	}
+	time.Sleep(60 * time.Millisecond)
+	fn(1)
+	if calls != 2 {
+		t.Errorf("expected the function to have been called again once the panic expired, got %d calls", calls)
+	}
+}
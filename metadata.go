@@ -0,0 +1,46 @@
+package gocache
+
+import (
+	"context"
+	"time"
+)
+
+// SetWithMetadata creates or updates a key with a given value and expiration time (-1 is NoExpiration), and attaches
+// metadata to the entry, such as where the value came from, its ETag or the ID of the trace that produced it, without
+// having to wrap the value in a struct. The metadata can be retrieved through GetMetadata.
+//
+// The metadata is copied, so the map passed as parameter may be modified afterwards. It stays attached to the entry
+// until the entry is set again, including through the other Set-like functions, which remove it. The metadata does
+// not count towards the MaxMemoryUsage.
+//
+// Returns ErrCacheFull if the cache is full and its FullBehavior is RejectWrites
+func (c *Cache) SetWithMetadata(key string, value interface{}, ttl time.Duration, metadata map[string]string) error {
+	var copied map[string]string
+	if len(metadata) > 0 {
+		copied = make(map[string]string, len(metadata))
+		for name, metadataValue := range metadata {
+			copied[name] = metadataValue
+		}
+	}
+	return c.setWithHooks(context.Background(), key, value, ttl, expirationOf(ttl), 0, copied)
+}
+
+// GetMetadata retrieves the metadata attached to an entry through SetWithMetadata
+// If the entry doesn't exist or has expired, the map returned will be nil and the boolean will be false.
+// If the entry exists but has no metadata, the map returned will be empty and the boolean will be true.
+//
+// Unlike Get, GetMetadata does not count as accessing the entry, nor does it update the statistics.
+func (c *Cache) GetMetadata(key string) (map[string]string, bool) {
+	key = c.storageKey(key)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	entry, ok := c.get(key)
+	if !ok || entry.Expired() {
+		return nil, false
+	}
+	metadata := make(map[string]string, len(entry.metadata))
+	for name, value := range entry.metadata {
+		metadata[name] = value
+	}
+	return metadata, true
+}
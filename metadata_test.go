@@ -0,0 +1,43 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_SetWithMetadata(t *testing.T) {
+	cache := NewCache()
+	metadata := map[string]string{"source": "database", "etag": "abc"}
+	if err := cache.SetWithMetadata("key", "value", time.Hour, metadata); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	metadata["source"] = "modified"
+	retrieved, ok := cache.GetMetadata("key")
+	if !ok || len(retrieved) != 2 || retrieved["source"] != "database" || retrieved["etag"] != "abc" {
+		t.Errorf("expected the metadata to have been copied, got %v", retrieved)
+	}
+	retrieved["etag"] = "modified"
+	if retrieved, _ := cache.GetMetadata("key"); retrieved["etag"] != "abc" {
+		t.Error("expected the metadata returned to be a copy")
+	}
+	if value, _ := cache.Get("key"); value != "value" {
+		t.Errorf("expected value, got %v", value)
+	}
+	// Setting the key again without metadata removes it
+	cache.Set("key", "other")
+	if retrieved, ok := cache.GetMetadata("key"); !ok || len(retrieved) != 0 {
+		t.Errorf("expected the metadata to have been removed, got %v", retrieved)
+	}
+}
+
+func TestCache_GetMetadataWhenKeyDoesNotExist(t *testing.T) {
+	cache := NewCache()
+	if _, ok := cache.GetMetadata("missing"); ok {
+		t.Error("expected no metadata for a key that doesn't exist")
+	}
+	cache.SetWithMetadata("expired", "value", time.Nanosecond, map[string]string{"source": "test"})
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.GetMetadata("expired"); ok {
+		t.Error("expected no metadata for an expired key")
+	}
+}
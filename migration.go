@@ -0,0 +1,62 @@
+package gocache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoMigration is returned when a persisted value cannot be upgraded to the current schema version, because no
+// migration was registered for one of the versions in between, or because it is newer than the current version
+var ErrNoMigration = errors.New("no migration")
+
+// MigrateFunc upgrades a persisted value from a schema version to the next. It is passed the key of the entry and the
+// value as decoded by encoding/json, and returns the upgraded value.
+type MigrateFunc func(key string, value interface{}) (interface{}, error)
+
+// WithMigrations registers the functions upgrading persisted values from each schema version to the next, so that
+// the entries saved by an older version of an application (see SaveHotKeys) can be upgraded when they are loaded
+// instead of being discarded. The function registered under version v upgrades values from version v to version v+1.
+//
+// The schema version of the cache, which is written along with every persisted entry, is one more than the highest
+// version a migration is registered for, so registering the migration of a new version of the values is all it
+// takes to bump the schema version. Since the entries persisted before any migration was registered have version 0,
+// the first migration must be registered under version 0.
+//
+// Defaults to nil, meaning that the schema version is 0
+func WithMigrations(migrations map[int]MigrateFunc) func(c *Cache) {
+	return func(c *Cache) {
+		c.migrations = make(map[int]MigrateFunc, len(migrations))
+		c.schemaVersion = 0
+		for version, migrate := range migrations {
+			c.migrations[version] = migrate
+			if version+1 > c.schemaVersion {
+				c.schemaVersion = version + 1
+			}
+		}
+	}
+}
+
+// SchemaVersion returns the schema version written along with the entries persisted by the cache (see WithMigrations)
+func (c *Cache) SchemaVersion() int {
+	return c.schemaVersion
+}
+
+// migrate upgrades the value of a persisted entry from its schema version to the schema version of the cache
+func (c *Cache) migrate(entry ExportedEntry) (interface{}, error) {
+	currentVersion := c.schemaVersion
+	if entry.Version > currentVersion {
+		return nil, fmt.Errorf("%w from version %d to %d", ErrNoMigration, entry.Version, currentVersion)
+	}
+	value := entry.Value
+	for version := entry.Version; version < currentVersion; version++ {
+		migrate, ok := c.migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("%w from version %d to %d", ErrNoMigration, version, version+1)
+		}
+		var err error
+		if value, err = migrate(entry.Key, value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,69 @@
+package gocache
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotkeys.jsonl")
+	// Version 0 stored the name of the user as a string
+	oldCache := NewCache()
+	oldCache.Set("user:1", "john")
+	oldCache.Set("user:2", "jane")
+	if _, err := oldCache.SaveHotKeys(path, 0); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	// Version 1 stores it in a map, and version 2 adds the email of the user
+	cache := NewCache(WithMigrations(map[int]MigrateFunc{
+		0: func(key string, value interface{}) (interface{}, error) {
+			return map[string]interface{}{"name": value}, nil
+		},
+		1: func(key string, value interface{}) (interface{}, error) {
+			if key == "user:2" {
+				return nil, errors.New("cannot migrate")
+			}
+			user := value.(map[string]interface{})
+			user["email"] = fmt.Sprintf("%s@example.com", user["name"])
+			return user, nil
+		},
+	}))
+	if cache.SchemaVersion() != 2 {
+		t.Errorf("expected a schema version of 2, got %d", cache.SchemaVersion())
+	}
+	numberOfEntriesRestored, err := cache.LoadHotKeys(path)
+	if err != nil || numberOfEntriesRestored != 1 {
+		t.Fatalf("expected 1 entry to have been restored, got %d and %v", numberOfEntriesRestored, err)
+	}
+	user, ok := cache.Get("user:1")
+	if !ok || user.(map[string]interface{})["email"] != "john@example.com" {
+		t.Errorf("expected user:1 to have been migrated, got %v", user)
+	}
+	if _, ok := cache.Get("user:2"); ok {
+		t.Error("expected user:2 to have been skipped, since it could not be migrated")
+	}
+	// Entries saved by the current version are restored as is, but an older version cannot load them
+	if _, err := cache.SaveHotKeys(path, 0); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if numberOfEntriesRestored, _ := NewCache(WithMigrations(cache.migrations)).LoadHotKeys(path); numberOfEntriesRestored != 1 {
+		t.Errorf("expected 1 entry to have been restored, got %d", numberOfEntriesRestored)
+	}
+	if numberOfEntriesRestored, _ := NewCache().LoadHotKeys(path); numberOfEntriesRestored != 0 {
+		t.Errorf("expected entries with a newer schema version to have been skipped, got %d", numberOfEntriesRestored)
+	}
+}
+
+func TestCache_MigrateWhenMigrationIsMissing(t *testing.T) {
+	cache := NewCache(WithMigrations(map[int]MigrateFunc{
+		1: func(key string, value interface{}) (interface{}, error) { return value, nil },
+	}))
+	if _, err := cache.migrate(ExportedEntry{Key: "key", Value: "value"}); !errors.Is(err, ErrNoMigration) {
+		t.Errorf("expected ErrNoMigration, got %v", err)
+	}
+	if value, err := cache.migrate(ExportedEntry{Key: "key", Value: "value", Version: 1}); err != nil || value != "value" {
+		t.Errorf("expected the value to have been migrated, got %v and %v", value, err)
+	}
+}
@@ -0,0 +1,62 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCache_MostRecentlyUsedEvictsHead(t *testing.T) {
+	cache := NewCache(WithMaxSize(3), WithEvictionPolicy(MostRecentlyUsed), WithRaceAssertions(true))
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	cache.Set("3", "value")
+	cache.Get("1")
+	// 1 is the most recently used entry, so it is the one evicted to make room for 4
+	cache.Set("4", "value")
+	if _, ok := cache.Get("1"); ok {
+		t.Error("expected 1 to have been evicted")
+	}
+	for _, key := range []string{"2", "3", "4"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %s not to have been evicted", key)
+		}
+	}
+	if cache.Stats().EvictedKeys != 1 {
+		t.Errorf("expected 1 eviction, got %d", cache.Stats().EvictedKeys)
+	}
+}
+
+func TestCache_MostRecentlyUsedDoesNotEvictEntryBeingWritten(t *testing.T) {
+	cache := NewCache(WithMaxSize(1), WithEvictionPolicy(MostRecentlyUsed), WithRaceAssertions(true))
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	if _, ok := cache.Get("2"); !ok {
+		t.Error("expected the entry that was just written not to have been evicted")
+	}
+	if cache.Count() != 1 {
+		t.Errorf("expected 1 entry, got %d", cache.Count())
+	}
+}
+
+func TestCache_MostRecentlyUsedCyclicScan(t *testing.T) {
+	const size, keys = 10, 15
+	lru := NewCache(WithMaxSize(size), WithEvictionPolicy(LeastRecentlyUsed))
+	mru := NewCache(WithMaxSize(size), WithEvictionPolicy(MostRecentlyUsed), WithRaceAssertions(true))
+	for _, cache := range []*Cache{lru, mru} {
+		for pass := 0; pass < 5; pass++ {
+			for n := 0; n < keys; n++ {
+				key := fmt.Sprintf("key-%d", n)
+				if _, ok := cache.Get(key); !ok {
+					cache.Set(key, "value")
+				}
+			}
+		}
+	}
+	// LRU always evicts the key that is needed next in a cyclic scan larger than the cache, so it never hits
+	if lru.Stats().Hits != 0 {
+		t.Errorf("expected LRU to have no hits, got %d", lru.Stats().Hits)
+	}
+	if mru.Stats().Hits == 0 {
+		t.Error("expected MRU to have hits")
+	}
+}
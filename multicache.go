@@ -0,0 +1,85 @@
+package gocache
+
+import "time"
+
+// MultiCache routes entries to one of two caches depending on the size of their value, so that large values cannot
+// evict masses of small, frequently accessed entries, while still presenting the API of a single cache
+//
+// Each underlying cache keeps its own configuration, eviction policy and statistics. A key is only ever present in one
+// of the two caches: updating a key with a value of a different size class moves it to the other cache.
+type MultiCache struct {
+	small         *Cache
+	large         *Cache
+	sizeThreshold int
+}
+
+// NewMultiCache creates a MultiCache which stores values whose approximate size in bytes is lower than or equal to
+// sizeThreshold in the small cache, and larger values in the large cache
+func NewMultiCache(small *Cache, large *Cache, sizeThreshold int) *MultiCache {
+	return &MultiCache{
+		small:         small,
+		large:         large,
+		sizeThreshold: sizeThreshold,
+	}
+}
+
+// Small returns the cache storing values whose size is lower than or equal to the size threshold
+func (mc *MultiCache) Small() *Cache {
+	return mc.small
+}
+
+// Large returns the cache storing values whose size is above the size threshold
+func (mc *MultiCache) Large() *Cache {
+	return mc.large
+}
+
+// Set creates or updates a key with a given value in the cache matching the size of the value
+//
+// Returns ErrCacheFull if the cache matching the size of the value is full and its FullBehavior is RejectWrites
+func (mc *MultiCache) Set(key string, value interface{}) error {
+	return mc.SetWithTTL(key, value, ttlOf(value))
+}
+
+// SetWithTTL creates or updates a key with a given value and expiration time in the cache matching the size of the
+// value, and removes the key from the other cache, if it was there
+//
+// Returns ErrCacheFull if the cache matching the size of the value is full and its FullBehavior is RejectWrites
+func (mc *MultiCache) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	target, other := mc.small, mc.large
+	if toBytes(value) > mc.sizeThreshold {
+		target, other = mc.large, mc.small
+	}
+	other.Delete(key)
+	return target.SetWithTTL(key, value, ttl)
+}
+
+// Get retrieves an entry from whichever cache it is in
+//
+// Because the small cache is looked up first, a key stored in the large cache counts as a miss in the small cache's
+// statistics.
+func (mc *MultiCache) Get(key string) (interface{}, bool) {
+	if value, ok := mc.small.Get(key); ok {
+		return value, true
+	}
+	return mc.large.Get(key)
+}
+
+// Delete removes a key from whichever cache it is in
+//
+// Returns false if the key did not exist.
+func (mc *MultiCache) Delete(key string) bool {
+	deletedFromSmall := mc.small.Delete(key)
+	deletedFromLarge := mc.large.Delete(key)
+	return deletedFromSmall || deletedFromLarge
+}
+
+// Count returns the total number of entries in both caches
+func (mc *MultiCache) Count() int {
+	return mc.small.Count() + mc.large.Count()
+}
+
+// Clear deletes all entries from both caches
+func (mc *MultiCache) Clear() {
+	mc.small.Clear()
+	mc.large.Clear()
+}
@@ -0,0 +1,57 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMultiCache(t *testing.T) {
+	mc := NewMultiCache(NewCache(WithMaxSize(10)), NewCache(WithMaxSize(2)), 64)
+	mc.Set("small", "value")
+	mc.SetWithTTL("large", strings.Repeat("x", 100), time.Hour)
+	if mc.Small().Count() != 1 || mc.Large().Count() != 1 {
+		t.Fatalf("expected one entry in each cache, got %d and %d", mc.Small().Count(), mc.Large().Count())
+	}
+	if value, ok := mc.Get("small"); !ok || value != "value" {
+		t.Error("expected small to be retrievable, got", value)
+	}
+	if value, ok := mc.Get("large"); !ok || value != strings.Repeat("x", 100) {
+		t.Error("expected large to be retrievable, got", value)
+	}
+	if mc.Count() != 2 {
+		t.Errorf("expected 2 entries, got %d", mc.Count())
+	}
+	// Large values must not evict small ones
+	for i := 0; i < 10; i++ {
+		mc.Set("blob", strings.Repeat("y", 100+i))
+		mc.Set(strings.Repeat("z", i+1), strings.Repeat("y", 100))
+	}
+	if _, ok := mc.Get("small"); !ok {
+		t.Error("expected small not to have been evicted by large values")
+	}
+	if mc.Large().Count() != 2 {
+		t.Errorf("expected the large cache to be capped at 2 entries, got %d", mc.Large().Count())
+	}
+}
+
+func TestMultiCache_SetMovesKeyBetweenCaches(t *testing.T) {
+	mc := NewMultiCache(NewCache(), NewCache(), 64)
+	mc.Set("key", strings.Repeat("x", 100))
+	mc.Set("key", "small")
+	if mc.Large().Count() != 0 || mc.Small().Count() != 1 {
+		t.Fatalf("expected key to have moved to the small cache, got %d and %d", mc.Small().Count(), mc.Large().Count())
+	}
+	if value, _ := mc.Get("key"); value != "small" {
+		t.Error("expected the latest value, got", value)
+	}
+	if !mc.Delete("key") || mc.Delete("key") {
+		t.Error("expected Delete to return true only the first time")
+	}
+	mc.Set("a", "a")
+	mc.Set("b", strings.Repeat("x", 100))
+	mc.Clear()
+	if mc.Count() != 0 {
+		t.Errorf("expected both caches to be empty, got %d entries", mc.Count())
+	}
+}
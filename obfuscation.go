@@ -0,0 +1,39 @@
+package gocache
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// WithKeyObfuscation makes the cache store keys as the hex-encoded HMAC-SHA256 digest of the key computed with the
+// hmacKey passed as parameter, so that keys containing personally identifiable information, such as emails or
+// tokens, never appear in the memory of the process, in dumps or in exports.
+//
+// Functions taking a key as parameter, such as Get, Set, Delete or TTL, work transparently with the original key.
+// However, every function returning keys, such as GetKeysByPattern, GetAll, GetKeysByTag, NextExpiration, the export
+// functions, the hooks and the audit records, returns the digests instead, and patterns are matched against the
+// digests, which means that only the "*" pattern remains useful, except for those of WithNeverCachePatterns and
+// OnSetPattern.
+//
+// Defaults to nil, meaning that keys are stored as they are
+func WithKeyObfuscation(hmacKey []byte) func(c *Cache) {
+	return func(c *Cache) {
+		if len(hmacKey) == 0 {
+			c.keyObfuscation = nil
+			return
+		}
+		c.keyObfuscation = append([]byte(nil), hmacKey...)
+	}
+}
+
+// storageKey returns the key under which the entry of the key passed as parameter is stored, which is the key itself
+// unless WithKeyObfuscation is used
+func (c *Cache) storageKey(key string) string {
+	if c.keyObfuscation == nil {
+		return key
+	}
+	mac := hmac.New(sha256.New, c.keyObfuscation)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package gocache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_WithKeyObfuscation(t *testing.T) {
+	cache := NewCache(WithKeyObfuscation([]byte("secret")))
+	cache.SetWithTTL("john@example.com", "john", time.Hour)
+	if value, ok := cache.Get("john@example.com"); !ok || value != "john" {
+		t.Errorf("expected john, got %v", value)
+	}
+	if ttl, err := cache.TTL("john@example.com"); err != nil || ttl <= 0 {
+		t.Errorf("expected a TTL, got %s and %v", ttl, err)
+	}
+	for key := range cache.GetAll() {
+		if strings.Contains(key, "john") || len(key) != 64 {
+			t.Errorf("expected the key to be stored as a digest, got %s", key)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := cache.ExportKeys(&buf, "*"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "example.com") {
+		t.Errorf("expected the key not to appear in the export, got %s", buf.String())
+	}
+	if value, ok := cache.Take("john@example.com"); !ok || value != "john" {
+		t.Errorf("expected to take john, got %v", value)
+	}
+	cache.Set("jane@example.com", "jane")
+	if cache.DeleteAll([]string{"jane@example.com"}) != 1 {
+		t.Error("expected DeleteAll to delete jane@example.com")
+	}
+	cache.Set("jim@example.com", "jim")
+	if cache.DeleteKeysByPattern("*") != 1 {
+		t.Error("expected DeleteKeysByPattern to delete the digest of jim@example.com")
+	}
+}
+
+func TestCache_WithKeyObfuscationUsesHMACKey(t *testing.T) {
+	first := NewCache(WithKeyObfuscation([]byte("first")))
+	second := NewCache(WithKeyObfuscation([]byte("second")))
+	first.Set("key", "value")
+	second.Set("key", "value")
+	if first.GetKeysByPattern("*", 0)[0] == second.GetKeysByPattern("*", 0)[0] {
+		t.Error("expected the digests of caches with different HMAC keys to differ")
+	}
+	if NewCache(WithKeyObfuscation(nil)).storageKey("key") != "key" {
+		t.Error("expected an empty HMAC key to disable key obfuscation")
+	}
+}
@@ -1,6 +1,10 @@
 package gocache
 
-import "testing"
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestMatchPattern(t *testing.T) {
 	scenarios := []struct {
@@ -119,3 +123,23 @@ func TestMatchPattern(t *testing.T) {
 		})
 	}
 }
+
+func FuzzMatchPattern(f *testing.F) {
+	f.Add("*", "livingroom_123")
+	f.Add("*living*", "livingroom_123")
+	f.Add("living?oom_[0-9]*", "livingroom_123")
+	f.Add("[", "[")
+	f.Add("\\", "")
+	f.Fuzz(func(t *testing.T, pattern, s string) {
+		matched := MatchPattern(pattern, s)
+		if pattern == "*" && !matched {
+			t.Errorf("expected pattern * to match %q", s)
+		}
+		if expected, err := filepath.Match(pattern, s); err == nil && pattern != "*" && matched != expected {
+			t.Errorf("expected MatchPattern(%q, %q) to be %v", pattern, s, expected)
+		}
+		if !strings.ContainsAny(s, `*?[]\/`) && !MatchPattern(s, s) {
+			t.Errorf("expected %q to match itself", s)
+		}
+	})
+}
@@ -32,4 +32,46 @@ const (
 	LeastRecentlyUsed
 
 	LeastFrequentUsed
+
+	// LRUK is an eviction policy that evicts the entry whose K-th most recent access is the oldest, K being set through
+	// WithK. Entries that have been accessed fewer than K times are evicted first, from the least recently used.
+	//
+	// Unlike LeastRecentlyUsed, a single access is not enough for an entry to be kept over entries that are accessed
+	// regularly, which makes LRUK resistant to one-off scans. Writes count as accesses, and the last K access times of
+	// each entry are kept, so the overhead of the access history is bounded by MaxK.
+	LRUK
+
+	// MostRecentlyUsed is an eviction policy that evicts the most recently accessed cache entry, other than the one
+	// being written. Entries are ordered the same way as with LeastRecentlyUsed, but the head is evicted instead of
+	// the tail.
+	//
+	// This is suited for cyclic scans over a dataset larger than the cache, where the entry that was just accessed is
+	// the one that will be needed again the latest, and for which LeastRecentlyUsed would evict every entry right
+	// before it is needed.
+	MostRecentlyUsed
+
+	// Sieve is an eviction policy that keeps the entries in insertion order like FirstInFirstOut, but gives a second
+	// chance to the entries that were accessed since the last time they were considered for eviction (SIEVE).
+	//
+	// A hand walks from the oldest entry to the newest, clearing the visited flag of the entries it passes until it
+	// finds one that wasn't visited, which is evicted. The hand then resumes from there on the next eviction.
+	// This achieves hit ratios close to LeastRecentlyUsed, but without moving entries on access.
+	Sieve
+)
+
+// FullBehavior is what dictates how writes are handled once the cache has reached its MaxSize or MaxMemoryUsage
+type FullBehavior int
+
+const (
+	// EvictTail is the default FullBehavior, which causes the cache to make room for new entries by evicting the
+	// entries dictated by the EvictionPolicy
+	EvictTail FullBehavior = iota
+
+	// RejectWrites is a FullBehavior that causes writes which would push the cache above its MaxSize or
+	// MaxMemoryUsage to be rejected with ErrCacheFull instead of evicting existing entries.
+	//
+	// This is useful when the eviction of any entry is unacceptable, such as when the cache is used as a session store.
+	// Note that updating an existing key is always allowed under a MaxSize, since it does not increase the number of
+	// entries, but may still be rejected if it would push the cache above its MaxMemoryUsage.
+	RejectWrites
 )
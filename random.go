@@ -0,0 +1,53 @@
+package gocache
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// lockedRand is a rand.Rand that is safe for concurrent use, since the functions of the cache using it may only hold
+// the read lock of the cache
+type lockedRand struct {
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+// WithRandSource sets the source of randomness used by the cache, so that tests can seed it and get reproducible
+// results from the functions relying on randomness: SampleKeys, EstimateCountByPattern, the statistics sampling (see
+// WithStatsSampling) and the layout of the sorted sets created through ZAdd. Eviction never relies on randomness, as
+// every EvictionPolicy, including the tie-breaks of LeastFrequentUsed, is deterministic.
+//
+// The source doesn't have to be safe for concurrent use. Defaults to nil, meaning that the global source of math/rand
+// is used.
+func WithRandSource(source rand.Source) func(c *Cache) {
+	return func(c *Cache) {
+		if source == nil {
+			c.random = nil
+			return
+		}
+		c.random = &lockedRand{random: rand.New(source)}
+		c.statsSampler = c.seed()
+	}
+}
+
+// intn returns a pseudo-random number in [0, n) from the source set through WithRandSource, if any
+func (c *Cache) intn(n int) int {
+	if c.random == nil {
+		return rand.Intn(n)
+	}
+	c.random.mutex.Lock()
+	defer c.random.mutex.Unlock()
+	return c.random.random.Intn(n)
+}
+
+// seed returns a non-zero seed for the pseudo-random number generators of the cache, which is drawn from the source
+// set through WithRandSource, if any, and derived from the current time otherwise
+func (c *Cache) seed() uint64 {
+	if c.random == nil {
+		return uint64(time.Now().UnixNano()) | 1
+	}
+	c.random.mutex.Lock()
+	defer c.random.mutex.Unlock()
+	return uint64(c.random.random.Int63()) | 1
+}
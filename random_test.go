@@ -0,0 +1,64 @@
+package gocache
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestWithRandSource(t *testing.T) {
+	newCache := func() *Cache {
+		cache := NewCache(WithMaxSize(NoMaxSize), WithRandSource(rand.NewSource(42)), WithStatsSampling(0.5))
+		for n := 0; n < 1000; n++ {
+			cache.Set(fmt.Sprintf("%03d", n), n)
+		}
+		return cache
+	}
+	first, second := newCache(), newCache()
+	for i := 0; i < 10; i++ {
+		if a, b := first.SampleKeys(10), second.SampleKeys(10); !reflect.DeepEqual(a, b) {
+			t.Fatalf("expected caches with the same seed to sample the same keys, got %v and %v", a, b)
+		}
+	}
+	if first.statsSampler != second.statsSampler {
+		t.Error("expected caches with the same seed to sample the same statistics")
+	}
+	for n := 0; n < 100; n++ {
+		first.Get("000")
+		second.Get("000")
+	}
+	if a, b := first.Stats().Hits, second.Stats().Hits; a != b {
+		t.Errorf("expected caches with the same seed to count the same hits, got %d and %d", a, b)
+	}
+}
+
+func TestWithRandSource_SortedSetLevels(t *testing.T) {
+	levels := func(cache *Cache) []int {
+		for n := 0; n < 100; n++ {
+			cache.ZAdd("leaderboard", float64(n), fmt.Sprintf("player-%d", n))
+		}
+		value, _ := cache.Get("leaderboard")
+		var levels []int
+		for node := value.(*sortedSet).head.next[0]; node != nil; node = node.next[0] {
+			levels = append(levels, len(node.next))
+		}
+		return levels
+	}
+	first := levels(NewCache(WithRandSource(rand.NewSource(7))))
+	second := levels(NewCache(WithRandSource(rand.NewSource(7))))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected sorted sets built with the same seed to have the same levels, got %v and %v", first, second)
+	}
+}
+
+func TestWithRandSource_Nil(t *testing.T) {
+	cache := NewCache(WithRandSource(rand.NewSource(1)), WithRandSource(nil))
+	if cache.random != nil {
+		t.Error("expected a nil source to fall back to the global source")
+	}
+	cache.Set("key", "value")
+	if keys := cache.SampleKeys(1); len(keys) != 1 {
+		t.Errorf("expected 1 key, got %d", len(keys))
+	}
+}
@@ -0,0 +1,44 @@
+package gocache
+
+// RangeBatchSize is the number of entries Range reads each time it acquires the lock
+const RangeBatchSize = 100
+
+// Range calls fn for each entry of the cache that hasn't expired, in no particular order. If fn returns false, the
+// iteration stops.
+//
+// Unlike GetAll, the values are not all copied at once: they are read in batches of RangeBatchSize, and the lock is
+// released while fn is called, so fn may safely call other methods of the cache. The keys are captured when the
+// iteration starts, so entries created during the iteration are not visited, and entries deleted before being
+// reached are skipped.
+//
+// Like GetAllEntries, Range does not count as accessing the entries, nor does it update the statistics.
+func (c *Cache) Range(fn func(key string, value interface{}) bool) {
+	c.mutex.RLock()
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	c.mutex.RUnlock()
+	batchKeys := make([]string, 0, RangeBatchSize)
+	batchValues := make([]interface{}, 0, RangeBatchSize)
+	for start := 0; start < len(keys); start += RangeBatchSize {
+		end := start + RangeBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batchKeys, batchValues = batchKeys[:0], batchValues[:0]
+		c.mutex.RLock()
+		for _, key := range keys[start:end] {
+			if entry, ok := c.entries[key]; ok && !entry.Expired() {
+				batchKeys = append(batchKeys, key)
+				batchValues = append(batchValues, entry.Value)
+			}
+		}
+		c.mutex.RUnlock()
+		for i, key := range batchKeys {
+			if !fn(key, batchValues[i]) {
+				return
+			}
+		}
+	}
+}
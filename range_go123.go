@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package gocache
+
+import "iter"
+
+// Entries returns an iterator over the entries of the cache that haven't expired, which allows ranging over the
+// cache directly:
+//
+//	for key, value := range cache.Entries() {
+//		...
+//	}
+//
+// See Range for the guarantees of the iteration.
+func (c *Cache) Entries() iter.Seq2[string, interface{}] {
+	return c.Range
+}
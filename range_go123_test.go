@@ -0,0 +1,18 @@
+//go:build go1.23
+
+package gocache
+
+import "testing"
+
+func TestCache_Entries(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", "a")
+	cache.Set("2", "b")
+	visited := make(map[string]interface{})
+	for key, value := range cache.Entries() {
+		visited[key] = value
+	}
+	if len(visited) != 2 || visited["1"] != "a" || visited["2"] != "b" {
+		t.Errorf("expected 1=a and 2=b, got %v", visited)
+	}
+}
@@ -0,0 +1,64 @@
+package gocache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCache_Range(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize))
+	for i := 0; i < RangeBatchSize*2+50; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	visited := make(map[string]interface{})
+	cache.Range(func(key string, value interface{}) bool {
+		visited[key] = value
+		return true
+	})
+	if len(visited) != RangeBatchSize*2+50 {
+		t.Errorf("expected %d entries to have been visited, got %d", RangeBatchSize*2+50, len(visited))
+	}
+	if _, ok := visited["expired"]; ok {
+		t.Error("expected the expired entry not to have been visited")
+	}
+	if visited["42"] != 42 {
+		t.Errorf("expected 42, got %v", visited["42"])
+	}
+	if stats := cache.Stats(); stats.Hits != 0 {
+		t.Errorf("expected Range not to count as hits, got %d", stats.Hits)
+	}
+}
+
+func TestCache_RangeStopsWhenFnReturnsFalse(t *testing.T) {
+	cache := NewCache()
+	for i := 0; i < 10; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	calls := 0
+	cache.Range(func(key string, value interface{}) bool {
+		calls++
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Errorf("expected the iteration to stop after 3 calls, got %d", calls)
+	}
+}
+
+func TestCache_RangeWhenFnModifiesCache(t *testing.T) {
+	cache := NewCache(WithRaceAssertions(true))
+	cache.Set("1", 1)
+	cache.Set("2", 2)
+	visited := 0
+	cache.Range(func(key string, value interface{}) bool {
+		visited++
+		cache.Delete(key)
+		cache.Set("new-"+key, value)
+		return true
+	})
+	if visited != 2 {
+		t.Errorf("expected the entries created during the iteration not to be visited, got %d visits", visited)
+	}
+}
@@ -0,0 +1,103 @@
+// Package ratelimit limits how often events may happen per key, such as requests per user or per IP address, by
+// counting them in a gocache.Cache.
+//
+// Events are counted in fixed time windows by default, which is cheap but allows bursts of up to twice the limit
+// around the boundary between two windows. WithSlidingWindow smooths these bursts out by weighting the count of the
+// previous window by how much of it still overlaps with a window ending now.
+//
+// Usage:
+//
+//	limiter := ratelimit.New(gocache.NewCache(gocache.WithMaxSize(100000)))
+//	if !limiter.Allow(userID, 100, time.Minute) {
+//		http.Error(w, "too many requests", http.StatusTooManyRequests)
+//		return
+//	}
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+const (
+	// KeyPrefix is the prefix of the keys used to store the counters in the cache
+	KeyPrefix = "ratelimit:"
+)
+
+// Limiter decides whether events are allowed based on how many were allowed recently for the same key
+type Limiter struct {
+	cache   *gocache.Cache
+	sliding bool
+
+	// now returns the current time, and is only replaced in tests
+	now func() time.Time
+}
+
+// Option is an option for New
+type Option func(limiter *Limiter)
+
+// WithSlidingWindow makes the limiter approximate a sliding window rather than use fixed windows, which prevents
+// bursts of up to twice the limit around the boundaries of fixed windows, at the cost of reading a second counter
+func WithSlidingWindow() Option {
+	return func(limiter *Limiter) {
+		limiter.sliding = true
+	}
+}
+
+// New creates a Limiter which stores its counters in the given cache
+//
+// The counters expire along with their window, but they may still be evicted early if the cache is full, in which
+// case events are counted from scratch, so the cache should have enough room for one counter per active key, or two
+// with WithSlidingWindow.
+func New(cache *gocache.Cache, opts ...Option) *Limiter {
+	limiter := &Limiter{cache: cache, now: time.Now}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter
+}
+
+// Allow returns whether an event for the given key is allowed, which is the case if fewer than limit events were
+// allowed for the key in the current window, and counts it if it is. Events that aren't allowed are not counted, so
+// a key that keeps retrying is allowed again as soon as the window moves on.
+//
+// The window is aligned on multiples of its duration since the unix epoch, and the counters of a key are separate
+// for each window duration. A limit of 0 or less, or a window of 0 or less, never allows any event. If the counter
+// cannot be updated, for instance because the cache rejects writes, the event is allowed.
+func (limiter *Limiter) Allow(key string, limit int, window time.Duration) bool {
+	if limit <= 0 || window <= 0 {
+		return false
+	}
+	now := limiter.now().UnixNano()
+	index := now / int64(window)
+	remaining := time.Duration((index+1)*int64(window) - now)
+	if limiter.sliding {
+		// The counter must outlive its window, as it is used to weigh the count of the next one
+		remaining += window
+	}
+	current, err := limiter.cache.IncrementWithTTL(limiter.counterKey(key, window, index), 1, remaining)
+	if err != nil {
+		return true
+	}
+	count := float64(current)
+	if limiter.sliding {
+		if previous, ok := limiter.cache.Get(limiter.counterKey(key, window, index-1)); ok {
+			if previousCount, ok := previous.(int64); ok {
+				overlap := float64(remaining-window) / float64(window)
+				count += float64(previousCount) * overlap
+			}
+		}
+	}
+	if count > float64(limit) {
+		_, _ = limiter.cache.IncrementWithTTL(limiter.counterKey(key, window, index), -1, remaining)
+		return false
+	}
+	return true
+}
+
+// counterKey returns the key of the counter of the given key for a window
+func (limiter *Limiter) counterKey(key string, window time.Duration, index int64) string {
+	return fmt.Sprintf("%s%d:%d:%s", KeyPrefix, window, index, key)
+}
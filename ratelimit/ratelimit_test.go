@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+// clock is a fake clock whose time only moves when told to
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(opts ...Option) (*Limiter, *clock) {
+	fakeClock := &clock{now: time.Unix(1700000000, 0)}
+	limiter := New(gocache.NewCache(), opts...)
+	limiter.now = fakeClock.Now
+	return limiter, fakeClock
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter, fakeClock := newTestLimiter()
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("user", 3, time.Minute) {
+			t.Fatalf("expected event %d to have been allowed", i+1)
+		}
+	}
+	if limiter.Allow("user", 3, time.Minute) {
+		t.Error("expected the fourth event to have been denied")
+	}
+	if !limiter.Allow("other-user", 3, time.Minute) {
+		t.Error("expected the events of other keys to be counted separately")
+	}
+	if !limiter.Allow("user", 3, time.Hour) {
+		t.Error("expected the events of other windows to be counted separately")
+	}
+	fakeClock.now = fakeClock.now.Add(time.Minute)
+	if !limiter.Allow("user", 3, time.Minute) {
+		t.Error("expected the event to have been allowed in the next window")
+	}
+}
+
+func TestLimiter_AllowDoesNotCountDeniedEvents(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	limiter.Allow("user", 1, time.Minute)
+	for i := 0; i < 10; i++ {
+		limiter.Allow("user", 1, time.Minute)
+	}
+	index := limiter.now().UnixNano() / int64(time.Minute)
+	if count, _ := limiter.cache.Get(limiter.counterKey("user", time.Minute, index)); count != int64(1) {
+		t.Error("expected only the allowed event to have been counted, got", count)
+	}
+}
+
+func TestLimiter_AllowWithInvalidLimit(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	if limiter.Allow("user", 0, time.Minute) {
+		t.Error("expected a limit of 0 never to allow any event")
+	}
+	if limiter.Allow("user", 3, 0) {
+		t.Error("expected a window of 0 never to allow any event")
+	}
+	if limiter.Allow("user", 3, -time.Minute) {
+		t.Error("expected a negative window never to allow any event")
+	}
+}
+
+func TestLimiter_AllowWhenCounterCannotBeUpdated(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	index := limiter.now().UnixNano() / int64(time.Minute)
+	limiter.cache.Set(limiter.counterKey("user", time.Minute, index), "not a counter")
+	if !limiter.Allow("user", 1, time.Minute) {
+		t.Error("expected the event to have been allowed")
+	}
+}
+
+func TestLimiter_AllowWithSlidingWindow(t *testing.T) {
+	limiter, fakeClock := newTestLimiter(WithSlidingWindow())
+	// Start at the end of a window, so that a fixed window would allow a burst of twice the limit
+	fakeClock.now = fakeClock.now.Truncate(time.Minute).Add(59 * time.Second)
+	for i := 0; i < 10; i++ {
+		if !limiter.Allow("user", 10, time.Minute) {
+			t.Fatalf("expected event %d to have been allowed", i+1)
+		}
+	}
+	// 16 seconds into the next window, the last 44 seconds of the previous window still count, so 10*44/60 events
+	fakeClock.now = fakeClock.now.Add(16 * time.Second)
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if limiter.Allow("user", 10, time.Minute) {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Error("expected 2 events to have been allowed, got", allowed)
+	}
+	fakeClock.now = fakeClock.now.Add(time.Minute)
+	allowed = 0
+	for i := 0; i < 20; i++ {
+		if limiter.Allow("user", 10, time.Minute) {
+			allowed++
+		}
+	}
+	// The 2 events of the previous window count as 2*44/60
+	if allowed != 8 {
+		t.Error("expected 8 events to have been allowed, got", allowed)
+	}
+}
+
+func TestLimiter_AllowConcurrency(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	var allowed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if limiter.Allow("user", 100, time.Minute) {
+					atomic.AddInt32(&allowed, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 100 {
+		t.Error("expected exactly 100 events to have been allowed, got", allowed)
+	}
+}
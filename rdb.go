@@ -0,0 +1,346 @@
+package gocache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidRDB          = errors.New("invalid rdb file")                   // Returned when an RDB file is malformed
+	ErrUnsupportedRDBValue = errors.New("unsupported value type in rdb file") // Returned when an RDB file contains a value that cannot be skipped
+)
+
+const (
+	rdbOpcodeSlotInfo     = 0xF4
+	rdbOpcodeFunction2    = 0xF5
+	rdbOpcodeModuleAux    = 0xF7
+	rdbOpcodeIdle         = 0xF8
+	rdbOpcodeFreq         = 0xF9
+	rdbOpcodeAux          = 0xFA
+	rdbOpcodeResizeDB     = 0xFB
+	rdbOpcodeExpireTimeMs = 0xFC
+	rdbOpcodeExpireTime   = 0xFD
+	rdbOpcodeSelectDB     = 0xFE
+	rdbOpcodeEOF          = 0xFF
+
+	rdbTypeString         = 0
+	rdbTypeList           = 1
+	rdbTypeSet            = 2
+	rdbTypeZSet           = 3
+	rdbTypeHash           = 4
+	rdbTypeZSet2          = 5
+	rdbTypeHashZipmap     = 9
+	rdbTypeListZiplist    = 10
+	rdbTypeSetIntset      = 11
+	rdbTypeZSetZiplist    = 12
+	rdbTypeHashZiplist    = 13
+	rdbTypeListQuicklist  = 14
+	rdbTypeHashListpack   = 16
+	rdbTypeZSetListpack   = 17
+	rdbTypeListQuicklist2 = 18
+	rdbTypeSetListpack    = 20
+
+	rdbEncodingInt8  = 0
+	rdbEncodingInt16 = 1
+	rdbEncodingInt32 = 2
+	rdbEncodingLZF   = 3
+
+	rdbLengthEncodingMask  = 0xC0
+	rdbLengthEncoding6Bit  = 0x00
+	rdbLengthEncoding14Bit = 0x40
+	rdbLengthEncoding32Bit = 0x80
+	rdbLengthEncoding64Bit = 0x81
+	rdbLengthEncodingValue = 0xC0
+
+	// rdbMaxStringLength is the maximum length of a string in Redis, which is used to avoid allocating absurd amounts
+	// of memory when reading a corrupted file
+	rdbMaxStringLength = 512 * Megabyte
+)
+
+// ImportFromRDB bulk-loads the string keys matching the given pattern from a Redis RDB file, along with their values
+// and expiration times. Keys from every database in the file are imported.
+//
+// Keys that do not hold a string are skipped, as well as keys that have already expired. Values are stored as strings.
+// Files containing streams or module values are not supported, and ErrUnsupportedRDBValue is returned if one is found.
+//
+// Returns the number of keys imported. If the cache rejects a write (see RejectWrites), the import stops and
+// ErrCacheFull is returned along with the number of keys imported until then.
+func (c *Cache) ImportFromRDB(r io.Reader, pattern string) (int, error) {
+	rdb := &rdbReader{reader: bufio.NewReader(r)}
+	header := make([]byte, 9)
+	if _, err := io.ReadFull(rdb.reader, header); err != nil || string(header[:5]) != "REDIS" {
+		return 0, ErrInvalidRDB
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	numberOfKeysImported := 0
+	var expiration time.Time
+	for {
+		opcode, err := rdb.reader.ReadByte()
+		if err != nil {
+			return numberOfKeysImported, ErrInvalidRDB
+		}
+		switch opcode {
+		case rdbOpcodeEOF:
+			// The checksum that follows is ignored
+			return numberOfKeysImported, nil
+		case rdbOpcodeSelectDB, rdbOpcodeIdle:
+			_, err = rdb.readLength()
+		case rdbOpcodeResizeDB:
+			if _, err = rdb.readLength(); err == nil {
+				_, err = rdb.readLength()
+			}
+		case rdbOpcodeSlotInfo:
+			for i := 0; i < 3 && err == nil; i++ {
+				_, err = rdb.readLength()
+			}
+		case rdbOpcodeFreq:
+			_, err = rdb.reader.ReadByte()
+		case rdbOpcodeAux:
+			if _, err = rdb.readString(); err == nil {
+				_, err = rdb.readString()
+			}
+		case rdbOpcodeFunction2:
+			_, err = rdb.readString()
+		case rdbOpcodeModuleAux:
+			return numberOfKeysImported, ErrUnsupportedRDBValue
+		case rdbOpcodeExpireTimeMs:
+			var milliseconds int64
+			if err = binary.Read(rdb.reader, binary.LittleEndian, &milliseconds); err == nil {
+				expiration = time.UnixMilli(milliseconds)
+			}
+		case rdbOpcodeExpireTime:
+			var seconds int32
+			if err = binary.Read(rdb.reader, binary.LittleEndian, &seconds); err == nil {
+				expiration = time.Unix(int64(seconds), 0)
+			}
+		default:
+			var key, value string
+			if key, err = rdb.readString(); err != nil {
+				break
+			}
+			if opcode != rdbTypeString {
+				err = rdb.skipValue(opcode)
+				expiration = time.Time{}
+				break
+			}
+			if value, err = rdb.readString(); err != nil {
+				break
+			}
+			ttl := time.Duration(NoExpiration)
+			if !expiration.IsZero() {
+				ttl = time.Until(expiration)
+				expiration = time.Time{}
+				if ttl < 1 {
+					continue
+				}
+			}
+			if !MatchPattern(pattern, key) {
+				continue
+			}
+			if err = c.SetWithTTL(key, value, ttl); err != nil {
+				return numberOfKeysImported, err
+			}
+			numberOfKeysImported++
+		}
+		if err != nil {
+			if err == ErrUnsupportedRDBValue || err == ErrCacheFull {
+				return numberOfKeysImported, err
+			}
+			return numberOfKeysImported, ErrInvalidRDB
+		}
+	}
+}
+
+// rdbReader reads the primitives of the RDB file format
+type rdbReader struct {
+	reader *bufio.Reader
+}
+
+// readLength reads a length-encoded integer
+func (rdb *rdbReader) readLength() (uint64, error) {
+	length, isEncodedValue, err := rdb.readLengthOrEncoding()
+	if err == nil && isEncodedValue {
+		return 0, ErrInvalidRDB
+	}
+	return length, err
+}
+
+// readLengthOrEncoding reads a length-encoded integer, which may instead be the type of a specially encoded string,
+// in which case the boolean returned is true
+func (rdb *rdbReader) readLengthOrEncoding() (uint64, bool, error) {
+	first, err := rdb.reader.ReadByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch first & rdbLengthEncodingMask {
+	case rdbLengthEncoding6Bit:
+		return uint64(first & 0x3F), false, nil
+	case rdbLengthEncoding14Bit:
+		second, err := rdb.reader.ReadByte()
+		return uint64(first&0x3F)<<8 | uint64(second), false, err
+	case rdbLengthEncodingValue:
+		return uint64(first & 0x3F), true, nil
+	}
+	switch first {
+	case rdbLengthEncoding32Bit:
+		var length uint32
+		err = binary.Read(rdb.reader, binary.BigEndian, &length)
+		return uint64(length), false, err
+	case rdbLengthEncoding64Bit:
+		var length uint64
+		err = binary.Read(rdb.reader, binary.BigEndian, &length)
+		return length, false, err
+	}
+	return 0, false, ErrInvalidRDB
+}
+
+// readString reads a string, which may be stored as an integer or compressed
+func (rdb *rdbReader) readString() (string, error) {
+	length, isEncodedValue, err := rdb.readLengthOrEncoding()
+	if err != nil {
+		return "", err
+	}
+	if !isEncodedValue {
+		if length > rdbMaxStringLength {
+			return "", ErrInvalidRDB
+		}
+		buffer := make([]byte, length)
+		_, err = io.ReadFull(rdb.reader, buffer)
+		return string(buffer), err
+	}
+	switch length {
+	case rdbEncodingInt8:
+		value, err := rdb.reader.ReadByte()
+		return strconv.Itoa(int(int8(value))), err
+	case rdbEncodingInt16:
+		var value int16
+		err = binary.Read(rdb.reader, binary.LittleEndian, &value)
+		return strconv.Itoa(int(value)), err
+	case rdbEncodingInt32:
+		var value int32
+		err = binary.Read(rdb.reader, binary.LittleEndian, &value)
+		return strconv.Itoa(int(value)), err
+	case rdbEncodingLZF:
+		compressedLength, err := rdb.readLength()
+		if err != nil {
+			return "", err
+		}
+		uncompressedLength, err := rdb.readLength()
+		if err != nil {
+			return "", err
+		}
+		if compressedLength > rdbMaxStringLength || uncompressedLength > rdbMaxStringLength {
+			return "", ErrInvalidRDB
+		}
+		compressed := make([]byte, compressedLength)
+		if _, err = io.ReadFull(rdb.reader, compressed); err != nil {
+			return "", err
+		}
+		uncompressed, err := lzfDecompress(compressed, int(uncompressedLength))
+		return string(uncompressed), err
+	}
+	return "", ErrInvalidRDB
+}
+
+// skipValue skips a value of the given type
+func (rdb *rdbReader) skipValue(valueType byte) error {
+	stringsPerElement := 0
+	switch valueType {
+	case rdbTypeList, rdbTypeSet, rdbTypeListQuicklist:
+		stringsPerElement = 1
+	case rdbTypeHash:
+		stringsPerElement = 2
+	case rdbTypeZSet:
+		// Each member is followed by its score, stored as a string prefixed by a single byte length
+		length, err := rdb.readLength()
+		for i := uint64(0); i < length && err == nil; i++ {
+			if _, err = rdb.readString(); err != nil {
+				break
+			}
+			var scoreLength byte
+			if scoreLength, err = rdb.reader.ReadByte(); err == nil && scoreLength < 253 {
+				_, err = rdb.reader.Discard(int(scoreLength))
+			}
+		}
+		return err
+	case rdbTypeZSet2:
+		// Each member is followed by its score, stored as a binary double
+		length, err := rdb.readLength()
+		for i := uint64(0); i < length && err == nil; i++ {
+			if _, err = rdb.readString(); err == nil {
+				_, err = rdb.reader.Discard(8)
+			}
+		}
+		return err
+	case rdbTypeListQuicklist2:
+		// Each node is a container type followed by the node itself
+		length, err := rdb.readLength()
+		for i := uint64(0); i < length && err == nil; i++ {
+			if _, err = rdb.readLength(); err == nil {
+				_, err = rdb.readString()
+			}
+		}
+		return err
+	case rdbTypeHashZipmap, rdbTypeListZiplist, rdbTypeSetIntset, rdbTypeZSetZiplist, rdbTypeHashZiplist,
+		rdbTypeHashListpack, rdbTypeZSetListpack, rdbTypeSetListpack:
+		// These are stored as a single blob
+		_, err := rdb.readString()
+		return err
+	default:
+		return ErrUnsupportedRDBValue
+	}
+	length, err := rdb.readLength()
+	for i := uint64(0); i < length*uint64(stringsPerElement) && err == nil; i++ {
+		_, err = rdb.readString()
+	}
+	return err
+}
+
+// lzfDecompress decompresses data compressed with LZF, which is what Redis uses to compress strings in RDB files
+func lzfDecompress(in []byte, uncompressedLength int) ([]byte, error) {
+	out := make([]byte, 0, uncompressedLength)
+	for i := 0; i < len(in); {
+		control := int(in[i])
+		i++
+		if control < 32 {
+			// Literal run of control+1 bytes
+			length := control + 1
+			if i+length > len(in) {
+				return nil, ErrInvalidRDB
+			}
+			out = append(out, in[i:i+length]...)
+			i += length
+			continue
+		}
+		// Back reference
+		length := control >> 5
+		if length == 7 {
+			if i >= len(in) {
+				return nil, ErrInvalidRDB
+			}
+			length += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, ErrInvalidRDB
+		}
+		reference := len(out) - ((control & 0x1F) << 8) - int(in[i]) - 1
+		i++
+		if reference < 0 {
+			return nil, ErrInvalidRDB
+		}
+		for j := 0; j < length+2; j++ {
+			out = append(out, out[reference+j])
+		}
+	}
+	if len(out) != uncompressedLength {
+		return nil, ErrInvalidRDB
+	}
+	return out, nil
+}
@@ -0,0 +1,137 @@
+package gocache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// rdbBuilder builds RDB files for testing purposes
+type rdbBuilder struct {
+	bytes.Buffer
+}
+
+func newRDBBuilder() *rdbBuilder {
+	builder := &rdbBuilder{}
+	builder.WriteString("REDIS0009")
+	return builder
+}
+
+func (builder *rdbBuilder) writeString(s string) {
+	builder.WriteByte(byte(len(s)))
+	builder.WriteString(s)
+}
+
+func (builder *rdbBuilder) writeExpiration(at time.Time) {
+	builder.WriteByte(rdbOpcodeExpireTimeMs)
+	binary.Write(builder, binary.LittleEndian, at.UnixMilli())
+}
+
+func (builder *rdbBuilder) bytes() []byte {
+	builder.WriteByte(rdbOpcodeEOF)
+	builder.Write(make([]byte, 8))
+	return builder.Bytes()
+}
+
+func TestCache_ImportFromRDB(t *testing.T) {
+	builder := newRDBBuilder()
+	builder.WriteByte(rdbOpcodeAux)
+	builder.writeString("redis-ver")
+	builder.writeString("7.0.0")
+	builder.WriteByte(rdbOpcodeSelectDB)
+	builder.WriteByte(0)
+	builder.WriteByte(rdbOpcodeResizeDB)
+	builder.WriteByte(5)
+	builder.WriteByte(2)
+	// A plain string
+	builder.WriteByte(rdbTypeString)
+	builder.writeString("plain")
+	builder.writeString("value")
+	// A string with a TTL
+	builder.writeExpiration(time.Now().Add(time.Hour))
+	builder.WriteByte(rdbTypeString)
+	builder.writeString("with-ttl")
+	builder.writeString("value")
+	// A string that has already expired
+	builder.writeExpiration(time.Now().Add(-time.Hour))
+	builder.WriteByte(rdbTypeString)
+	builder.writeString("expired")
+	builder.writeString("value")
+	// A list, which should be skipped
+	builder.WriteByte(rdbTypeList)
+	builder.writeString("list")
+	builder.WriteByte(2)
+	builder.writeString("a")
+	builder.writeString("b")
+	// A string stored as an integer
+	builder.WriteByte(rdbTypeString)
+	builder.writeString("int16")
+	builder.Write([]byte{rdbLengthEncodingValue | rdbEncodingInt16, 0x39, 0x30})
+	// A string compressed with LZF, which is one literal "a" followed by a back reference repeating it 7 times
+	builder.WriteByte(rdbTypeString)
+	builder.writeString("compressed")
+	builder.Write([]byte{rdbLengthEncodingValue | rdbEncodingLZF, 4, 8, 0, 'a', 5 << 5, 0})
+	cache := NewCache(WithMaxSize(NoMaxSize))
+	imported, err := cache.ImportFromRDB(bytes.NewReader(builder.bytes()), "")
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if imported != 4 {
+		t.Errorf("expected 4 keys to have been imported, got %d", imported)
+	}
+	expectedValues := map[string]string{"plain": "value", "with-ttl": "value", "int16": "12345", "compressed": "aaaaaaaa"}
+	for key, expectedValue := range expectedValues {
+		if value, ok := cache.Get(key); !ok || value != expectedValue {
+			t.Errorf("expected %s to have value %s, got %v", key, expectedValue, value)
+		}
+	}
+	if _, ok := cache.Get("expired"); ok {
+		t.Error("expected expired key not to have been imported")
+	}
+	if _, ok := cache.Get("list"); ok {
+		t.Error("expected list not to have been imported")
+	}
+	if ttl, err := cache.TTL("with-ttl"); err != nil || ttl.Minutes() < 59 {
+		t.Error("expected with-ttl to have a TTL of almost an hour, got", ttl)
+	}
+	if _, err := cache.TTL("plain"); err != ErrKeyHasNoExpiration {
+		t.Error("expected plain to have no expiration")
+	}
+}
+
+func TestCache_ImportFromRDBWithPattern(t *testing.T) {
+	builder := newRDBBuilder()
+	for _, key := range []string{"user:1", "user:2", "session:1"} {
+		builder.WriteByte(rdbTypeString)
+		builder.writeString(key)
+		builder.writeString("value")
+	}
+	cache := NewCache()
+	imported, err := cache.ImportFromRDB(bytes.NewReader(builder.bytes()), "user:*")
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if imported != 2 || cache.Count() != 2 {
+		t.Errorf("expected 2 keys to have been imported, got %d", imported)
+	}
+}
+
+func TestCache_ImportFromRDBWhenFileIsInvalid(t *testing.T) {
+	cache := NewCache()
+	if _, err := cache.ImportFromRDB(bytes.NewReader([]byte("NOT AN RDB FILE")), ""); err != ErrInvalidRDB {
+		t.Error("expected ErrInvalidRDB, got", err)
+	}
+	builder := newRDBBuilder()
+	builder.WriteByte(rdbTypeString)
+	builder.writeString("truncated")
+	if _, err := cache.ImportFromRDB(bytes.NewReader(builder.Bytes()), ""); err != ErrInvalidRDB {
+		t.Error("expected ErrInvalidRDB, got", err)
+	}
+	builder = newRDBBuilder()
+	builder.WriteByte(15) // stream
+	builder.writeString("stream")
+	if _, err := cache.ImportFromRDB(bytes.NewReader(builder.bytes()), ""); err != ErrUnsupportedRDBValue {
+		t.Error("expected ErrUnsupportedRDBValue, got", err)
+	}
+}
@@ -0,0 +1,83 @@
+package gocache
+
+import "runtime"
+
+const (
+	// ReclaimerBatchSize is the maximum number of entries the reclaimer evicts before releasing the lock, so that
+	// foreground operations are never blocked for long
+	ReclaimerBatchSize = 100
+)
+
+// StartReclaimer starts the reclaimer on a different goroutine
+// The reclaimer's job is to keep the memory usage of the cache below the soft watermark passed as parameter by
+// evicting entries in the background, so that as long as the watermark is sufficiently lower than the MaxMemoryUsage,
+// writes almost never have to evict entries themselves.
+//
+// Unlike the janitor, which deletes expired entries, the reclaimer evicts entries according to the eviction policy,
+// and it only runs when a write pushes the memory usage above the watermark. Because the memory usage is only tracked
+// when a MaxMemoryUsage is set (see WithMaxMemoryUsage), the reclaimer does nothing otherwise.
+// It can be stopped by calling Cache.StopReclaimer.
+func (c *Cache) StartReclaimer(softWatermark int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stopReclaimer != nil {
+		return ErrReclaimerAlreadyRunning
+	}
+	c.reclaimerWatermark = softWatermark
+	c.stopReclaimer = make(chan bool)
+	c.wakeReclaimer = make(chan struct{}, 1)
+	// The channels are captured so that the goroutine doesn't have to read them from the cache, which StopReclaimer
+	// modifies
+	stop, wake := c.stopReclaimer, c.wakeReclaimer
+	goLabeled(c.name, "reclaimer", func() {
+		for {
+			select {
+			case <-wake:
+				for c.reclaim() {
+					// Give the operations waiting for the lock a chance to go through between batches
+					runtime.Gosched()
+				}
+			case <-stop:
+				stop <- true
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// StopReclaimer stops the reclaimer
+func (c *Cache) StopReclaimer() {
+	c.mutex.Lock()
+	stop := c.stopReclaimer
+	c.stopReclaimer = nil
+	c.wakeReclaimer = nil
+	c.mutex.Unlock()
+	if stop != nil {
+		// Just like StopJanitor, wait for the reclaimer to reply on the same channel to confirm that it has stopped
+		stop <- true
+		<-stop
+	}
+}
+
+// reclaim evicts up to ReclaimerBatchSize entries while the memoryUsage is above the reclaimerWatermark, and returns
+// whether more entries must be evicted
+func (c *Cache) reclaim() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stopReclaimer == nil {
+		return false
+	}
+	evictedKeysBefore := c.stats.EvictedKeys
+	for c.memoryUsage > c.reclaimerWatermark && len(c.entries) > 0 {
+		if c.stats.EvictedKeys-evictedKeysBefore >= ReclaimerBatchSize {
+			c.assertInvariants()
+			return true
+		}
+		if !c.evict(nil) {
+			break
+		}
+	}
+	c.assertInvariants()
+	return false
+}
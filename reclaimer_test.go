@@ -0,0 +1,53 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCache_StartReclaimer(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMaxMemoryUsage(100*Kilobyte), WithRaceAssertions(true))
+	if err := cache.StartReclaimer(50 * Kilobyte); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	defer cache.StopReclaimer()
+	if err := cache.StartReclaimer(50 * Kilobyte); err != ErrReclaimerAlreadyRunning {
+		t.Error("expected ErrReclaimerAlreadyRunning, got", err)
+	}
+	for n := 0; n < 2000; n++ {
+		cache.Set(fmt.Sprintf("key-%04d", n), "value")
+	}
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		cache.mutex.Lock()
+		memoryUsage := cache.memoryUsage
+		cache.mutex.Unlock()
+		if memoryUsage <= 50*Kilobyte {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatalf("expected the reclaimer to bring the memory usage below the watermark, got %d", memoryUsage)
+		}
+	}
+	if _, ok := cache.Get("key-1999"); !ok {
+		t.Error("expected the most recent entry not to have been evicted")
+	}
+}
+
+func TestCache_StopReclaimer(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMaxMemoryUsage(100*Kilobyte))
+	cache.StartReclaimer(Kilobyte)
+	cache.StopReclaimer()
+	cache.StopReclaimer()
+	for n := 0; n < 100; n++ {
+		cache.Set(fmt.Sprintf("key-%04d", n), "value")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if cache.Count() != 100 {
+		t.Errorf("expected no entry to have been evicted once the reclaimer is stopped, got %d entries", cache.Count())
+	}
+	if err := cache.StartReclaimer(Kilobyte); err != nil {
+		t.Error("expected the reclaimer to be able to start again, got", err)
+	}
+	cache.StopReclaimer()
+}
@@ -0,0 +1,431 @@
+package gocache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRedisBatchSize is the number of keys requested from or sent to Redis at once if
+	// RedisOptions.BatchSize is not set
+	DefaultRedisBatchSize = 100
+)
+
+var (
+	ErrRedisProtocol = errors.New("unexpected reply from redis") // Returned when Redis replies with something that cannot be parsed
+)
+
+// RedisOptions are the options used to connect to Redis
+type RedisOptions struct {
+	// Username is the username used to authenticate, if Redis uses ACLs
+	Username string
+
+	// Password is the password used to authenticate, if any
+	Password string
+
+	// DB is the database to select
+	DB int
+
+	// BatchSize is the number of keys requested from or sent to Redis at once
+	// Defaults to DefaultRedisBatchSize
+	BatchSize int
+
+	// Encode is used by ExportToRedis to serialize values that are neither a string nor a []byte
+	// If nil, such values are not exported
+	Encode func(value interface{}) ([]byte, error)
+}
+
+func (opts *RedisOptions) batchSize() int {
+	if opts == nil || opts.BatchSize < 1 {
+		return DefaultRedisBatchSize
+	}
+	return opts.BatchSize
+}
+
+// redisError is an error reply sent by Redis
+type redisError string
+
+func (err redisError) Error() string {
+	return "redis: " + string(err)
+}
+
+// redisConn is a minimal client for the Redis serialization protocol (RESP), which only supports what the cache needs
+// to talk to Redis. It is not safe for concurrent use.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// dialRedis connects to Redis, authenticates and selects the database specified in the options, if any
+func dialRedis(ctx context.Context, addr string, opts *RedisOptions) (*redisConn, error) {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	rc := &redisConn{conn: conn, reader: bufio.NewReader(conn), writer: bufio.NewWriter(conn)}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if opts != nil {
+		if opts.Password != "" {
+			args := []string{"AUTH", opts.Password}
+			if opts.Username != "" {
+				args = []string{"AUTH", opts.Username, opts.Password}
+			}
+			if _, err = rc.do(args...); err != nil {
+				rc.Close()
+				return nil, err
+			}
+		}
+		if opts.DB != 0 {
+			if _, err = rc.do("SELECT", strconv.Itoa(opts.DB)); err != nil {
+				rc.Close()
+				return nil, err
+			}
+		}
+	}
+	return rc, nil
+}
+
+// Close closes the connection
+func (rc *redisConn) Close() error {
+	return rc.conn.Close()
+}
+
+// send buffers a command, which will only be sent once flush is called
+func (rc *redisConn) send(args ...string) error {
+	if _, err := fmt.Fprintf(rc.writer, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if _, err := fmt.Fprintf(rc.writer, "$%d\r\n%s\r\n", len(arg), arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush sends every buffered command
+func (rc *redisConn) flush() error {
+	return rc.writer.Flush()
+}
+
+// do sends a command and returns its reply
+func (rc *redisConn) do(args ...string) (interface{}, error) {
+	if err := rc.send(args...); err != nil {
+		return nil, err
+	}
+	if err := rc.flush(); err != nil {
+		return nil, err
+	}
+	return rc.receive()
+}
+
+// receive reads a reply, which is either a string, an int64, nil, a []interface{} or a redisError.
+// Error replies are returned both as the reply and as the error.
+func (rc *redisConn) receive() (interface{}, error) {
+	line, err := rc.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, ErrRedisProtocol
+	}
+	payload := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return redisError(payload), redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		length, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, ErrRedisProtocol
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		buffer := make([]byte, length+2)
+		if _, err = io.ReadFull(rc.reader, buffer); err != nil {
+			return nil, err
+		}
+		return string(buffer[:length]), nil
+	case '*':
+		length, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, ErrRedisProtocol
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		array := make([]interface{}, length)
+		for i := range array {
+			// An error reply nested in an array is a value like any other, so it is not returned as an error
+			if array[i], err = rc.receive(); err != nil {
+				if _, isRedisError := err.(redisError); !isRedisError {
+					return nil, err
+				}
+			}
+		}
+		return array, nil
+	default:
+		return nil, ErrRedisProtocol
+	}
+}
+
+// ImportFromRedis bulk-loads the string keys matching the given pattern from a live Redis instance, along with their
+// values and TTLs, which eases the migration of small datasets from Redis to the cache.
+//
+// The keys are retrieved incrementally using SCAN, so Redis is never blocked for long, but keys that are modified
+// while the import is ongoing may or may not be imported. Keys that do not hold a string are skipped.
+// Values are stored as strings.
+//
+// Returns the number of keys imported. If the cache rejects a write (see RejectWrites), the import stops and
+// ErrCacheFull is returned along with the number of keys imported until then.
+func (c *Cache) ImportFromRedis(ctx context.Context, addr, pattern string, opts *RedisOptions) (_ int, err error) {
+	defer func() { c.countNetworkError(err) }()
+	rc, err := dialRedis(ctx, addr, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	if pattern == "" {
+		pattern = "*"
+	}
+	numberOfKeysImported := 0
+	cursor := "0"
+	for {
+		if err = ctx.Err(); err != nil {
+			return numberOfKeysImported, err
+		}
+		reply, err := rc.do("SCAN", cursor, "MATCH", pattern, "COUNT", strconv.Itoa(opts.batchSize()))
+		if err != nil {
+			return numberOfKeysImported, err
+		}
+		scanReply, ok := reply.([]interface{})
+		if !ok || len(scanReply) != 2 {
+			return numberOfKeysImported, ErrRedisProtocol
+		}
+		keys, ok := scanReply[1].([]interface{})
+		if !ok {
+			return numberOfKeysImported, ErrRedisProtocol
+		}
+		if cursor, ok = scanReply[0].(string); !ok {
+			return numberOfKeysImported, ErrRedisProtocol
+		}
+		// Pipeline a GET and a PTTL for every key in the batch
+		for _, key := range keys {
+			if err = rc.send("GET", fmt.Sprint(key)); err != nil {
+				return numberOfKeysImported, err
+			}
+			if err = rc.send("PTTL", fmt.Sprint(key)); err != nil {
+				return numberOfKeysImported, err
+			}
+		}
+		if err = rc.flush(); err != nil {
+			return numberOfKeysImported, err
+		}
+		for _, key := range keys {
+			value, getErr := rc.receive()
+			ttlReply, ttlErr := rc.receive()
+			if ttlErr != nil {
+				return numberOfKeysImported, ttlErr
+			}
+			if getErr != nil {
+				if _, isRedisError := getErr.(redisError); isRedisError {
+					// WRONGTYPE, the key doesn't hold a string
+					continue
+				}
+				return numberOfKeysImported, getErr
+			}
+			stringValue, ok := value.(string)
+			if !ok {
+				// The key was deleted between SCAN and GET
+				continue
+			}
+			ttl := time.Duration(NoExpiration)
+			if milliseconds, _ := ttlReply.(int64); milliseconds >= 0 {
+				if milliseconds == 0 {
+					// The key is about to expire, so there is no point in importing it
+					continue
+				}
+				ttl = time.Duration(milliseconds) * time.Millisecond
+			}
+			if err = c.SetWithTTL(fmt.Sprint(key), stringValue, ttl); err != nil {
+				return numberOfKeysImported, err
+			}
+			numberOfKeysImported++
+		}
+		if cursor == "0" {
+			return numberOfKeysImported, nil
+		}
+	}
+}
+
+// ExportToRedis writes every entry of the cache to Redis, preserving their TTLs, so that a warmed cache can seed a
+// Redis instance shared with other replicas.
+//
+// The entries are snapshotted before being sent in pipelined batches, meaning that the cache is only locked while the
+// snapshot is taken. Strings and []byte are exported as-is, while other values are only exported if
+// RedisOptions.Encode is set. Expired entries are never exported.
+//
+// Returns the number of keys exported.
+func (c *Cache) ExportToRedis(ctx context.Context, addr string, opts *RedisOptions) (_ int, err error) {
+	defer func() { c.countNetworkError(err) }()
+	type exportedEntry struct {
+		key        string
+		value      interface{}
+		expiration int64
+	}
+	c.mutex.RLock()
+	entries := make([]exportedEntry, 0, len(c.entries))
+	for key, entry := range c.entries {
+		if !entry.Expired() {
+			entries = append(entries, exportedEntry{key: key, value: entry.Value, expiration: entry.Expiration})
+		}
+	}
+	c.mutex.RUnlock()
+	rc, err := dialRedis(ctx, addr, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	numberOfKeysExported := 0
+	for start := 0; start < len(entries); start += opts.batchSize() {
+		if err = ctx.Err(); err != nil {
+			return numberOfKeysExported, err
+		}
+		end := start + opts.batchSize()
+		if end > len(entries) {
+			end = len(entries)
+		}
+		numberOfCommandsSent := 0
+		for _, entry := range entries[start:end] {
+			var value string
+			switch v := entry.value.(type) {
+			case string:
+				value = v
+			case []byte:
+				value = string(v)
+			default:
+				if opts == nil || opts.Encode == nil {
+					continue
+				}
+				encoded, err := opts.Encode(v)
+				if err != nil {
+					return numberOfKeysExported, err
+				}
+				value = string(encoded)
+			}
+			args := []string{"SET", entry.key, value}
+			if entry.expiration != NoExpiration {
+				milliseconds := time.Until(time.Unix(0, entry.expiration)).Milliseconds()
+				if milliseconds < 1 {
+					// The entry expired since the snapshot was taken
+					continue
+				}
+				args = append(args, "PX", strconv.FormatInt(milliseconds, 10))
+			}
+			if err = rc.send(args...); err != nil {
+				return numberOfKeysExported, err
+			}
+			numberOfCommandsSent++
+		}
+		if err = rc.flush(); err != nil {
+			return numberOfKeysExported, err
+		}
+		for i := 0; i < numberOfCommandsSent; i++ {
+			if _, err = rc.receive(); err != nil {
+				return numberOfKeysExported, err
+			}
+			numberOfKeysExported++
+		}
+	}
+	return numberOfKeysExported, nil
+}
+
+// SubscribeToRedisInvalidations keeps the cache coherent with a Redis instance used as source of truth by deleting
+// the local entry of every key matching the given pattern whenever Redis notifies that it was modified, deleted,
+// expired or evicted.
+//
+// This relies on keyspace notifications, which are disabled by default and must be enabled on the Redis instance
+// (e.g. CONFIG SET notify-keyspace-events KA).
+//
+// This blocks until the context is canceled or the connection to Redis is lost, so it should usually be called on its
+// own goroutine. When the context is canceled, the context's error is returned.
+func (c *Cache) SubscribeToRedisInvalidations(ctx context.Context, addr, pattern string, opts *RedisOptions) (err error) {
+	defer func() { c.countNetworkError(err) }()
+	rc, err := dialRedis(ctx, addr, opts)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// The deadline set while dialing must not apply to a subscription, which is meant to last
+	_ = rc.conn.SetDeadline(time.Time{})
+	if pattern == "" {
+		pattern = "*"
+	}
+	db := 0
+	if opts != nil {
+		db = opts.DB
+	}
+	channelPrefix := fmt.Sprintf("__keyspace@%d__:", db)
+	if _, err = rc.do("PSUBSCRIBE", channelPrefix+pattern); err != nil {
+		return err
+	}
+	// Closing the connection is the only way to interrupt a blocking read
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			rc.Close()
+		case <-stop:
+		}
+	}()
+	for {
+		reply, err := rc.receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		// A keyspace notification is a pmessage whose channel is the prefix followed by the key, and whose message
+		// is the event
+		message, ok := reply.([]interface{})
+		if !ok || len(message) != 4 || message[0] != "pmessage" {
+			continue
+		}
+		channel, _ := message[2].(string)
+		if len(channel) > len(channelPrefix) && channel[:len(channelPrefix)] == channelPrefix {
+			c.Delete(channel[len(channelPrefix):])
+		}
+	}
+}
+
+// countNetworkError counts the error returned by a function talking to Redis in Statistics.NetworkErrors if the
+// connection could not be established or was lost, as opposed to Redis replying with an error or the context being
+// canceled
+func (c *Cache) countNetworkError(err error) {
+	var netErr net.Error
+	if errors.Is(err, context.Canceled) || err == context.DeadlineExceeded {
+		return
+	}
+	if !errors.As(err, &netErr) && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return
+	}
+	c.mutex.Lock()
+	c.stats.NetworkErrors++
+	c.mutex.Unlock()
+}
@@ -0,0 +1,320 @@
+package gocache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a Redis server that understands just enough commands to test the cache's Redis integrations
+type fakeRedis struct {
+	listener net.Listener
+
+	mutex sync.Mutex
+	// strings are the keys holding strings, and lists the keys holding anything else
+	strings     map[string]string
+	lists       map[string]bool
+	ttls        map[string]time.Duration
+	commands    []string
+	subscribers []*redisConn
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedis{
+		listener: listener,
+		strings:  make(map[string]string),
+		lists:    make(map[string]bool),
+		ttls:     make(map[string]time.Duration),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(&redisConn{conn: conn, reader: bufio.NewReader(conn), writer: bufio.NewWriter(conn)})
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	return server
+}
+
+func (server *fakeRedis) Addr() string {
+	return server.listener.Addr().String()
+}
+
+func (server *fakeRedis) serve(rc *redisConn) {
+	defer rc.Close()
+	for {
+		request, err := rc.receive()
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range request.([]interface{}) {
+			args = append(args, arg.(string))
+		}
+		server.mutex.Lock()
+		server.commands = append(server.commands, strings.Join(args, " "))
+		rc.writer.WriteString(server.handle(rc, args))
+		rc.flush()
+		server.mutex.Unlock()
+	}
+}
+
+// handle returns the RESP-encoded reply to the given command. The caller must hold the lock.
+func (server *fakeRedis) handle(rc *redisConn, args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "SCAN":
+		// Return the keys matching the pattern, sorted, one batch at a time, using the index of the next key as cursor
+		cursor, _ := strconv.Atoi(args[1])
+		count, _ := strconv.Atoi(args[5])
+		var keys []string
+		for key := range server.strings {
+			keys = append(keys, key)
+		}
+		for key := range server.lists {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var batch []string
+		next := cursor
+		for ; next < len(keys) && len(batch) < count; next++ {
+			if MatchPattern(args[3], keys[next]) {
+				batch = append(batch, keys[next])
+			}
+		}
+		if next >= len(keys) {
+			next = 0
+		}
+		reply := fmt.Sprintf("*2\r\n$%d\r\n%d\r\n*%d\r\n", len(strconv.Itoa(next)), next, len(batch))
+		for _, key := range batch {
+			reply += fmt.Sprintf("$%d\r\n%s\r\n", len(key), key)
+		}
+		return reply
+	case "GET":
+		if server.lists[args[1]] {
+			return "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+		}
+		value, ok := server.strings[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "PTTL":
+		_, isString := server.strings[args[1]]
+		if !isString && !server.lists[args[1]] {
+			return ":-2\r\n"
+		}
+		if ttl, ok := server.ttls[args[1]]; ok {
+			return fmt.Sprintf(":%d\r\n", ttl.Milliseconds())
+		}
+		return ":-1\r\n"
+	case "SET":
+		server.strings[args[1]] = args[2]
+		delete(server.ttls, args[1])
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			milliseconds, _ := strconv.Atoi(args[4])
+			server.ttls[args[1]] = time.Duration(milliseconds) * time.Millisecond
+		}
+		return "+OK\r\n"
+	case "PSUBSCRIBE", "SUBSCRIBE":
+		server.subscribers = append(server.subscribers, rc)
+		return fmt.Sprintf("*3\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n:1\r\n", len(args[0]), strings.ToLower(args[0]), len(args[1]), args[1])
+	case "PING":
+		return "+PONG\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func TestCache_ImportFromRedis(t *testing.T) {
+	server := newFakeRedis(t)
+	for i := 0; i < 25; i++ {
+		server.strings["user:"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+	}
+	server.ttls["user:1"] = time.Hour
+	server.strings["other"] = "value"
+	server.lists["user:list"] = true
+	cache := NewCache(WithMaxSize(NoMaxSize))
+	imported, err := cache.ImportFromRedis(context.Background(), server.Addr(), "user:*", &RedisOptions{Password: "password", DB: 1, BatchSize: 10})
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if imported != 25 {
+		t.Errorf("expected 25 keys to have been imported, got %d", imported)
+	}
+	if cache.Count() != 25 {
+		t.Errorf("expected cache to have 25 entries, got %d", cache.Count())
+	}
+	if value, ok := cache.Get("user:7"); !ok || value != "value7" {
+		t.Errorf("expected user:7 to have value value7, got %v", value)
+	}
+	if _, ok := cache.Get("other"); ok {
+		t.Error("expected key other not to have been imported, since it doesn't match the pattern")
+	}
+	if _, ok := cache.Get("user:list"); ok {
+		t.Error("expected key user:list not to have been imported, since it isn't a string")
+	}
+	if ttl, err := cache.TTL("user:1"); err != nil || ttl.Minutes() < 59 {
+		t.Error("expected user:1 to have kept its TTL of an hour, got", ttl)
+	}
+	if _, err := cache.TTL("user:2"); err != ErrKeyHasNoExpiration {
+		t.Error("expected user:2 to have no expiration")
+	}
+	if server.commands[0] != "AUTH password" || server.commands[1] != "SELECT 1" {
+		t.Error("expected the client to authenticate and select the database, got", server.commands[:2])
+	}
+}
+
+func TestCache_ImportFromRedisWhenCacheIsFull(t *testing.T) {
+	server := newFakeRedis(t)
+	for i := 0; i < 5; i++ {
+		server.strings[strconv.Itoa(i)] = "value"
+	}
+	cache := NewCache(WithMaxSize(3), WithFullBehavior(RejectWrites))
+	imported, err := cache.ImportFromRedis(context.Background(), server.Addr(), "", nil)
+	if err != ErrCacheFull {
+		t.Error("expected ErrCacheFull, got", err)
+	}
+	if imported != 3 {
+		t.Errorf("expected 3 keys to have been imported, got %d", imported)
+	}
+}
+
+func TestCache_ImportFromRedisWhenConnectionFails(t *testing.T) {
+	server := newFakeRedis(t)
+	addr := server.Addr()
+	server.listener.Close()
+	cache := NewCache()
+	if _, err := cache.ImportFromRedis(context.Background(), addr, "*", nil); err == nil {
+		t.Error("expected an error")
+	}
+	if cache.Stats().NetworkErrors != 1 {
+		t.Errorf("expected 1 network error, got %d", cache.Stats().NetworkErrors)
+	}
+}
+
+func TestCache_ExportToRedis(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := NewCache(WithMaxSize(NoMaxSize))
+	for i := 0; i < 25; i++ {
+		cache.Set("key"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+	}
+	cache.SetWithTTL("with-ttl", []byte("bytes"), time.Hour)
+	cache.SetWithTTL("expired", "value", time.Millisecond)
+	cache.Set("struct", struct{ Name string }{Name: "john"})
+	time.Sleep(2 * time.Millisecond)
+	exported, err := cache.ExportToRedis(context.Background(), server.Addr(), &RedisOptions{BatchSize: 10})
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if exported != 26 {
+		t.Errorf("expected 26 keys to have been exported, got %d", exported)
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.strings["key7"] != "value7" {
+		t.Error("expected key7 to have been exported with value7, got", server.strings["key7"])
+	}
+	if server.strings["with-ttl"] != "bytes" {
+		t.Error("expected with-ttl to have been exported with value bytes, got", server.strings["with-ttl"])
+	}
+	if ttl := server.ttls["with-ttl"]; ttl.Minutes() < 59 || ttl.Minutes() > 60 {
+		t.Error("expected with-ttl to have been exported with a TTL of almost an hour, got", ttl)
+	}
+	if _, ok := server.ttls["key7"]; ok {
+		t.Error("expected key7 to have been exported without a TTL")
+	}
+	if _, ok := server.strings["expired"]; ok {
+		t.Error("expected expired key not to have been exported")
+	}
+	if _, ok := server.strings["struct"]; ok {
+		t.Error("expected struct not to have been exported, since no encoder was provided")
+	}
+}
+
+func TestCache_ExportToRedisWithEncode(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := NewCache()
+	cache.Set("number", 42)
+	exported, err := cache.ExportToRedis(context.Background(), server.Addr(), &RedisOptions{Encode: func(value interface{}) ([]byte, error) {
+		return []byte(fmt.Sprint(value)), nil
+	}})
+	if err != nil || exported != 1 {
+		t.Fatalf("expected 1 key to have been exported without error, got %d and %v", exported, err)
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.strings["number"] != "42" {
+		t.Error("expected number to have been exported with value 42, got", server.strings["number"])
+	}
+}
+
+// publish sends a keyspace notification to every subscriber
+func (server *fakeRedis) publish(key, event string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	pattern, channel := "__keyspace@0__:*", "__keyspace@0__:"+key
+	for _, subscriber := range server.subscribers {
+		fmt.Fprintf(subscriber.writer, "*4\r\n$8\r\npmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(pattern), pattern, len(channel), channel, len(event), event)
+		subscriber.flush()
+	}
+}
+
+func TestCache_SubscribeToRedisInvalidations(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := NewCache()
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- cache.SubscribeToRedisInvalidations(ctx, server.Addr(), "", nil)
+	}()
+	for {
+		server.mutex.Lock()
+		numberOfSubscribers := len(server.subscribers)
+		server.mutex.Unlock()
+		if numberOfSubscribers == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	server.publish("1", "set")
+	for start := time.Now(); cache.Count() != 1; {
+		if time.Since(start) > time.Second {
+			t.Fatal("expected key 1 to have been deleted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok := cache.Get("2"); !ok {
+		t.Error("expected key 2 to still exist")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Error("expected context.Canceled, got", err)
+		}
+		if cache.Stats().NetworkErrors != 0 {
+			t.Error("expected the cancellation not to count as a network error")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected SubscribeToRedisInvalidations to return after the context was canceled")
+	}
+}
@@ -0,0 +1,144 @@
+package gocache
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+)
+
+const (
+	// RefresherChecksPerWindow is the number of times per window passed to StartRefresher that the refresher looks
+	// for entries to refresh
+	RefresherChecksPerWindow = 10
+
+	// RefresherMinInterval is the minimum interval between each time the refresher looks for entries to refresh
+	RefresherMinInterval = 10 * time.Millisecond
+)
+
+// refreshCandidate is an entry that is due for a refresh
+type refreshCandidate struct {
+	key       string
+	ttl       time.Duration
+	updatedAt int64
+}
+
+// StartRefresher starts the refresher on a different goroutine
+// The refresher's job is to refresh entries in the background before they expire, by calling refresh with their key
+// and setting the value it returns with the TTL the entry was last set with, so that frequently used entries are
+// never missing from the cache.
+//
+// Rather than refreshing every entry right before it expires, which would cause a burst of refreshes when many entries
+// were set at the same time with the same TTL (e.g. during a warm-up), each entry is refreshed at a pseudo-random time
+// derived from its key within the window preceding its expiration, or within the first half of its TTL if the TTL is
+// shorter than twice the window. This spreads the refreshes evenly over the window.
+//
+// Entries are refreshed one at a time. If refresh returns an error, the entry is left as is and refreshing it is
+// retried on the next check until it expires. Entries that are updated or deleted while they are being refreshed are
+// not overwritten, and entries without expiration are never refreshed. Note that the key passed to refresh is the
+// key the entry is stored under, which is not the key given by the caller if WithKeyObfuscation is used.
+// It can be stopped by calling Cache.StopRefresher.
+func (c *Cache) StartRefresher(window time.Duration, refresh func(key string) (interface{}, error)) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stopRefresher != nil {
+		return ErrRefresherAlreadyRunning
+	}
+	c.stopRefresher = make(chan bool)
+	stop := c.stopRefresher
+	interval := window / RefresherChecksPerWindow
+	if interval < RefresherMinInterval {
+		interval = RefresherMinInterval
+	}
+	goLabeled(c.name, "refresher", func() {
+		for {
+			select {
+			case <-time.After(interval):
+				// Entries due before the next check are refreshed now, as they may have expired by then
+				for _, candidate := range c.dueForRefresh(window, time.Now().Add(interval).UnixNano()) {
+					select {
+					case <-stop:
+						stop <- true
+						return
+					default:
+					}
+					c.refreshEntry(candidate, refresh)
+				}
+			case <-stop:
+				stop <- true
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// StopRefresher stops the refresher, waiting for the refresh in progress, if any, to complete
+func (c *Cache) StopRefresher() {
+	c.mutex.Lock()
+	stop := c.stopRefresher
+	c.stopRefresher = nil
+	c.mutex.Unlock()
+	if stop != nil {
+		// Just like StopJanitor, wait for the refresher to reply on the same channel to confirm that it has stopped
+		stop <- true
+		<-stop
+	}
+}
+
+// dueForRefresh returns the entries that must be refreshed by the given unix time in nanoseconds
+func (c *Cache) dueForRefresh(window time.Duration, now int64) []refreshCandidate {
+	var candidates []refreshCandidate
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for key, entry := range c.entries {
+		if entry.Expiration == NoExpiration || entry.Expired() {
+			continue
+		}
+		// The expiration is computed right before the entry is updated, so the difference is slightly below the TTL
+		ttl := time.Duration(entry.Expiration - entry.updatedAt)
+		if ttl > time.Millisecond {
+			ttl = ttl.Round(time.Millisecond)
+		}
+		if now >= entry.Expiration-int64(refreshOffset(key, window, ttl)) {
+			candidates = append(candidates, refreshCandidate{key: key, ttl: ttl, updatedAt: entry.updatedAt})
+		}
+	}
+	return candidates
+}
+
+// refreshOffset returns how long before its expiration an entry with the given key and TTL must be refreshed, which
+// is derived from the key so that entries set at the same time are refreshed at different times
+func refreshOffset(key string, window, ttl time.Duration) time.Duration {
+	if window > ttl/2 {
+		window = ttl / 2
+	}
+	if window <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return time.Duration(hash.Sum64() % uint64(window))
+}
+
+// refreshEntry calls refresh for an entry due for a refresh, and sets the value it returns unless the entry was
+// updated or deleted in the meantime
+func (c *Cache) refreshEntry(candidate refreshCandidate, refresh func(key string) (interface{}, error)) {
+	loaded := c.LoadStarted()
+	value, err := refresh(candidate.key)
+	loaded()
+	if err != nil {
+		return
+	}
+	c.mutex.RLock()
+	entry, ok := c.get(candidate.key)
+	unchanged := ok && entry.updatedAt == candidate.updatedAt
+	var metadata map[string]string
+	if unchanged {
+		metadata = entry.metadata
+	}
+	c.mutex.RUnlock()
+	if !unchanged {
+		return
+	}
+	_ = c.setStoredWithHooks(context.Background(), candidate.key, candidate.key, value, candidate.ttl, expirationOf(candidate.ttl), 0, metadata)
+}
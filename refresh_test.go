@@ -0,0 +1,80 @@
+package gocache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_StartRefresher(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize))
+	for n := 0; n < 100; n++ {
+		cache.SetWithTTL(fmt.Sprintf("%02d", n), 0, 300*time.Millisecond)
+	}
+	cache.Set("no-expiration", 0)
+	var mutex sync.Mutex
+	refreshTimes := make(map[int64]bool)
+	refreshes := make(map[string]int)
+	if err := cache.StartRefresher(200*time.Millisecond, func(key string) (interface{}, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		refreshTimes[time.Now().UnixNano()/int64(20*time.Millisecond)] = true
+		refreshes[key]++
+		return refreshes[key], nil
+	}); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	defer cache.StopRefresher()
+	if err := cache.StartRefresher(time.Second, nil); err != ErrRefresherAlreadyRunning {
+		t.Error("expected ErrRefresherAlreadyRunning, got", err)
+	}
+	time.Sleep(400 * time.Millisecond)
+	cache.StopRefresher()
+	for n := 0; n < 100; n++ {
+		key := fmt.Sprintf("%02d", n)
+		if value, ok := cache.Get(key); !ok || value.(int) < 1 {
+			t.Errorf("expected %s to have been refreshed, got %v", key, value)
+		}
+		if ttl, _ := cache.TTL(key); ttl > 300*time.Millisecond {
+			t.Errorf("expected %s to have been refreshed with its TTL, got %s", key, ttl)
+		}
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if refreshes["no-expiration"] != 0 {
+		t.Error("expected the entry without expiration not to have been refreshed")
+	}
+	if len(refreshTimes) < 3 {
+		t.Errorf("expected the refreshes to have been spread over the window, got %d distinct periods", len(refreshTimes))
+	}
+}
+
+func TestCache_StartRefresherWhenRefreshFails(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("key", "value", 50*time.Millisecond)
+	cache.StartRefresher(20*time.Millisecond, func(key string) (interface{}, error) {
+		return nil, errors.New("unavailable")
+	})
+	defer cache.StopRefresher()
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected the entry to have expired, since it could not be refreshed")
+	}
+}
+
+func TestRefreshOffset(t *testing.T) {
+	for n := 0; n < 100; n++ {
+		key := fmt.Sprintf("%02d", n)
+		if offset := refreshOffset(key, time.Minute, time.Hour); offset < 0 || offset >= time.Minute {
+			t.Errorf("expected the offset of %s to be within the window, got %s", key, offset)
+		}
+		if offset := refreshOffset(key, time.Minute, time.Minute); offset >= 30*time.Second {
+			t.Errorf("expected the offset of %s to be within the first half of the TTL, got %s", key, offset)
+		}
+	}
+	if offset := refreshOffset("key", 0, time.Hour); offset != 0 {
+		t.Errorf("expected no offset without window, got %s", offset)
+	}
+}
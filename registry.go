@@ -0,0 +1,117 @@
+package gocache
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry keeps track of caches by name, so that frameworks and admin endpoints can enumerate every cache of a
+// process without each cache having to be passed around to them
+type Registry struct {
+	mutex  sync.RWMutex
+	caches map[string]*Cache
+}
+
+// DefaultRegistry is the Registry used by the package-level Register, Unregister, Get, Names and AggregateStats
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{caches: make(map[string]*Cache)}
+}
+
+// Register adds a cache to the registry under the name passed as parameter
+//
+// Returns ErrCacheAlreadyRegistered if another cache is already registered under that name
+func (r *Registry) Register(name string, cache *Cache) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if existing, ok := r.caches[name]; ok && existing != cache {
+		return ErrCacheAlreadyRegistered
+	}
+	r.caches[name] = cache
+	return nil
+}
+
+// Unregister removes the cache registered under the name passed as parameter, if any
+func (r *Registry) Unregister(name string) {
+	r.mutex.Lock()
+	delete(r.caches, name)
+	r.mutex.Unlock()
+}
+
+// Get retrieves the cache registered under the name passed as parameter
+// If there is no such cache, the cache returned will be nil and the boolean will be false
+func (r *Registry) Get(name string) (*Cache, bool) {
+	r.mutex.RLock()
+	cache, ok := r.caches[name]
+	r.mutex.RUnlock()
+	return cache, ok
+}
+
+// Names returns the names of the registered caches, sorted alphabetically
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	names := make([]string, 0, len(r.caches))
+	for name := range r.caches {
+		names = append(names, name)
+	}
+	r.mutex.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Stats returns the sum of the statistics of every registered cache
+// The load latency percentiles cannot be summed, so they are left empty.
+func (r *Registry) Stats() Statistics {
+	var total Statistics
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, cache := range r.caches {
+		stats := cache.Stats()
+		total.EvictedKeys += stats.EvictedKeys
+		total.ExpiredKeys += stats.ExpiredKeys
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.StaleServes += stats.StaleServes
+		total.CorruptedEntries += stats.CorruptedEntries
+		total.NotAdmitted += stats.NotAdmitted
+		total.LongKeys += stats.LongKeys
+		total.IdenticalWrites += stats.IdenticalWrites
+		total.NetworkErrors += stats.NetworkErrors
+		total.FirstSeen += stats.FirstSeen
+		total.Duplicates += stats.Duplicates
+		total.Loads += stats.Loads
+		total.CoalescedLoads += stats.CoalescedLoads
+		total.LoadsInFlight += stats.LoadsInFlight
+	}
+	return total
+}
+
+// Register adds a cache to the DefaultRegistry under the name passed as parameter
+//
+// Returns ErrCacheAlreadyRegistered if another cache is already registered under that name
+func Register(name string, cache *Cache) error {
+	return DefaultRegistry.Register(name, cache)
+}
+
+// Unregister removes the cache registered under the name passed as parameter from the DefaultRegistry, if any
+func Unregister(name string) {
+	DefaultRegistry.Unregister(name)
+}
+
+// Get retrieves the cache registered under the name passed as parameter in the DefaultRegistry
+// If there is no such cache, the cache returned will be nil and the boolean will be false
+func Get(name string) (*Cache, bool) {
+	return DefaultRegistry.Get(name)
+}
+
+// Names returns the names of the caches registered in the DefaultRegistry, sorted alphabetically
+func Names() []string {
+	return DefaultRegistry.Names()
+}
+
+// AggregateStats returns the sum of the statistics of every cache registered in the DefaultRegistry
+func AggregateStats() Statistics {
+	return DefaultRegistry.Stats()
+}
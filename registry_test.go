@@ -0,0 +1,67 @@
+package gocache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	users, sessions := NewCache(), NewCache()
+	if err := registry.Register("users", users); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if err := registry.Register("sessions", sessions); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if err := registry.Register("users", users); err != nil {
+		t.Error("expected registering the same cache twice to be a no-op, got", err)
+	}
+	if err := registry.Register("users", sessions); err != ErrCacheAlreadyRegistered {
+		t.Errorf("expected %v, got %v", ErrCacheAlreadyRegistered, err)
+	}
+	if cache, ok := registry.Get("users"); !ok || cache != users {
+		t.Error("expected to retrieve the users cache")
+	}
+	if names := registry.Names(); !reflect.DeepEqual(names, []string{"sessions", "users"}) {
+		t.Errorf("expected [sessions users], got %v", names)
+	}
+	registry.Unregister("sessions")
+	if _, ok := registry.Get("sessions"); ok {
+		t.Error("expected sessions to have been unregistered")
+	}
+}
+
+func TestRegistry_Stats(t *testing.T) {
+	registry := NewRegistry()
+	users, sessions := NewCache(), NewCache()
+	registry.Register("users", users)
+	registry.Register("sessions", sessions)
+	users.Set("1", "john")
+	users.Get("1")
+	users.Get("2")
+	sessions.Set("1", "token")
+	sessions.Get("1")
+	stats := registry.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d and %d", stats.Hits, stats.Misses)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	cache := NewCache()
+	defer Unregister("test")
+	if err := Register("test", cache); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if registered, ok := Get("test"); !ok || registered != cache {
+		t.Error("expected to retrieve the cache from the default registry")
+	}
+	cache.Get("missing")
+	if AggregateStats().Misses != 1 {
+		t.Errorf("expected 1 miss, got %d", AggregateStats().Misses)
+	}
+	if names := Names(); len(names) != 1 || names[0] != "test" {
+		t.Errorf("expected [test], got %v", names)
+	}
+}
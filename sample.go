@@ -0,0 +1,89 @@
+package gocache
+
+// SampleKeys returns up to n keys picked uniformly at random, without replacement, and without iterating over the
+// entire cache, which makes it cheap to inspect the composition of the keyspace of a huge cache.
+// If n is greater than or equal to the number of entries, every key is returned.
+//
+// Expired entries that have not been deleted yet are picked like any other entry but left out of the result, so
+// fewer than n keys may be returned. Like GetKeysByPattern, this does not count as accessing the entries.
+func (c *Cache) SampleKeys(n int) []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var keys []string
+	for _, entry := range c.sample(n) {
+		if !entry.Expired() {
+			keys = append(keys, entry.Key)
+		}
+	}
+	return keys
+}
+
+// EstimateCountByPattern estimates the number of keys matching the given pattern from the proportion of matching keys
+// in a random sample of sampleSize keys (see SampleKeys), which is much cheaper than counting them on a huge cache.
+// The larger the sample, the more accurate the estimate. If sampleSize is greater than or equal to the number of
+// entries, the count is exact.
+func (c *Cache) EstimateCountByPattern(pattern string, sampleSize int) int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	sample := c.sample(sampleSize)
+	if len(sample) == 0 {
+		return 0
+	}
+	matches := 0
+	for _, entry := range sample {
+		if !entry.Expired() && MatchPattern(pattern, entry.Key) {
+			matches++
+		}
+	}
+	if len(sample) == len(c.sampleEntries) {
+		return matches
+	}
+	return int(float64(matches)/float64(len(sample))*float64(len(c.sampleEntries)) + 0.5)
+}
+
+// sample returns up to n entries picked uniformly at random, without replacement
+//
+// The caller must hold the lock.
+func (c *Cache) sample(n int) []*Entry {
+	if n <= 0 {
+		return nil
+	}
+	total := len(c.sampleEntries)
+	if n >= total {
+		return append([]*Entry(nil), c.sampleEntries...)
+	}
+	// Floyd's algorithm picks n distinct indexes with n random numbers, regardless of the number of entries
+	picked := make(map[int]bool, n)
+	sample := make([]*Entry, 0, n)
+	for i := total - n; i < total; i++ {
+		index := c.intn(i + 1)
+		if picked[index] {
+			index = i
+		}
+		picked[index] = true
+		sample = append(sample, c.sampleEntries[index])
+	}
+	return sample
+}
+
+// addToSample adds an entry to the entries SampleKeys picks from
+//
+// The caller must hold the lock.
+func (c *Cache) addToSample(entry *Entry) {
+	entry.sampleIndex = len(c.sampleEntries)
+	c.sampleEntries = append(c.sampleEntries, entry)
+}
+
+// removeFromSample removes an entry from the entries SampleKeys picks from, by moving the last entry in its place
+//
+// The caller must hold the lock.
+func (c *Cache) removeFromSample(entry *Entry) {
+	last := len(c.sampleEntries) - 1
+	if entry.sampleIndex > last || c.sampleEntries[entry.sampleIndex] != entry {
+		return
+	}
+	c.sampleEntries[entry.sampleIndex] = c.sampleEntries[last]
+	c.sampleEntries[entry.sampleIndex].sampleIndex = entry.sampleIndex
+	c.sampleEntries[last] = nil
+	c.sampleEntries = c.sampleEntries[:last]
+}
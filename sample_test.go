@@ -0,0 +1,90 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCache_SampleKeys(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithRaceAssertions(true))
+	for n := 0; n < 1000; n++ {
+		cache.Set(fmt.Sprintf("%03d", n), n)
+	}
+	for n := 0; n < 1000; n += 2 {
+		cache.Delete(fmt.Sprintf("%03d", n))
+	}
+	keys := cache.SampleKeys(100)
+	if len(keys) != 100 {
+		t.Fatalf("expected 100 keys, got %d", len(keys))
+	}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("expected %s to only have been sampled once", key)
+		}
+		seen[key] = true
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %s to be in the cache", key)
+		}
+	}
+	if keys := cache.SampleKeys(10000); len(keys) != 500 {
+		t.Errorf("expected every key to be returned, got %d", len(keys))
+	}
+	if keys := cache.SampleKeys(0); len(keys) != 0 {
+		t.Errorf("expected no key, got %d", len(keys))
+	}
+	cache.Clear()
+	if keys := cache.SampleKeys(10); len(keys) != 0 {
+		t.Errorf("expected no key after Clear, got %d", len(keys))
+	}
+}
+
+func TestCache_SampleKeysIsUniform(t *testing.T) {
+	cache := NewCache()
+	for n := 0; n < 10; n++ {
+		cache.Set(fmt.Sprintf("%d", n), n)
+	}
+	occurrences := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		for _, key := range cache.SampleKeys(3) {
+			occurrences[key]++
+		}
+	}
+	// Each key is expected to be picked 3000 times
+	for key, count := range occurrences {
+		if count < 2700 || count > 3300 {
+			t.Errorf("expected %s to have been picked about 3000 times, got %d", key, count)
+		}
+	}
+}
+
+func TestCache_SampleKeysSkipsExpiredEntries(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	cache.Set("key", "value")
+	time.Sleep(time.Millisecond)
+	if keys := cache.SampleKeys(2); len(keys) != 1 || keys[0] != "key" {
+		t.Errorf("expected only key to be returned, got %v", keys)
+	}
+}
+
+func TestCache_EstimateCountByPattern(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithEvictionPolicy(LeastFrequentUsed), WithRaceAssertions(true))
+	for n := 0; n < 10000; n++ {
+		if n%4 == 0 {
+			cache.Set(fmt.Sprintf("session:%d", n), n)
+		} else {
+			cache.Set(fmt.Sprintf("user:%d", n), n)
+		}
+	}
+	if estimate := cache.EstimateCountByPattern("session:*", 2000); estimate < 2200 || estimate > 2800 {
+		t.Errorf("expected an estimate of about 2500, got %d", estimate)
+	}
+	if count := cache.EstimateCountByPattern("session:*", 10000); count != 2500 {
+		t.Errorf("expected an exact count of 2500, got %d", count)
+	}
+	if count := NewCache().EstimateCountByPattern("*", 100); count != 0 {
+		t.Errorf("expected 0 for an empty cache, got %d", count)
+	}
+}
@@ -0,0 +1,86 @@
+package gocache
+
+import "hash/fnv"
+
+const (
+	// ScanBuckets is the number of buckets the keys are distributed in for Scan, each call to Scan returning the keys
+	// of one or more buckets
+	ScanBuckets = 4096
+
+	// ScanDefaultCount is the number of keys Scan aims to return if the count passed as parameter is 0 or less
+	ScanDefaultCount = 10
+
+	// scanBucketBits is the number of bits of the hash of a key used to determine its bucket
+	scanBucketBits = 12
+)
+
+// Scan incrementally iterates over the keys that match the given pattern, in the same way as the SCAN command of
+// Redis: the first call must be made with a cursor of 0, and every subsequent call with the cursor returned by the
+// previous call, until the cursor returned is 0 again, which means that the iteration is complete.
+//
+// Unlike iterating over a snapshot of the keys, Scan never holds the lock for more than one call and does not copy
+// every key, while still guaranteeing that every key present in the cache from the start to the end of the iteration
+// is returned exactly once, no matter how many entries are set, deleted or evicted in the meantime. Keys that are
+// added or removed during the iteration may or may not be returned. To do so, keys are distributed in ScanBuckets
+// buckets by the hash of their key, and each call returns the keys of whole buckets, in order.
+//
+// Each call aims to return count keys, but as buckets are never split, it may return more. It may also return fewer
+// keys, or none at all, if the buckets it walked did not have enough keys matching the pattern, which does not mean
+// that the iteration is complete. If count is 0 or less, ScanDefaultCount is used.
+// Expired entries are skipped. Like GetKeysByPattern, this does not count as accessing the entries.
+func (c *Cache) Scan(cursor uint64, pattern string, count int) ([]string, uint64) {
+	if count <= 0 {
+		count = ScanDefaultCount
+	}
+	var keys []string
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	// Like Redis, the number of buckets walked is bounded, so that a call on a sparse cache returns quickly
+	for walked := 0; cursor < uint64(len(c.scanBuckets)) && walked < count*10; walked++ {
+		bucket := c.scanBuckets[cursor]
+		cursor++
+		for key := range bucket {
+			if entry := c.entries[key]; !entry.Expired() && MatchPattern(pattern, key) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) >= count {
+			break
+		}
+	}
+	if cursor >= uint64(len(c.scanBuckets)) {
+		cursor = 0
+	}
+	return keys, cursor
+}
+
+// scanBucketOf returns the index of the bucket of scanBuckets the given key belongs to
+func scanBucketOf(key string) uint16 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return uint16(hash.Sum64() >> (64 - scanBucketBits))
+}
+
+// addToScanBuckets adds the key of an entry to the bucket Scan finds it in
+//
+// The caller must hold the lock.
+func (c *Cache) addToScanBuckets(entry *Entry) {
+	if c.scanBuckets == nil {
+		c.scanBuckets = make([]map[string]struct{}, ScanBuckets)
+	}
+	entry.scanBucket = scanBucketOf(entry.Key)
+	if c.scanBuckets[entry.scanBucket] == nil {
+		c.scanBuckets[entry.scanBucket] = make(map[string]struct{})
+	}
+	c.scanBuckets[entry.scanBucket][entry.Key] = struct{}{}
+}
+
+// removeFromScanBuckets removes the key of an entry from the bucket Scan finds it in
+//
+// The caller must hold the lock.
+func (c *Cache) removeFromScanBuckets(entry *Entry) {
+	if c.scanBuckets == nil {
+		return
+	}
+	delete(c.scanBuckets[entry.scanBucket], entry.Key)
+}
@@ -0,0 +1,88 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCache_Scan(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithRaceAssertions(true))
+	for n := 0; n < 1000; n++ {
+		cache.Set(fmt.Sprintf("key-%03d", n), n)
+	}
+	cache.Set("other", "value")
+	returned := make(map[string]int)
+	calls := 0
+	for cursor := uint64(0); ; {
+		var keys []string
+		keys, cursor = cache.Scan(cursor, "key-*", 50)
+		calls++
+		for _, key := range keys {
+			returned[key]++
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	if len(returned) != 1000 {
+		t.Errorf("expected 1000 keys to have been returned, got %d", len(returned))
+	}
+	for key, count := range returned {
+		if count != 1 {
+			t.Errorf("expected %s to have been returned once, got %d", key, count)
+		}
+	}
+	if calls < 10 {
+		t.Errorf("expected the scan to have been split over multiple calls, got %d", calls)
+	}
+}
+
+func TestCache_ScanWhileEntriesChurn(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithRaceAssertions(true))
+	for n := 0; n < 1000; n++ {
+		cache.Set(fmt.Sprintf("stable-%03d", n), n)
+	}
+	returned := make(map[string]int)
+	churn := 0
+	for cursor := uint64(0); ; {
+		var keys []string
+		keys, cursor = cache.Scan(cursor, "*", 20)
+		for _, key := range keys {
+			returned[key]++
+		}
+		// Entries are added and removed between every call, which must not affect the keys that stay in the cache
+		for i := 0; i < 50; i++ {
+			cache.Set(fmt.Sprintf("churn-%d", churn), churn)
+			if churn >= 25 {
+				cache.Delete(fmt.Sprintf("churn-%d", churn-25))
+			}
+			churn++
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	for n := 0; n < 1000; n++ {
+		if key := fmt.Sprintf("stable-%03d", n); returned[key] != 1 {
+			t.Errorf("expected %s to have been returned once, got %d", key, returned[key])
+		}
+	}
+}
+
+func TestCache_ScanWhenCacheIsClearedDuringScan(t *testing.T) {
+	cache := NewCache()
+	for n := 0; n < 100; n++ {
+		cache.Set(fmt.Sprintf("%02d", n), n)
+	}
+	_, cursor := cache.Scan(0, "*", 1)
+	if cursor == 0 {
+		t.Fatal("expected the scan not to be complete")
+	}
+	cache.Clear()
+	if keys, cursor := cache.Scan(cursor, "*", 1); len(keys) != 0 || cursor != 0 {
+		t.Errorf("expected the scan to be complete, got %v and %d", keys, cursor)
+	}
+	if keys, cursor := NewCache().Scan(0, "*", 0); len(keys) != 0 || cursor != 0 {
+		t.Errorf("expected no key for an empty cache, got %v and %d", keys, cursor)
+	}
+}
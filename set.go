@@ -1,44 +1,175 @@
 package gocache
 
 import (
+	"context"
 	"reflect"
 	"time"
 )
 
 // Set creates or updates a key with a given value
-func (c *Cache) Set(key string, value interface{}) {
-	c.SetWithTTL(key, value, NoExpiration)
+// The entry never expires, unless the value is a TTLer, in which case the TTL it returns is used
+//
+// Returns ErrCacheFull if the cache is full and its FullBehavior is RejectWrites
+func (c *Cache) Set(key string, value interface{}) error {
+	return c.SetWithTTL(key, value, ttlOf(value))
 }
 
 // SetWithTTL creates or updates a key with a given value and sets an expiration time (-1 is NoExpiration)
 //
 // The TTL provided must be greater than 0, or NoExpiration (-1). If a negative value that isn't -1 (NoExpiration) is
 // provided, the entry will not be created if the key doesn't exist
-func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+//
+// Returns ErrCacheFull if the cache is full and its FullBehavior is RejectWrites, in which case the cache is left
+// untouched
+func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	return c.SetWithTTLCtx(context.Background(), key, value, ttl)
+}
+
+// SetWithTTLCtx is the same as SetWithTTL, but the context passed as parameter is used to attach a request ID to the
+// audit record of the write (see WithAuditLog)
+func (c *Cache) SetWithTTLCtx(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.setWithHooks(ctx, key, value, ttl, expirationOf(ttl), 0, nil)
+}
+
+// SetWithMinLifetime creates or updates a key with a given value and expiration time (-1 is NoExpiration), and
+// guarantees that the entry will not be evicted to make room for other entries for at least minLifetime.
+//
+// This is useful for values that are so expensive to compute that evicting them early is never worth it.
+// The TTL still applies, so an entry whose TTL is shorter than its minimum lifetime expires normally.
+// While pinned entries are skipped by evictions, the cache may temporarily exceed its MaxSize or MaxMemoryUsage if
+// every entry is pinned. Updating the entry through any other Set-like function removes the guarantee.
+//
+// Returns ErrCacheFull if the cache is full and its FullBehavior is RejectWrites
+func (c *Cache) SetWithMinLifetime(key string, value interface{}, minLifetime, ttl time.Duration) error {
+	return c.setWithHooks(context.Background(), key, value, ttl, expirationOf(ttl), minLifetime, nil)
+}
+
+// SetWithExpiration creates or updates a key with a given value that expires at the given time, which is more precise
+// than SetWithTTL for deadlines dictated by an external source, such as the expiration time of a token or the
+// Expires header of an HTTP response
+//
+// The zero time means that the entry never expires. If the expiration time has already passed, the entry is not
+// created, or deleted if it exists.
+//
+// Returns ErrCacheFull if the cache is full and its FullBehavior is RejectWrites
+func (c *Cache) SetWithExpiration(key string, value interface{}, expireAt time.Time) error {
+	if expireAt.IsZero() {
+		return c.setWithHooks(context.Background(), key, value, NoExpiration, NoExpiration, 0, nil)
+	}
+	ttl := time.Until(expireAt)
+	if ttl < 1 {
+		return c.setWithHooks(context.Background(), key, value, ttl, expiresInstantly, 0, nil)
+	}
+	return c.setWithHooks(context.Background(), key, value, ttl, expireAt.UnixNano(), 0, nil)
+}
+
+// setWithHooks invokes the BeforeSet and AfterSet hooks around set, and records the write in the audit log
+// The key passed as parameter is the key given by the caller, which is converted to the key it is stored under.
+// The ttl is only passed to the hooks and the audit log, the expiration being what determines when the entry expires.
+func (c *Cache) setWithHooks(ctx context.Context, key string, value interface{}, ttl time.Duration, expiration int64, minLifetime time.Duration, metadata map[string]string) error {
+	storageKey, err := c.writeKey(key)
+	if err != nil {
+		if err == errNeverCached {
+			return nil
+		}
+		return err
+	}
+	return c.setStoredWithHooks(ctx, key, storageKey, value, ttl, expiration, minLifetime, metadata)
+}
+
+// setStoredWithHooks is the same as setWithHooks, but the key passed as parameter is the key the entry is stored under,
+// the callerKey being the key given by the caller, or the same key if it is unknown, such as when restoring entries
+func (c *Cache) setStoredWithHooks(ctx context.Context, callerKey, key string, value interface{}, ttl time.Duration, expiration int64, minLifetime time.Duration, metadata map[string]string) error {
+	return c.writeWithHooks(ctx, key, value, ttl, func() error {
+		return c.set(callerKey, key, value, expiration, minLifetime, metadata)
+	})
+}
+
+// writeWithHooks invokes the BeforeSet and AfterSet hooks around write, which writes the value under the key the
+// entry is stored under, and records the write in the audit log
+func (c *Cache) writeWithHooks(ctx context.Context, key string, value interface{}, ttl time.Duration, write func() error) error {
+	defer c.endOp(OpSet, key, c.startOp())
+	if c.hooks != nil {
+		c.hooks.BeforeSet(key, value, ttl)
+	}
+	err := write()
+	if c.hooks != nil {
+		c.hooks.AfterSet(key, value, ttl, err)
+	}
+	if err == nil && c.audits(OpSet) {
+		record := AuditRecord{Key: key, Size: toBytes(value)}
+		if ttl != NoExpiration {
+			record.TTL = ttl.String()
+		}
+		c.audit(ctx, OpSet, record)
+	}
+	return err
+}
+
+// set creates or updates a key with a given value, expiration time (unix time in nanoseconds, or NoExpiration),
+// minimum lifetime and metadata, evicting entries if necessary
+// The key is the key the entry is stored under, while the callerKey is the key given by the caller (see writeKey).
+func (c *Cache) set(callerKey, key string, value interface{}, expiration int64, minLifetime time.Duration, metadata map[string]string) error {
+	value = c.normalizeNil(value)
+	c.mutex.Lock()
+	err := c.setLocked(callerKey, key, value, expiration, minLifetime, metadata)
+	c.mutex.Unlock()
+	return err
+}
+
+// normalizeNil returns nil if the value passed as parameter is a nil pointer and forcing nil interfaces is
+// enabled (see WithForceNilInterfaceOnNilPointer), and the value itself otherwise
+func (c *Cache) normalizeNil(value interface{}) interface{} {
 	// An interface is only nil if both its value and its type are nil, however, passing a nil pointer as an interface{}
 	// means that the interface itself is not nil, because the interface value is nil but not the type.
 	if c.forceNilInterfaceOnNilPointer {
 		if value != nil && (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
-			value = nil
+			return nil
 		}
 	}
+	return value
+}
 
-	c.mutex.Lock()
+// setLocked is the same as set, but the caller must hold the lock
+func (c *Cache) setLocked(callerKey, key string, value interface{}, expiration int64, minLifetime time.Duration, metadata map[string]string) error {
+	if c.draining {
+		return ErrDraining
+	}
+	if c.entries == nil {
+		// The zero value of Cache is ready to use, so the map is created on the first write
+		c.entries = make(map[string]*Entry)
+	}
 	entry, ok := c.get(key)
 	if !ok {
 		// A negative TTL that isn't -1 (NoExpiration) or 0 is an entry that will expire instantly,
 		// so might as well just not create it in the first place
-		if ttl != NoExpiration && ttl < 1 {
-			c.mutex.Unlock()
-			return
+		if expiration == expiresInstantly {
+			if c.traced(key) {
+				c.trace(key, "skip", "reason=expired")
+			}
+			return nil
 		}
-		// Cache entry doesn't exist, so we have to create a new one
-		entry = &Entry{
-			Key:               key,
-			Value:             value,
-			RelevantTimestamp: time.Now(),
-			next:              c.head,
+		if c.admissionFilter != nil && !c.admissionFilter(callerKey, value, toBytes(value)) {
+			c.stats.NotAdmitted++
+			if c.traced(key) {
+				c.trace(key, "skip", "reason=not-admitted")
+			}
+			return nil
+		}
+		if c.fullBehavior == RejectWrites && c.isFullFor(key, value, nil) {
+			if c.traced(key) {
+				c.trace(key, "skip", "reason=cache-full")
+			}
+			return ErrCacheFull
 		}
+		// Cache entry doesn't exist, so we have to create a new one
+		entry = c.newEntry()
+		entry.Key = key
+		entry.Value = value
+		entry.RelevantTimestamp = time.Now()
+		entry.createdAt = entry.RelevantTimestamp.UnixNano()
+		entry.updatedAt = entry.createdAt
+		entry.next = c.head
 		if c.head == nil {
 			c.tail = entry
 		} else {
@@ -46,62 +177,293 @@ func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
 		}
 		c.head = entry
 		c.entries[key] = entry
+		c.addToSample(entry)
+		c.addToScanBuckets(entry)
+		c.tag(entry)
 		if c.maxMemoryUsage != NoMaxMemoryUsage {
 			c.memoryUsage += entry.SizeInBytes()
 		}
 	} else {
 		// A negative TTL that isn't -1 (NoExpiration) or 0 is an entry that will expire instantly,
 		// so might as well just delete it immediately instead of updating it
-		if ttl != NoExpiration && ttl < 1 {
+		if expiration == expiresInstantly {
+			if c.traced(key) {
+				c.trace(key, "delete", "reason=expired")
+			}
 			c.delete(key)
-			c.mutex.Unlock()
-			return
+			c.assertInvariants()
+			return nil
+		}
+		if c.identical != nil && !entry.Expired() && c.identical(entry.Value, value) {
+			c.stats.IdenticalWrites++
+			if c.traced(key) {
+				c.trace(key, "skip", "reason=identical")
+			}
+			return nil
+		}
+		if c.fullBehavior == RejectWrites && c.isFullFor(key, value, entry) {
+			if c.traced(key) {
+				c.trace(key, "skip", "reason=cache-full")
+			}
+			return ErrCacheFull
 		}
 		if c.maxMemoryUsage != NoMaxMemoryUsage {
 			// Subtract the old entry from the cache's memoryUsage
 			c.memoryUsage -= entry.SizeInBytes()
 		}
 		// Update existing entry's value
+		c.untag(entry)
 		entry.Value = value
+		c.tag(entry)
 		entry.RelevantTimestamp = time.Now()
+		entry.updatedAt = entry.RelevantTimestamp.UnixNano()
 		if c.maxMemoryUsage != NoMaxMemoryUsage {
 			// Add the memory usage of the new entry to the cache's memoryUsage
 			c.memoryUsage += entry.SizeInBytes()
 		}
-		// Because we just updated the entry, we need to move it back to HEAD
-		c.moveExistingEntryToHead(entry)
+		if c.evictionPolicy == Sieve {
+			// Sieve never moves entries, updating the entry counts as accessing it instead
+			entry.visited = true
+		} else {
+			// Because we just updated the entry, we need to move it back to HEAD
+			c.moveExistingEntryToHead(entry)
+		}
 	}
-	if ttl != NoExpiration {
-		entry.Expiration = time.Now().Add(ttl).UnixNano()
-	} else {
-		entry.Expiration = NoExpiration
+	entry.Expiration = c.bucketed(expiration)
+	if c.traced(key) {
+		if ok {
+			c.trace(key, "set", "updated", traceExpiration(expiration))
+		} else {
+			c.trace(key, "set", "created", traceExpiration(expiration))
+		}
 	}
-	// If the cache doesn't have a maxSize/maxMemoryUsage, then there's no point
-	// checking if we need to evict an entry, so we'll just return now
-	if c.maxSize == NoMaxSize && c.maxMemoryUsage == NoMaxMemoryUsage {
-		c.mutex.Unlock()
-		return
+	c.scheduleExpiration(entry)
+	entry.metadata = metadata
+	entry.retained = false
+	entry.softExpiration = 0
+	entry.refreshRequested = false
+	if minLifetime > 0 {
+		entry.pinnedUntil = time.Now().Add(minLifetime).UnixNano()
+	} else {
+		entry.pinnedUntil = 0
 	}
 	// If there's a maxSize and the cache has more entries than the maxSize, evict
 	if c.maxSize != NoMaxSize && len(c.entries) > c.maxSize {
-		c.evict()
+		c.evict(entry)
 	}
 	// If there's a maxMemoryUsage and the memoryUsage is above the maxMemoryUsage, evict
 	if c.maxMemoryUsage != NoMaxMemoryUsage && c.memoryUsage > c.maxMemoryUsage {
-		for c.memoryUsage > c.maxMemoryUsage && len(c.entries) > 0 {
-			c.evict()
+		c.evictUntilBelowMaxMemoryUsage(entry)
+	}
+	if c.wakeReclaimer != nil && c.memoryUsage > c.reclaimerWatermark {
+		select {
+		case c.wakeReclaimer <- struct{}{}:
+		default:
 		}
 	}
 
-	if c.evictionPolicy == LeastFrequentUsed {
+	// The entry itself may have been evicted if it was an existing entry, in which case it must not be added back
+	// to the frequency list
+	if c.evictionPolicy == LeastFrequentUsed && c.entries[key] == entry {
 		c.incrementEntryFrequency(entry)
 	}
-	c.mutex.Unlock()
+	if c.evictionPolicy == LRUK && c.entries[key] == entry {
+		c.recordAccess(entry)
+	}
+	if len(c.setSubscriptions) > 0 {
+		c.notifySetSubscribers(callerKey, key, value)
+	}
+	if c.usageAlert != nil {
+		c.checkUsage()
+	}
+	c.assertInvariants()
+	return nil
+}
+
+// Replace updates the value of a key only if it exists, while preserving its expiration time as well as its position
+// according to the eviction policy, which means that unlike the Set-like functions, replacing a value doesn't count
+// as accessing it.
+//
+// Returns false if the key doesn't exist or has expired, or if the cache is full and its FullBehavior is RejectWrites,
+// in which case the cache is left untouched.
+func (c *Cache) Replace(key string, value interface{}) bool {
+	key, err := c.writeKey(key)
+	if err != nil {
+		// The key is never stored, so it cannot exist
+		return false
+	}
+	defer c.endOp(OpSet, key, c.startOp())
+	value = c.normalizeNil(value)
+	if c.hooks != nil {
+		// BeforeSet must be called without holding the lock, so the TTL it receives is read beforehand, however,
+		// whether the key still exists is decided by replace, under the same lock as the write
+		c.mutex.RLock()
+		entry, ok := c.get(key)
+		ok = ok && !entry.Expired()
+		var ttl time.Duration
+		if ok {
+			ttl = remainingTTL(entry)
+		}
+		c.mutex.RUnlock()
+		if !ok {
+			return false
+		}
+		c.hooks.BeforeSet(key, value, ttl)
+	}
+	ttl, err := c.replace(key, value)
+	if c.hooks != nil {
+		c.hooks.AfterSet(key, value, ttl, err)
+	}
+	if err == nil && c.audits(OpSet) {
+		record := AuditRecord{Key: key, Size: toBytes(value)}
+		if ttl != NoExpiration {
+			record.TTL = ttl.String()
+		}
+		c.audit(context.Background(), OpSet, record)
+	}
+	return err == nil
+}
+
+// replace updates the value of an existing entry without changing its expiration time nor its position, and returns
+// the remaining TTL of the entry, or ErrKeyDoesNotExist if the entry doesn't exist or has expired, or ErrCacheFull if
+// the cache is full and its FullBehavior is RejectWrites
+func (c *Cache) replace(key string, value interface{}) (time.Duration, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.draining {
+		return 0, ErrDraining
+	}
+	entry, ok := c.get(key)
+	if !ok || entry.Expired() {
+		return 0, ErrKeyDoesNotExist
+	}
+	ttl := remainingTTL(entry)
+	if c.fullBehavior == RejectWrites && c.isFullFor(key, value, entry) {
+		return ttl, ErrCacheFull
+	}
+	if c.maxMemoryUsage != NoMaxMemoryUsage {
+		c.memoryUsage -= entry.SizeInBytes()
+	}
+	c.untag(entry)
+	entry.Value = value
+	c.tag(entry)
+	entry.updatedAt = time.Now().UnixNano()
+	if c.maxMemoryUsage != NoMaxMemoryUsage {
+		c.memoryUsage += entry.SizeInBytes()
+		if c.memoryUsage > c.maxMemoryUsage {
+			c.evictUntilBelowMaxMemoryUsage(entry)
+		}
+	}
+	c.assertInvariants()
+	return ttl, nil
+}
+
+// remainingTTL returns how long until an entry that hasn't expired expires, or NoExpiration if it never does
+func remainingTTL(entry *Entry) time.Duration {
+	if entry.Expiration == NoExpiration {
+		return NoExpiration
+	}
+	return time.Until(time.Unix(0, entry.Expiration))
 }
 
 // SetAll creates or updates multiple values
-func (c *Cache) SetAll(entries map[string]interface{}) {
+// Like Set, values that are TTLers expire after the TTL they return
+//
+// If the cache's FullBehavior is RejectWrites, entries that do not fit are skipped and ErrCacheFull is returned once
+// all entries have been processed
+func (c *Cache) SetAll(entries map[string]interface{}) error {
+	var err error
+	for key, value := range entries {
+		if setErr := c.SetWithTTL(key, value, ttlOf(value)); setErr != nil {
+			err = setErr
+		}
+	}
+	return err
+}
+
+// SetAllWithTTL creates or updates multiple values, all of which share the same expiration time (-1 is NoExpiration)
+//
+// If the cache's FullBehavior is RejectWrites, entries that do not fit are skipped and ErrCacheFull is returned once
+// all entries have been processed
+func (c *Cache) SetAllWithTTL(entries map[string]interface{}, ttl time.Duration) error {
+	var err error
 	for key, value := range entries {
-		c.SetWithTTL(key, value, NoExpiration)
+		if setErr := c.SetWithTTL(key, value, ttl); setErr != nil {
+			err = setErr
+		}
+	}
+	return err
+}
+
+// EntryInput is an entry to be created or updated through SetAllEntries
+type EntryInput struct {
+	// Key is the name of the cache entry
+	Key string
+
+	// Value is the value of the cache entry
+	Value interface{}
+
+	// TTL is the time until the cache entry expires (-1 is NoExpiration)
+	//
+	// Note that the zero value will cause the entry not to be created, just like passing 0 to SetWithTTL would,
+	// so NoExpiration must be set explicitly for entries that should never expire
+	TTL time.Duration
+}
+
+// SetAllEntries creates or updates multiple entries, each with its own expiration time
+//
+// Entries are written in the order they are given, so if the same key is present more than once, the last one wins.
+// If the cache's FullBehavior is RejectWrites, entries that do not fit are skipped and ErrCacheFull is returned once
+// all entries have been processed
+func (c *Cache) SetAllEntries(entries []EntryInput) error {
+	var err error
+	for _, entry := range entries {
+		if setErr := c.SetWithTTL(entry.Key, entry.Value, entry.TTL); setErr != nil {
+			err = setErr
+		}
+	}
+	return err
+}
+
+// newEntry returns an empty entry, taking it from the pre-allocated entries if there are any left
+func (c *Cache) newEntry() *Entry {
+	if len(c.preallocatedEntries) == 0 {
+		return &Entry{}
+	}
+	entry := &c.preallocatedEntries[0]
+	c.preallocatedEntries = c.preallocatedEntries[1:]
+	return entry
+}
+
+// isFullFor returns whether writing the given value under the given key would push the cache above its maxSize or
+// maxMemoryUsage. existing is the entry currently stored under the key, or nil if there is none.
+func (c *Cache) isFullFor(key string, value interface{}, existing *Entry) bool {
+	if existing == nil && c.maxSize != NoMaxSize && len(c.entries) >= c.maxSize {
+		return true
+	}
+	if c.maxMemoryUsage != NoMaxMemoryUsage {
+		newUsage := c.memoryUsage + (&Entry{Key: key, Value: value}).SizeInBytes()
+		if existing != nil {
+			newUsage -= existing.SizeInBytes()
+		}
+		if newUsage > c.maxMemoryUsage {
+			return true
+		}
+	}
+	return false
+}
+
+// expiresInstantly is the expiration passed to set for entries that would expire as soon as they are written
+const expiresInstantly = 0
+
+// expirationOf returns the unix time in nanoseconds at which an entry set with the given TTL expires, NoExpiration if
+// the TTL is NoExpiration, or expiresInstantly if the TTL is 0 or negative
+func expirationOf(ttl time.Duration) int64 {
+	if ttl == NoExpiration {
+		return NoExpiration
+	}
+	if ttl < 1 {
+		return expiresInstantly
 	}
+	return time.Now().Add(ttl).UnixNano()
 }
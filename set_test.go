@@ -2,6 +2,10 @@ package gocache
 
 import (
 	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	"testing"
 )
 
@@ -217,3 +221,245 @@ func TestCache_SetWithTTLWhenTTLIsZeroAndEntryAlreadyExists(t *testing.T) {
 		t.Error("expected key to not exist, because there's the entry was created with a TTL of 0, so it should have been deleted immediately")
 	}
 }
+
+func TestCache_SetWithFullBehaviorRejectWrites(t *testing.T) {
+	cache := NewCache(WithMaxSize(2), WithFullBehavior(RejectWrites))
+	if err := cache.Set("1", "v"); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	if err := cache.Set("2", "v"); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	if err := cache.Set("3", "v"); err != ErrCacheFull {
+		t.Error("expected ErrCacheFull, got", err)
+	}
+	if _, ok := cache.Get("3"); ok {
+		t.Error("expected key 3 not to have been created")
+	}
+	if cache.Count() != 2 {
+		t.Error("expected cache size to be 2, got", cache.Count())
+	}
+	if cache.Stats().EvictedKeys != 0 {
+		t.Error("expected no keys to have been evicted, got", cache.Stats().EvictedKeys)
+	}
+	if err := cache.Set("1", "updated"); err != nil {
+		t.Error("expected updating an existing key to be allowed, got", err)
+	}
+	cache.Delete("2")
+	if err := cache.Set("3", "v"); err != nil {
+		t.Error("expected no error after making room, got", err)
+	}
+}
+
+func TestCache_SetWithFullBehaviorRejectWritesAndMaxMemoryUsage(t *testing.T) {
+	const ValueSize = Kilobyte
+	cache := NewCache(WithMaxSize(0), WithMaxMemoryUsage(Kilobyte*4), WithFullBehavior(RejectWrites))
+	for i := 0; i < 3; i++ {
+		if err := cache.Set(fmt.Sprintf("%d", i), strings.Repeat("0", ValueSize)); err != nil {
+			t.Error("expected no error, got", err)
+		}
+	}
+	if err := cache.Set("3", strings.Repeat("0", ValueSize)); err != ErrCacheFull {
+		t.Error("expected ErrCacheFull, got", err)
+	}
+	if err := cache.Set("0", strings.Repeat("0", ValueSize*2)); err != ErrCacheFull {
+		t.Error("expected growing an existing entry past the limit to return ErrCacheFull, got", err)
+	}
+	if value, _ := cache.Get("0"); len(value.(string)) != ValueSize {
+		t.Error("expected key 0 to have kept its old value")
+	}
+	if cache.MemoryUsage() > cache.MaxMemoryUsage() {
+		t.Error("expected memory usage to be below the limit, got", cache.MemoryUsage())
+	}
+	if err := cache.SetAll(map[string]interface{}{"4": strings.Repeat("0", ValueSize), "5": "small"}); err != ErrCacheFull {
+		t.Error("expected SetAll to return ErrCacheFull, got", err)
+	}
+}
+
+func TestCache_SetAllWithTTL(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize))
+	cache.SetAllWithTTL(map[string]interface{}{"k1": "v1", "k2": "v2"}, time.Hour)
+	for _, key := range []string{"k1", "k2"} {
+		ttl, err := cache.TTL(key)
+		if err != nil {
+			t.Errorf("expected key %s to have a TTL, got error %v", key, err)
+		}
+		if ttl.Minutes() < 59 || ttl.Minutes() > 60 {
+			t.Errorf("expected the TTL of key %s to be almost an hour, got %s", key, ttl)
+		}
+	}
+}
+
+func TestCache_SetAllEntries(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize))
+	cache.SetAllEntries([]EntryInput{
+		{Key: "k1", Value: "v1", TTL: time.Hour},
+		{Key: "k2", Value: "v2", TTL: NoExpiration},
+		{Key: "k3", Value: "v3", TTL: time.Millisecond},
+		{Key: "k4", Value: "v4"},
+	})
+	if ttl, err := cache.TTL("k1"); err != nil || ttl.Minutes() < 59 {
+		t.Error("expected k1 to expire in almost an hour")
+	}
+	if _, err := cache.TTL("k2"); err != ErrKeyHasNoExpiration {
+		t.Error("expected k2 to have no expiration")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cache.Get("k3"); ok {
+		t.Error("expected k3 to have expired")
+	}
+	if _, ok := cache.Get("k4"); ok {
+		t.Error("expected k4 not to have been created, because its TTL was 0")
+	}
+}
+
+func TestCache_SetWithMinLifetime(t *testing.T) {
+	for _, policy := range []EvictionPolicy{FirstInFirstOut, LeastRecentlyUsed, LeastFrequentUsed} {
+		t.Run(fmt.Sprint(policy), func(t *testing.T) {
+			cache := NewCache(WithMaxSize(3), WithEvictionPolicy(policy), WithRaceAssertions(true))
+			cache.SetWithMinLifetime("pinned", "value", time.Hour, NoExpiration)
+			for i := 0; i < 10; i++ {
+				cache.Set(strconv.Itoa(i), "value")
+			}
+			if _, ok := cache.Get("pinned"); !ok {
+				t.Error("expected the pinned entry not to have been evicted")
+			}
+			if cache.Count() != 3 {
+				t.Errorf("expected the cache to still be capped at 3 entries, got %d", cache.Count())
+			}
+		})
+	}
+}
+
+func TestCache_SetWithMinLifetimeWhenPinExpires(t *testing.T) {
+	cache := NewCache(WithMaxSize(2))
+	cache.SetWithMinLifetime("pinned", "value", time.Millisecond, NoExpiration)
+	time.Sleep(2 * time.Millisecond)
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	if _, ok := cache.Get("pinned"); ok {
+		t.Error("expected the entry to be evictable once its minimum lifetime has passed")
+	}
+	cache.SetWithMinLifetime("short-ttl", "value", time.Hour, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cache.Get("short-ttl"); ok {
+		t.Error("expected the TTL to still apply to pinned entries")
+	}
+}
+
+func TestCache_SetWithMinLifetimeWhenEveryEntryIsPinned(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMaxMemoryUsage(200), WithRaceAssertions(true))
+	cache.SetWithMinLifetime("1", strings.Repeat("x", 80), time.Hour, NoExpiration)
+	cache.SetWithMinLifetime("2", strings.Repeat("x", 80), time.Hour, NoExpiration)
+	if cache.Count() != 2 || cache.MemoryUsage() <= cache.MaxMemoryUsage() {
+		t.Errorf("expected the cache to exceed its max memory usage rather than evict pinned entries, got %d entries and %d bytes", cache.Count(), cache.MemoryUsage())
+	}
+	cache.Set("3", "value")
+	if _, ok := cache.Get("3"); ok {
+		t.Error("expected the only unpinned entry to have been evicted")
+	}
+}
+
+func TestCache_SetWithExpiration(t *testing.T) {
+	cache := NewCache()
+	expireAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	cache.SetWithExpiration("key", "value", expireAt)
+	if entry := cache.entries["key"]; entry.Expiration != expireAt.UnixNano() {
+		t.Errorf("expected the entry to expire exactly at %s, got %s", expireAt, time.Unix(0, entry.Expiration))
+	}
+	cache.SetWithExpiration("never", "value", time.Time{})
+	if _, err := cache.TTL("never"); err != ErrKeyHasNoExpiration {
+		t.Error("expected the zero time to mean no expiration, got", err)
+	}
+	cache.SetWithExpiration("past", "value", time.Now().Add(-time.Second))
+	if _, ok := cache.Get("past"); ok || cache.Count() != 2 {
+		t.Error("expected an entry whose expiration time has passed not to be created")
+	}
+	cache.SetWithExpiration("key", "value", time.Now().Add(-time.Second))
+	if cache.Count() != 1 {
+		t.Error("expected an existing entry to be deleted when set with an expiration time that has passed")
+	}
+}
+
+func TestCache_Replace(t *testing.T) {
+	cache := NewCache(WithMaxSize(2), WithEvictionPolicy(LeastRecentlyUsed), WithRaceAssertions(true))
+	if cache.Replace("missing", "value") {
+		t.Error("expected Replace not to create a key that doesn't exist")
+	}
+	if cache.Count() != 0 {
+		t.Errorf("expected the cache to be empty, got %d entries", cache.Count())
+	}
+	cache.SetWithTTL("1", "value", time.Hour)
+	cache.Set("2", "value")
+	expirationBefore := cache.entries["1"].Expiration
+	if !cache.Replace("1", "replaced") {
+		t.Error("expected Replace to update 1")
+	}
+	if value := cache.entries["1"].Value; value != "replaced" {
+		t.Errorf("expected replaced, got %v", value)
+	}
+	if cache.entries["1"].Expiration != expirationBefore {
+		t.Error("expected Replace to preserve the expiration time")
+	}
+	// Replacing 1 must not have moved it to the head, so it is still the next to be evicted
+	cache.Set("3", "value")
+	if _, ok := cache.Get("1"); ok {
+		t.Error("expected 1 to have been evicted")
+	}
+}
+
+func TestCache_ReplaceWithExpiredKey(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("key", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if cache.Replace("key", "replaced") {
+		t.Error("expected Replace not to update an expired key")
+	}
+}
+
+func TestCache_ReplaceWithKeysThatAreNeverStored(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithHooks(hooks), WithKeyObfuscation([]byte("secret")), WithNeverCachePatterns("session:*"), WithMaxKeyLength(10))
+	if cache.Replace("session:1", "value") || cache.Replace(strings.Repeat("a", 11), "value") {
+		t.Error("expected Replace not to update keys that are never stored")
+	}
+	if len(hooks.events) != 0 {
+		t.Errorf("expected the hooks not to have been invoked, got %v", hooks.events)
+	}
+	if longKeys := cache.Stats().LongKeys; longKeys != 1 {
+		t.Error("expected the long key to have been counted, got", longKeys)
+	}
+}
+
+func TestCache_ReplaceWithHooks(t *testing.T) {
+	hooks := &recordingHooks{}
+	cache := NewCache(WithHooks(hooks))
+	cache.Replace("missing", "value")
+	if len(hooks.events) != 0 {
+		t.Errorf("expected the hooks not to have been invoked for a key that doesn't exist, got %v", hooks.events)
+	}
+	cache.Set("key", "value")
+	hooks.events = nil
+	if !cache.Replace("key", "replaced") {
+		t.Error("expected Replace to update key")
+	}
+	expected := "BeforeSet key=replaced,AfterSet key=replaced err=<nil>"
+	if events := strings.Join(hooks.events, ","); events != expected {
+		t.Errorf("expected %s, got %s", expected, events)
+	}
+}
+
+func TestCache_ReplaceWithMaxMemoryUsage(t *testing.T) {
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMaxMemoryUsage(Kilobyte), WithFullBehavior(RejectWrites))
+	cache.Set("key", "value")
+	if cache.Replace("key", strings.Repeat("x", 2*Kilobyte)) {
+		t.Error("expected Replace to be rejected because the value doesn't fit")
+	}
+	if value, _ := cache.Get("key"); value != "value" {
+		t.Errorf("expected the value to be left untouched, got %v", value)
+	}
+	memoryUsageBefore := cache.MemoryUsage()
+	if !cache.Replace("key", "other") || cache.MemoryUsage() != memoryUsageBefore {
+		t.Errorf("expected the memory usage to be %d after replacing a value of the same size, got %d", memoryUsageBefore, cache.MemoryUsage())
+	}
+}
@@ -0,0 +1,26 @@
+package gocache
+
+// nextSieveVictim moves the hand of the Sieve eviction policy to the next entry to evict and returns it, clearing the
+// visited flag of the entries it passes along the way. Entries that cannot be evicted, as well as the protected entry,
+// are passed over.
+//
+// Returns nil if no entry can be evicted. The caller must hold the lock.
+func (c *Cache) nextSieveVictim(now int64, protected *Entry) *Entry {
+	hand := c.sieveHand
+	// Every entry's visited flag is cleared on the first lap, so if no entry can be evicted after two laps, none can be
+	for steps := 0; steps < 2*len(c.entries); steps++ {
+		if hand == nil {
+			// Wrap around to the oldest entry once the hand has walked past the newest
+			hand = c.tail
+		}
+		if hand.visited {
+			hand.visited = false
+		} else if hand != protected && c.evictable(hand, now) {
+			c.sieveHand = hand
+			return hand
+		}
+		hand = hand.previous
+	}
+	c.sieveHand = hand
+	return nil
+}
@@ -0,0 +1,79 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCache_SieveGivesVisitedEntriesASecondChance(t *testing.T) {
+	cache := NewCache(WithMaxSize(3), WithEvictionPolicy(Sieve), WithRaceAssertions(true))
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	cache.Set("3", "value")
+	cache.Get("1")
+	// 1 is the oldest entry, but it was visited, so the hand passes over it and evicts 2 instead
+	cache.Set("4", "value")
+	if _, ok := cache.Get("2"); ok {
+		t.Error("expected 2 to have been evicted")
+	}
+	// The hand resumes from where it left off, so 3 is evicted next, even though 1 is no longer visited
+	cache.Set("5", "value")
+	if _, ok := cache.Get("3"); ok {
+		t.Error("expected 3 to have been evicted")
+	}
+	for _, key := range []string{"1", "4", "5"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %s not to have been evicted", key)
+		}
+	}
+}
+
+func TestCache_SieveDoesNotMoveEntries(t *testing.T) {
+	cache := NewCache(WithMaxSize(3), WithEvictionPolicy(Sieve), WithRaceAssertions(true))
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	cache.Get("1")
+	cache.Set("1", "updated")
+	if cache.head.Key != "2" || cache.tail.Key != "1" {
+		t.Errorf("expected the entries to stay in insertion order, got head=%s and tail=%s", cache.head.Key, cache.tail.Key)
+	}
+}
+
+func TestCache_SieveWhenEveryEntryIsVisited(t *testing.T) {
+	cache := NewCache(WithMaxSize(10), WithEvictionPolicy(Sieve), WithRaceAssertions(true))
+	for n := 0; n < 10; n++ {
+		cache.Set(fmt.Sprintf("%d", n), "value")
+		cache.Get(fmt.Sprintf("%d", n))
+	}
+	// Every entry is visited, so the hand clears them all and wraps around to evict the oldest one
+	cache.Set("new", "value")
+	if _, ok := cache.Get("0"); ok {
+		t.Error("expected 0 to have been evicted")
+	}
+	if _, ok := cache.Get("new"); !ok {
+		t.Error("expected the entry that was just written not to have been evicted")
+	}
+	if cache.Count() != 10 {
+		t.Errorf("expected 10 entries, got %d", cache.Count())
+	}
+}
+
+func TestCache_SieveWithDeletedHand(t *testing.T) {
+	cache := NewCache(WithMaxSize(3), WithEvictionPolicy(Sieve), WithRaceAssertions(true))
+	cache.Set("1", "value")
+	cache.Set("2", "value")
+	cache.Set("3", "value")
+	cache.Set("4", "value")
+	// The hand now points to 2, deleting it must move the hand rather than leave it dangling
+	cache.Delete("2")
+	cache.Set("5", "value")
+	cache.Set("6", "value")
+	if cache.Count() != 3 {
+		t.Errorf("expected 3 entries, got %d", cache.Count())
+	}
+	cache.Clear()
+	cache.Set("7", "value")
+	if _, ok := cache.Get("7"); !ok {
+		t.Error("expected 7 to be in the cache")
+	}
+}
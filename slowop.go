@@ -0,0 +1,106 @@
+package gocache
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// SlowOpCapacity is the maximum number of slow operations retained by the cache (see WithSlowOpThreshold)
+// Once it is reached, the oldest slow operation is discarded for each new one.
+const SlowOpCapacity = 128
+
+// SlowOp is an operation that took longer than the threshold set through WithSlowOpThreshold, as returned by SlowOps
+type SlowOp struct {
+	// Time is the time at which the operation started
+	Time time.Time
+
+	// Op is the operation, which is either OpGet, OpSet or OpDelete
+	Op OpMask
+
+	// Key is the key the operation applied to, which is the key the entry is stored under
+	Key string
+
+	// Duration is how long the operation took, including the time spent waiting for the lock and running the hooks
+	Duration time.Duration
+
+	// Stack is the stack trace of the goroutine that performed the operation, if WithSlowOpStacks is used
+	Stack string
+}
+
+// WithSlowOpThreshold makes the cache record the retrievals through Get and GetCtx, the writes through the Set-like
+// functions and the deletions through Delete and DeleteCtx that took longer than the threshold passed as parameter,
+// like the SLOWLOG of Redis, so that sporadic latency spikes can be diagnosed in production. The last SlowOpCapacity
+// slow operations can be retrieved through SlowOps.
+//
+// Defaults to 0, meaning that slow operations are not recorded, and that the duration of operations isn't measured.
+func WithSlowOpThreshold(threshold time.Duration) func(c *Cache) {
+	return func(c *Cache) {
+		if threshold < 0 {
+			threshold = 0
+		}
+		c.slowOpThreshold = threshold
+	}
+}
+
+// WithSlowOpStacks sets whether the stack trace of the goroutine that performed a slow operation is recorded along
+// with it, which shows where the operation came from at the cost of making slow operations even slower.
+// Only relevant if WithSlowOpThreshold is used. Defaults to false
+func WithSlowOpStacks(stacks bool) func(c *Cache) {
+	return func(c *Cache) {
+		c.slowOpStacks = stacks
+	}
+}
+
+// SlowOps returns the slow operations recorded since the cache was created (see WithSlowOpThreshold), from the most
+// recent to the oldest, up to SlowOpCapacity of them
+func (c *Cache) SlowOps() []SlowOp {
+	c.slowOpsMutex.Lock()
+	defer c.slowOpsMutex.Unlock()
+	slowOps := make([]SlowOp, 0, len(c.slowOps))
+	for i := 1; i <= len(c.slowOps); i++ {
+		slowOps = append(slowOps, c.slowOps[(c.slowOpsRecorded-uint64(i))%SlowOpCapacity])
+	}
+	return slowOps
+}
+
+// ResetSlowOps discards the slow operations recorded so far
+func (c *Cache) ResetSlowOps() {
+	c.slowOpsMutex.Lock()
+	c.slowOps = nil
+	c.slowOpsRecorded = 0
+	c.slowOpsMutex.Unlock()
+}
+
+// startOp returns the time at which an operation started, or the zero time if slow operations are not recorded
+func (c *Cache) startOp() time.Time {
+	if c.slowOpThreshold == 0 {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// endOp records an operation that started at the time returned by startOp if it took longer than the threshold set
+// through WithSlowOpThreshold
+//
+// The lock must not be held, as recording the stack trace of the operation may take a while.
+func (c *Cache) endOp(op OpMask, key string, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	duration := time.Since(start)
+	if duration <= c.slowOpThreshold {
+		return
+	}
+	slowOp := SlowOp{Time: start, Op: op, Key: key, Duration: duration}
+	if c.slowOpStacks {
+		slowOp.Stack = string(debug.Stack())
+	}
+	c.slowOpsMutex.Lock()
+	if len(c.slowOps) < SlowOpCapacity {
+		c.slowOps = append(c.slowOps, slowOp)
+	} else {
+		c.slowOps[c.slowOpsRecorded%SlowOpCapacity] = slowOp
+	}
+	c.slowOpsRecorded++
+	c.slowOpsMutex.Unlock()
+}
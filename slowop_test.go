@@ -0,0 +1,108 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type slowHooks struct {
+	NoopHooks
+}
+
+func (slowHooks) BeforeGet(key string) {
+	if strings.HasPrefix(key, "slow") {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (slowHooks) BeforeSet(key string, value interface{}, ttl time.Duration) {
+	if strings.HasPrefix(key, "slow") {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWithSlowOpThreshold(t *testing.T) {
+	cache := NewCache(WithHooks(slowHooks{}), WithSlowOpThreshold(time.Millisecond))
+	cache.Set("fast", "value")
+	cache.Get("fast")
+	cache.Set("slow-set", "value")
+	cache.Get("slow-get")
+	cache.Delete("fast")
+	slowOps := cache.SlowOps()
+	if len(slowOps) != 2 {
+		t.Fatalf("expected 2 slow operations, got %v", slowOps)
+	}
+	if slowOps[0].Op != OpGet || slowOps[0].Key != "slow-get" {
+		t.Errorf("expected the most recent slow operation to be the get of slow-get, got %v", slowOps[0])
+	}
+	if slowOps[1].Op != OpSet || slowOps[1].Key != "slow-set" {
+		t.Errorf("expected the oldest slow operation to be the set of slow-set, got %v", slowOps[1])
+	}
+	if slowOps[0].Duration < 5*time.Millisecond || slowOps[0].Time.IsZero() || slowOps[0].Stack != "" {
+		t.Errorf("expected the duration and time of the operation to have been recorded without its stack, got %v", slowOps[0])
+	}
+	cache.ResetSlowOps()
+	if slowOps := cache.SlowOps(); len(slowOps) != 0 {
+		t.Errorf("expected no slow operation after ResetSlowOps, got %v", slowOps)
+	}
+}
+
+func TestWithSlowOpThresholdWithGetManyDetailed(t *testing.T) {
+	cache := NewCache(WithHooks(slowHooks{}), WithSlowOpThreshold(time.Millisecond))
+	cache.GetManyDetailed([]string{"slow-get"})
+	if slowOps := cache.SlowOps(); len(slowOps) != 1 || slowOps[0].Op != OpGet || slowOps[0].Key != "slow-get" {
+		t.Errorf("expected the get of slow-get to have been recorded, got %v", slowOps)
+	}
+}
+
+func TestWithSlowOpThresholdWithTryGet(t *testing.T) {
+	cache := NewCache(WithHooks(slowHooks{}), WithSlowOpThreshold(time.Millisecond))
+	cache.TryGet("slow-get")
+	if slowOps := cache.SlowOps(); len(slowOps) != 1 || slowOps[0].Op != OpGet || slowOps[0].Key != "slow-get" {
+		t.Errorf("expected the get of slow-get to have been recorded, got %v", slowOps)
+	}
+}
+
+func TestWithSlowOpThresholdWithReplace(t *testing.T) {
+	cache := NewCache(WithHooks(slowHooks{}), WithSlowOpThreshold(time.Millisecond))
+	cache.Set("slow-replace", "value")
+	cache.ResetSlowOps()
+	cache.Replace("slow-replace", "replaced")
+	if slowOps := cache.SlowOps(); len(slowOps) != 1 || slowOps[0].Op != OpSet || slowOps[0].Key != "slow-replace" {
+		t.Errorf("expected the replacement of slow-replace to have been recorded, got %v", slowOps)
+	}
+}
+
+func TestWithSlowOpThresholdWhenCapacityIsReached(t *testing.T) {
+	cache := NewCache(WithSlowOpThreshold(time.Nanosecond))
+	for n := 0; n < SlowOpCapacity+10; n++ {
+		cache.Set("key", n)
+	}
+	slowOps := cache.SlowOps()
+	if len(slowOps) != SlowOpCapacity {
+		t.Fatalf("expected %d slow operations, got %d", SlowOpCapacity, len(slowOps))
+	}
+	for i := 1; i < len(slowOps); i++ {
+		if slowOps[i].Time.After(slowOps[i-1].Time) {
+			t.Fatal("expected the slow operations to be ordered from the most recent to the oldest")
+		}
+	}
+}
+
+func TestWithSlowOpStacks(t *testing.T) {
+	cache := NewCache(WithHooks(slowHooks{}), WithSlowOpThreshold(time.Millisecond), WithSlowOpStacks(true))
+	cache.Get("slow")
+	slowOps := cache.SlowOps()
+	if len(slowOps) != 1 || !strings.Contains(slowOps[0].Stack, "TestWithSlowOpStacks") {
+		t.Errorf("expected the stack of the slow operation to have been recorded, got %v", slowOps)
+	}
+}
+
+func TestCache_SlowOpsWhenDisabled(t *testing.T) {
+	cache := NewCache(WithHooks(slowHooks{}))
+	cache.Get("slow")
+	if slowOps := cache.SlowOps(); len(slowOps) != 0 {
+		t.Errorf("expected no slow operation to have been recorded, got %v", slowOps)
+	}
+}
@@ -0,0 +1,98 @@
+package gocache
+
+import (
+	"context"
+	"time"
+)
+
+// RefreshObserver can be implemented by Hooks to be notified of the entries set through SetWithSoftHardTTL that need
+// a refresh, so that they can be refreshed before they expire.
+//
+// OnNeedsRefresh is called without holding the lock, the first time an entry is retrieved through Get, GetCtx or
+// GetWithSoftTTL after its soft TTL has passed, with the key the entry is stored under and its value. It is not
+// called again for the same entry until it is set again.
+type RefreshObserver interface {
+	OnNeedsRefresh(key string, value interface{})
+}
+
+// SetWithSoftHardTTL creates or updates a key with a given value and two deadlines: once the soft TTL has passed, the
+// value is still served, but it is flagged as needing a refresh (see GetWithSoftTTL and RefreshObserver), and once
+// the hard TTL (-1 is NoExpiration) has passed, the entry expires just like if it had been set through SetWithTTL.
+//
+// If the soft TTL is not shorter than the hard TTL, the entry never needs a refresh before it expires. If it is 0 or
+// negative, the entry needs a refresh as soon as it is set. Updating the entry through any other Set-like function
+// removes the soft TTL.
+//
+// Returns ErrCacheFull if the cache is full and its FullBehavior is RejectWrites
+func (c *Cache) SetWithSoftHardTTL(key string, value interface{}, soft, hard time.Duration) error {
+	storageKey, err := c.writeKey(key)
+	if err != nil {
+		if err == errNeverCached {
+			return nil
+		}
+		return err
+	}
+	callerKey, key := key, storageKey
+	expiration := expirationOf(hard)
+	var softExpiration int64
+	if hard == NoExpiration || soft < hard {
+		softExpiration = time.Now().Add(soft).UnixNano()
+	}
+	return c.writeWithHooks(context.Background(), key, value, hard, func() error {
+		value := c.normalizeNil(value)
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		var updatedAt int64
+		if entry, ok := c.get(key); ok {
+			updatedAt = entry.updatedAt
+		}
+		err := c.setLocked(callerKey, key, value, expiration, 0, nil)
+		// The entry may not have been created (e.g. because of its hard TTL), evicted right after being set, or left
+		// untouched because the value was identical (see WithSkipIdenticalWrites)
+		if entry, ok := c.get(key); err == nil && ok && entry.updatedAt != updatedAt && softExpiration != 0 {
+			// 0 means that the entry never needs a refresh, so a soft deadline in the past is clamped to 1
+			entry.softExpiration = softExpiration
+			if entry.softExpiration < 1 {
+				entry.softExpiration = 1
+			}
+		}
+		return err
+	})
+}
+
+// GetWithSoftTTL retrieves an entry using the key passed as parameter, just like Get, and returns whether its soft
+// TTL has passed, meaning that it needs a refresh (see SetWithSoftHardTTL)
+// If there is no such entry, the value returned will be nil and both booleans will be false.
+// Entries that weren't set through SetWithSoftHardTTL never need a refresh.
+func (c *Cache) GetWithSoftTTL(key string) (value interface{}, needsRefresh bool, ok bool) {
+	key = c.storageKey(key)
+	if c.hooks != nil {
+		c.hooks.BeforeGet(key)
+	}
+	notify := false
+	c.mutex.Lock()
+	value, ok = c.lookup(key)
+	if ok {
+		needsRefresh, notify = c.needsRefresh(key)
+	}
+	c.mutex.Unlock()
+	if notify && c.refreshObserver != nil {
+		c.refreshObserver.OnNeedsRefresh(key, value)
+	}
+	c.afterGet(context.Background(), key, value, ok)
+	return value, needsRefresh, ok
+}
+
+// needsRefresh returns whether the soft TTL of an entry that exists has passed, and whether the RefreshObserver must
+// be notified, which is only the case the first time this is called after the soft TTL has passed
+//
+// The caller must hold the lock.
+func (c *Cache) needsRefresh(key string) (needsRefresh bool, notify bool) {
+	entry, ok := c.get(key)
+	if !ok || entry.softExpiration == 0 || time.Now().UnixNano() < entry.softExpiration {
+		return false, false
+	}
+	notify = !entry.refreshRequested
+	entry.refreshRequested = true
+	return true, notify
+}
@@ -0,0 +1,93 @@
+package gocache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type refreshRecordingHooks struct {
+	NoopHooks
+	mutex sync.Mutex
+	keys  []string
+}
+
+func (hooks *refreshRecordingHooks) OnNeedsRefresh(key string, value interface{}) {
+	hooks.mutex.Lock()
+	hooks.keys = append(hooks.keys, key)
+	hooks.mutex.Unlock()
+}
+
+func TestCache_SetWithSoftHardTTL(t *testing.T) {
+	cache := NewCache()
+	if err := cache.SetWithSoftHardTTL("key", "value", 20*time.Millisecond, 60*time.Millisecond); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if value, needsRefresh, ok := cache.GetWithSoftTTL("key"); !ok || needsRefresh || value != "value" {
+		t.Errorf("expected a fresh value, got %v, %v, %v", value, needsRefresh, ok)
+	}
+	if ttl, err := cache.TTL("key"); err != nil || ttl <= 20*time.Millisecond {
+		t.Errorf("expected the TTL to be the hard TTL, got %s, %v", ttl, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if value, needsRefresh, ok := cache.GetWithSoftTTL("key"); !ok || !needsRefresh || value != "value" {
+		t.Errorf("expected a value that needs a refresh, got %v, %v, %v", value, needsRefresh, ok)
+	}
+	if value, ok := cache.Get("key"); !ok || value != "value" {
+		t.Error("expected the value to still be served after its soft TTL")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, needsRefresh, ok := cache.GetWithSoftTTL("key"); ok || needsRefresh {
+		t.Error("expected the entry to have expired after its hard TTL")
+	}
+}
+
+func TestCache_SetWithSoftHardTTLWhenSetAgain(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithSoftHardTTL("key", "value", time.Nanosecond, time.Hour)
+	time.Sleep(time.Millisecond)
+	if _, needsRefresh, _ := cache.GetWithSoftTTL("key"); !needsRefresh {
+		t.Error("expected the entry to need a refresh")
+	}
+	cache.SetWithTTL("key", "refreshed", time.Hour)
+	if value, needsRefresh, ok := cache.GetWithSoftTTL("key"); !ok || needsRefresh || value != "refreshed" {
+		t.Errorf("expected setting the key again to remove the soft TTL, got %v, %v, %v", value, needsRefresh, ok)
+	}
+}
+
+func TestCache_SetWithSoftHardTTLWhenSoftIsNotShorterThanHard(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithSoftHardTTL("key", "value", time.Hour, time.Millisecond)
+	if _, needsRefresh, ok := cache.GetWithSoftTTL("key"); !ok || needsRefresh {
+		t.Error("expected the entry to never need a refresh")
+	}
+	cache.SetWithSoftHardTTL("immediate", "value", 0, NoExpiration)
+	if _, needsRefresh, ok := cache.GetWithSoftTTL("immediate"); !ok || !needsRefresh {
+		t.Error("expected the entry to need a refresh as soon as it is set")
+	}
+	cache.SetWithSoftHardTTL("expired", "value", -time.Hour, 0)
+	if _, ok := cache.Get("expired"); ok {
+		t.Error("expected an entry with a hard TTL of 0 not to be created")
+	}
+}
+
+func TestCache_SetWithSoftHardTTLNotifiesRefreshObserver(t *testing.T) {
+	hooks := &refreshRecordingHooks{}
+	cache := NewCache(WithHooks(hooks))
+	cache.SetWithSoftHardTTL("key", "value", time.Nanosecond, time.Hour)
+	cache.Set("other", "value")
+	time.Sleep(time.Millisecond)
+	cache.Get("key")
+	cache.Get("key")
+	cache.GetWithSoftTTL("key")
+	cache.Get("other")
+	if len(hooks.keys) != 1 || hooks.keys[0] != "key" {
+		t.Errorf("expected the observer to have been notified once, got %v", hooks.keys)
+	}
+	cache.SetWithSoftHardTTL("key", "refreshed", time.Nanosecond, time.Hour)
+	time.Sleep(time.Millisecond)
+	cache.GetWithSoftTTL("key")
+	if len(hooks.keys) != 2 {
+		t.Errorf("expected the observer to have been notified again after the key was set again, got %v", hooks.keys)
+	}
+}
@@ -0,0 +1,201 @@
+package gocache
+
+const (
+	// sortedSetMaxLevel is the maximum number of levels of the skip list of a sorted set, which is enough for 4^32
+	// members
+	sortedSetMaxLevel = 32
+)
+
+// ScoredMember is a member of a sorted set along with its score, as returned by ZRangeByScore
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// skipListNode is a member of a sorted set
+type skipListNode struct {
+	member string
+	score  float64
+
+	// next are the following nodes at each level the node is in
+	next []*skipListNode
+}
+
+// cost returns the approximate size of the node in bytes
+func (node *skipListNode) cost() int {
+	return toBytes(node.member) + 8 + 8*len(node.next)
+}
+
+// before returns whether the node is ordered before a member with the given score
+// Members with the same score are ordered lexicographically.
+func (node *skipListNode) before(score float64, member string) bool {
+	return node.score < score || (node.score == score && node.member < member)
+}
+
+// sortedSet is the value of the keys created through ZAdd
+//
+// The members are kept in a skip list ordered by score, so that ranges of scores can be retrieved without sorting.
+type sortedSet struct {
+	// scores are the scores of the members, by member
+	scores map[string]float64
+
+	head  *skipListNode
+	level int
+
+	// cost is the approximate size of the members, of their scores and of the skip list in bytes
+	cost int
+
+	// intn returns the pseudo-random numbers used to pick the level of the members (see Cache.intn)
+	intn func(n int) int
+}
+
+func newSortedSet(intn func(n int) int) *sortedSet {
+	return &sortedSet{
+		scores: make(map[string]float64),
+		intn:   intn,
+		head:   &skipListNode{next: make([]*skipListNode, sortedSetMaxLevel)},
+		level:  1,
+	}
+}
+
+// CacheCost returns the approximate size of the sorted set in bytes
+func (set *sortedSet) CacheCost() int {
+	return set.cost
+}
+
+// Len returns the number of members of the sorted set
+func (set *sortedSet) Len() int {
+	return len(set.scores)
+}
+
+// precedingNodes returns, for each level, the last node ordered before a member with the given score
+func (set *sortedSet) precedingNodes(score float64, member string) [sortedSetMaxLevel]*skipListNode {
+	var preceding [sortedSetMaxLevel]*skipListNode
+	node := set.head
+	for i := set.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && node.next[i].before(score, member) {
+			node = node.next[i]
+		}
+		preceding[i] = node
+	}
+	return preceding
+}
+
+// insert adds a member that isn't in the sorted set
+func (set *sortedSet) insert(member string, score float64) {
+	preceding := set.precedingNodes(score, member)
+	level := 1
+	for level < sortedSetMaxLevel && set.intn(4) == 0 {
+		level++
+	}
+	for ; set.level < level; set.level++ {
+		preceding[set.level] = set.head
+	}
+	node := &skipListNode{member: member, score: score, next: make([]*skipListNode, level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = preceding[i].next[i]
+		preceding[i].next[i] = node
+	}
+	set.scores[member] = score
+	set.cost += node.cost()
+}
+
+// remove removes a member that is in the sorted set
+func (set *sortedSet) remove(member string) {
+	preceding := set.precedingNodes(set.scores[member], member)
+	node := preceding[0].next[0]
+	for i := 0; i < len(node.next); i++ {
+		preceding[i].next[i] = node.next[i]
+	}
+	for set.level > 1 && set.head.next[set.level-1] == nil {
+		set.level--
+	}
+	delete(set.scores, member)
+	set.cost -= node.cost()
+}
+
+// ZAdd adds a member with the given score to the sorted set stored under a key, or updates its score if it is already
+// in the set, creating the set without expiration if it doesn't exist. Returns whether the member is new.
+//
+// The whole sorted set is a single entry, so it expires and is evicted as a whole. Use Expire to set its TTL.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through ZAdd
+func (c *Cache) ZAdd(key string, score float64, member string) (bool, error) {
+	created := false
+	create := func() *sortedSet {
+		return newSortedSet(c.intn)
+	}
+	err := modifyCollection(c, key, create, func(set *sortedSet) (bool, error) {
+		if previous, ok := set.scores[member]; ok {
+			if previous == score {
+				return false, nil
+			}
+			set.remove(member)
+		} else {
+			created = true
+		}
+		set.insert(member, score)
+		return true, nil
+	})
+	return created, err
+}
+
+// ZRem removes members from the sorted set stored under a key, and returns the number of members that were in the
+// set. The key is deleted once the set is empty.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through ZAdd
+func (c *Cache) ZRem(key string, members ...string) (int, error) {
+	removed := 0
+	err := modifyCollection(c, key, nil, func(set *sortedSet) (bool, error) {
+		for _, member := range members {
+			if _, ok := set.scores[member]; ok {
+				set.remove(member)
+				removed++
+			}
+		}
+		return removed > 0, nil
+	})
+	return removed, err
+}
+
+// ZScore retrieves the score of a member of the sorted set stored under a key
+// If the key or the member doesn't exist, the score returned will be 0 and the boolean will be false.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through ZAdd
+func (c *Cache) ZScore(key, member string) (float64, bool, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	set, ok, err := lookupCollection[*sortedSet](c, key)
+	if !ok {
+		return 0, false, err
+	}
+	score, ok := set.scores[member]
+	return score, ok, nil
+}
+
+// ZRangeByScore retrieves the members of the sorted set stored under a key whose score is between min and max
+// (inclusive), ordered by score, and then lexicographically for members with the same score.
+// Use math.Inf for ranges without a lower or an upper bound.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through ZAdd
+func (c *Cache) ZRangeByScore(key string, min, max float64) ([]ScoredMember, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	set, ok, err := lookupCollection[*sortedSet](c, key)
+	if !ok {
+		return nil, err
+	}
+	var members []ScoredMember
+	node := set.head
+	for i := set.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && node.next[i].score < min {
+			node = node.next[i]
+		}
+	}
+	for node = node.next[0]; node != nil && node.score <= max; node = node.next[0] {
+		members = append(members, ScoredMember{Member: node.member, Score: node.score})
+	}
+	return members, nil
+}
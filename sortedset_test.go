@@ -0,0 +1,129 @@
+package gocache
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestCache_ZAdd(t *testing.T) {
+	cache := NewCache()
+	if created, err := cache.ZAdd("leaderboard", 10, "alice"); err != nil || !created {
+		t.Errorf("expected alice to have been added, got %v (%v)", created, err)
+	}
+	cache.ZAdd("leaderboard", 30, "bob")
+	cache.ZAdd("leaderboard", 20, "carol")
+	if created, err := cache.ZAdd("leaderboard", 40, "alice"); err != nil || created {
+		t.Errorf("expected the score of alice to have been updated, got %v (%v)", created, err)
+	}
+	if score, ok, err := cache.ZScore("leaderboard", "alice"); err != nil || !ok || score != 40 {
+		t.Errorf("expected the score of alice to be 40, got %v, %v (%v)", score, ok, err)
+	}
+	if _, ok, _ := cache.ZScore("leaderboard", "dave"); ok {
+		t.Error("expected dave not to be a member")
+	}
+	members, err := cache.ZRangeByScore("leaderboard", math.Inf(-1), math.Inf(1))
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	expected := []ScoredMember{{"carol", 20}, {"bob", 30}, {"alice", 40}}
+	if fmt.Sprint(members) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, members)
+	}
+}
+
+func TestCache_ZRangeByScore(t *testing.T) {
+	cache := NewCache()
+	cache.ZAdd("key", 1, "a")
+	cache.ZAdd("key", 2, "c")
+	cache.ZAdd("key", 2, "b")
+	cache.ZAdd("key", 3, "d")
+	scenarios := []struct {
+		min, max float64
+		expected string
+	}{
+		{2, 2, "[{b 2} {c 2}]"},
+		{1.5, 3, "[{b 2} {c 2} {d 3}]"},
+		{0, 1, "[{a 1}]"},
+		{4, 5, "[]"},
+		{3, 1, "[]"},
+	}
+	for _, scenario := range scenarios {
+		t.Run(fmt.Sprintf("%v-%v", scenario.min, scenario.max), func(t *testing.T) {
+			members, _ := cache.ZRangeByScore("key", scenario.min, scenario.max)
+			if fmt.Sprint(members) != scenario.expected {
+				t.Errorf("expected %s, got %v", scenario.expected, members)
+			}
+		})
+	}
+	if members, err := cache.ZRangeByScore("missing", 0, 10); err != nil || len(members) != 0 {
+		t.Errorf("expected no members, got %v (%v)", members, err)
+	}
+}
+
+func TestCache_ZRem(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(Megabyte))
+	cache.ZAdd("key", 1, "a")
+	cache.ZAdd("key", 2, "b")
+	if removed, err := cache.ZRem("key", "a", "c"); err != nil || removed != 1 {
+		t.Errorf("expected 1 member to have been removed, got %d (%v)", removed, err)
+	}
+	if members, _ := cache.ZRangeByScore("key", 0, 10); len(members) != 1 || members[0].Member != "b" {
+		t.Error("expected only b to be left, got", members)
+	}
+	cache.ZRem("key", "b")
+	if cache.Count() != 0 || cache.MemoryUsage() != 0 {
+		t.Error("expected the key to have been deleted once the set was empty")
+	}
+}
+
+func TestCache_ZAddKeepsMembersOrdered(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(Megabyte))
+	scores := make(map[string]float64)
+	for i := 0; i < 1000; i++ {
+		member := fmt.Sprintf("member-%d", rand.Intn(500))
+		score := float64(rand.Intn(100))
+		if rand.Intn(4) == 0 {
+			cache.ZRem("key", member)
+			delete(scores, member)
+			continue
+		}
+		cache.ZAdd("key", score, member)
+		scores[member] = score
+	}
+	members, _ := cache.ZRangeByScore("key", math.Inf(-1), math.Inf(1))
+	if len(members) != len(scores) {
+		t.Fatalf("expected %d members, got %d", len(scores), len(members))
+	}
+	if !sort.SliceIsSorted(members, func(i, j int) bool {
+		return members[i].Score < members[j].Score || (members[i].Score == members[j].Score && members[i].Member < members[j].Member)
+	}) {
+		t.Error("expected the members to be ordered by score")
+	}
+	for _, member := range members {
+		if scores[member.Member] != member.Score {
+			t.Errorf("expected the score of %s to be %v, got %v", member.Member, scores[member.Member], member.Score)
+		}
+	}
+	set := cache.entries["key"].Value.(*sortedSet)
+	cost := 0
+	for node := set.head.next[0]; node != nil; node = node.next[0] {
+		cost += node.cost()
+	}
+	if set.cost != cost {
+		t.Errorf("expected the cost of the set to be %d, got %d", cost, set.cost)
+	}
+}
+
+func TestCache_ZAddWithWrongType(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	if _, err := cache.ZAdd("key", 1, "a"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.ZRangeByScore("key", 0, 1); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package sqlcache wraps a database/sql database with cache-aside semantics: the results of read queries are stored in
+// a gocache.Cache, keyed on the normalized query and its arguments, and write statements invalidate the cached results
+// of the tables they modify.
+//
+// Rather than tracking which cached results read from which table, every table has a generation that is part of the
+// key of the results that read from it. Invalidating a table increments its generation, which makes the results cached
+// under the previous generation unreachable until they are evicted or expire.
+//
+// Usage:
+//
+//	db := sqlcache.New(sqlDB, gocache.NewCache(), sqlcache.WithDefaultTTL(time.Minute))
+//	rows, err := db.Query(ctx, "SELECT id, name FROM users WHERE id = ?", 1)
+//	// ...
+//	_, err = db.Exec(ctx, "UPDATE users SET name = ? WHERE id = ?", "john", 1) // invalidates every cached query on users
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	gocache "github.com/arham09/cache"
+)
+
+const (
+	// KeyPrefix is the prefix of the keys used to store query results in the cache
+	KeyPrefix = "sqlcache:"
+)
+
+var (
+	// readKeywordRegex matches the keywords followed by the tables that a read query reads from
+	readKeywordRegex = regexp.MustCompile(`(?i)\b(?:from|join)\s+`)
+
+	// tableRegex matches a table name, possibly quoted and qualified by its schema
+	tableRegex = regexp.MustCompile(`^[\w."` + "`" + `]+`)
+
+	// aliasRegex matches the alias following a table name, which may be a keyword instead (see sqlKeywords)
+	aliasRegex = regexp.MustCompile(`(?i)^\s+(?:as\s+)?(\w+)`)
+
+	// listSeparatorRegex matches the comma separating the tables of a list, e.g. FROM a, b
+	listSeparatorRegex = regexp.MustCompile(`^\s*,\s*`)
+
+	// writeTableRegex matches the table that a write statement modifies
+	writeTableRegex = regexp.MustCompile(`(?i)^\s*(?:insert\s+(?:or\s+\w+\s+)?into|update|delete\s+from|replace\s+into|truncate(?:\s+table)?|drop\s+table(?:\s+if\s+exists)?|alter\s+table)\s+([\w."` + "`" + `]+)`)
+
+	// sqlKeywords are the keywords that may follow a table name in a read query, and which therefore aren't aliases
+	sqlKeywords = map[string]bool{
+		"where": true, "join": true, "inner": true, "left": true, "right": true, "full": true, "outer": true,
+		"cross": true, "natural": true, "on": true, "using": true, "group": true, "order": true, "having": true,
+		"limit": true, "offset": true, "union": true, "except": true, "intersect": true, "window": true, "for": true,
+		"fetch": true, "straight_join": true,
+	}
+)
+
+// Rows are the materialized result of a query
+//
+// Since the same Rows are returned to every caller until they are invalidated, they must not be modified.
+type Rows struct {
+	// Columns are the names of the columns
+	Columns []string
+
+	// Values are the rows, each containing one value per column
+	Values [][]interface{}
+}
+
+// DB is a database whose read queries are cached
+type DB struct {
+	db    *sql.DB
+	cache *gocache.Cache
+
+	defaultTTL    time.Duration
+	statementTTLs map[string]time.Duration
+	writeHook     func(query string, args []interface{}) []string
+
+	// mutex guards generation, writes and tableGenerations
+	mutex sync.Mutex
+
+	// generation is incremented whenever every cached result is invalidated
+	generation uint64
+
+	// writes is incremented whenever a table is invalidated, so that the results of the queries whose tables could not
+	// be determined are invalidated by every write
+	writes uint64
+
+	// tableGenerations contains the generation of each table, which is incremented whenever the table is invalidated
+	tableGenerations map[string]uint64
+}
+
+// Option is an option for New
+type Option func(db *DB)
+
+// WithDefaultTTL sets the TTL of cached query results, unless a TTL is set for the statement with WithStatementTTL.
+// Defaults to gocache.NoExpiration, meaning that results are only removed when invalidated or evicted
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(db *DB) {
+		db.defaultTTL = ttl
+	}
+}
+
+// WithStatementTTL sets the TTL of the results of a specific query
+// The query is normalized the same way as the queries passed to Query, so whitespace differences do not matter
+func WithStatementTTL(query string, ttl time.Duration) Option {
+	return func(db *DB) {
+		db.statementTTLs[normalize(query)] = ttl
+	}
+}
+
+// WithWriteHook sets a function called after every successful write statement, which returns the tables whose cached
+// results must be invalidated, in addition to the table detected from the statement itself.
+//
+// This is useful when a write has side effects that cannot be inferred from the statement, such as triggers or views.
+func WithWriteHook(hook func(query string, args []interface{}) []string) Option {
+	return func(db *DB) {
+		db.writeHook = hook
+	}
+}
+
+// New creates a DB that caches the results of the read queries made on the given database in the given cache
+func New(db *sql.DB, cache *gocache.Cache, opts ...Option) *DB {
+	cachedDB := &DB{
+		db:               db,
+		cache:            cache,
+		defaultTTL:       gocache.NoExpiration,
+		statementTTLs:    make(map[string]time.Duration),
+		tableGenerations: make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(cachedDB)
+	}
+	return cachedDB
+}
+
+// Query returns the rows of a read query, from the cache if possible, and from the database otherwise
+func (db *DB) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	normalizedQuery := normalize(query)
+	key := db.key(normalizedQuery, args)
+	if value, ok := db.cache.Get(key); ok {
+		return value.(*Rows), nil
+	}
+	sqlRows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer sqlRows.Close()
+	rows := &Rows{}
+	if rows.Columns, err = sqlRows.Columns(); err != nil {
+		return nil, err
+	}
+	for sqlRows.Next() {
+		values := make([]interface{}, len(rows.Columns))
+		pointers := make([]interface{}, len(values))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err = sqlRows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		rows.Values = append(rows.Values, values)
+	}
+	if err = sqlRows.Err(); err != nil {
+		return nil, err
+	}
+	ttl, ok := db.statementTTLs[normalizedQuery]
+	if !ok {
+		ttl = db.defaultTTL
+	}
+	// If a table was invalidated while the query was running, the rows may be stale and must be stored under a key
+	// that is already unreachable, which is why the key is not recomputed here
+	_ = db.cache.SetWithTTL(key, rows, ttl)
+	return rows, nil
+}
+
+// Exec executes a write statement and invalidates the cached results of the queries reading from the table it
+// modifies, as well as of the tables returned by the write hook, if any.
+//
+// If no table could be determined, neither from the statement nor from the write hook, every cached result is
+// invalidated.
+func (db *DB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := db.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	tables := tablesMatching(writeTableRegex, normalize(query))
+	if db.writeHook != nil {
+		tables = append(tables, db.writeHook(query, args)...)
+	}
+	if len(tables) == 0 {
+		db.InvalidateAll()
+	} else {
+		db.Invalidate(tables...)
+	}
+	return result, nil
+}
+
+// Invalidate removes the cached results of every query reading from the given tables
+func (db *DB) Invalidate(tables ...string) {
+	db.mutex.Lock()
+	for _, table := range tables {
+		db.tableGenerations[normalizeTable(table)]++
+	}
+	db.writes++
+	db.mutex.Unlock()
+}
+
+// InvalidateAll removes every cached query result
+func (db *DB) InvalidateAll() {
+	db.mutex.Lock()
+	db.generation++
+	db.mutex.Unlock()
+}
+
+// key returns the key under which the results of the given query are cached, which includes the current generation
+// of every table the query reads from, or the number of writes if they could not be determined
+func (db *DB) key(normalizedQuery string, args []interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(KeyPrefix)
+	tables := readTables(normalizedQuery)
+	db.mutex.Lock()
+	fmt.Fprintf(&sb, "%d", db.generation)
+	for _, table := range tables {
+		fmt.Fprintf(&sb, ",%s@%d", table, db.tableGenerations[table])
+	}
+	if len(tables) == 0 {
+		fmt.Fprintf(&sb, ",*@%d", db.writes)
+	}
+	db.mutex.Unlock()
+	fmt.Fprintf(&sb, "|%s|%#v", normalizedQuery, dereference(args))
+	return sb.String()
+}
+
+// dereference returns the arguments of a query with the pointers replaced by the values they point to, so that the
+// key depends on those values rather than on the addresses of the pointers. Nil pointers are left as they are.
+func dereference(args []interface{}) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		value := reflect.ValueOf(arg)
+		for value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Ptr || !value.IsValid() {
+			values[i] = arg
+		} else {
+			values[i] = value.Interface()
+		}
+	}
+	return values
+}
+
+// normalize collapses the whitespace of a query outside of its quoted literals and identifiers, so that queries that
+// only differ by their formatting share the same cached results, while queries that differ by the whitespace of their
+// literals don't
+func normalize(query string) string {
+	var sb strings.Builder
+	var quote rune
+	space, escaped := false, false
+	for _, r := range query {
+		if quote == 0 && unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+		switch {
+		case escaped:
+			escaped = false
+		case quote == 0 && (r == '\'' || r == '"' || r == '`'):
+			quote = r
+		case quote != 0 && r == '\\':
+			// Backslashes only escape quotes in some dialects, but treating them as such at worst leaves whitespace
+			// uncollapsed, which never makes different queries share their results
+			escaped = true
+		case r == quote:
+			// A doubled quote escapes the quote, which amounts to closing and reopening the literal
+			quote = 0
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// normalizeTable lowercases a table name and removes the quotes around it, as well as the schema qualifying it, so
+// that writes through public.users invalidate the reads from users and vice versa. This means that the tables of the
+// same name in different schemas invalidate each other, which is harmless.
+func normalizeTable(table string) string {
+	table = strings.ToLower(strings.NewReplacer(`"`, "", "`", "").Replace(table))
+	return table[strings.LastIndexByte(table, '.')+1:]
+}
+
+// readTables returns the normalized names of the tables that the given read query reads from, including every table
+// of the comma-separated lists, e.g. both a and b for FROM a x, b y
+//
+// Keywords followed by something else than a table name, such as a subquery, are skipped, the tables of the subquery
+// being found through its own keywords.
+func readTables(query string) []string {
+	var tables []string
+	for _, location := range readKeywordRegex.FindAllStringIndex(query, -1) {
+		rest := query[location[1]:]
+		for {
+			table := tableRegex.FindString(rest)
+			if table == "" {
+				break
+			}
+			tables = append(tables, normalizeTable(table))
+			rest = rest[len(table):]
+			if alias := aliasRegex.FindStringSubmatch(rest); alias != nil && !sqlKeywords[strings.ToLower(alias[1])] {
+				rest = rest[len(alias[0]):]
+			}
+			separator := listSeparatorRegex.FindString(rest)
+			if separator == "" {
+				break
+			}
+			rest = rest[len(separator):]
+		}
+	}
+	return tables
+}
+
+// tablesMatching returns the normalized table names captured by the given regex in the given query
+func tablesMatching(regex *regexp.Regexp, query string) []string {
+	var tables []string
+	for _, match := range regex.FindAllStringSubmatch(query, -1) {
+		tables = append(tables, normalizeTable(match[1]))
+	}
+	return tables
+}
@@ -0,0 +1,257 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+// fakeDriver is a database/sql driver which returns a single row containing the number of queries executed so far,
+// which makes it easy to tell whether a result came from the cache or from the database
+type fakeDriver struct {
+	mutex   sync.Mutex
+	queries int
+	execs   []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.conn.driver.mutex.Lock()
+	defer s.conn.driver.mutex.Unlock()
+	s.conn.driver.execs = append(s.conn.driver.execs, s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.conn.driver.mutex.Lock()
+	defer s.conn.driver.mutex.Unlock()
+	s.conn.driver.queries++
+	return &fakeRows{value: int64(s.conn.driver.queries)}, nil
+}
+
+type fakeRows struct {
+	value int64
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"queries"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.value
+	r.done = true
+	return nil
+}
+
+func newTestDB(t *testing.T, opts ...Option) (*DB, *fakeDriver) {
+	fake := &fakeDriver{}
+	name := "sqlcache-" + t.Name()
+	sql.Register(name, fake)
+	sqlDB, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+	return New(sqlDB, gocache.NewCache(), opts...), fake
+}
+
+func query(t *testing.T, db *DB, query string, args ...interface{}) int64 {
+	rows, err := db.Query(context.Background(), query, args...)
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if len(rows.Columns) != 1 || rows.Columns[0] != "queries" || len(rows.Values) != 1 {
+		t.Fatal("unexpected rows", rows)
+	}
+	return rows.Values[0][0].(int64)
+}
+
+func TestDB_Query(t *testing.T) {
+	db, _ := newTestDB(t)
+	if query(t, db, "SELECT * FROM users WHERE id = ?", 1) != 1 {
+		t.Error("expected the first query to hit the database")
+	}
+	if query(t, db, "SELECT *\n  FROM users\tWHERE id = ?", 1) != 1 {
+		t.Error("expected the same query with different whitespace to be served from the cache")
+	}
+	if query(t, db, "SELECT * FROM users WHERE id = ?", 2) != 2 {
+		t.Error("expected the same query with different arguments to hit the database")
+	}
+}
+
+func TestDB_QueryWithWhitespaceInLiterals(t *testing.T) {
+	db, _ := newTestDB(t)
+	query(t, db, "SELECT * FROM users WHERE name = 'a  b'")
+	if query(t, db, "SELECT * FROM users WHERE name = 'a b'") != 2 {
+		t.Error("expected the whitespace of the literal to be part of the key")
+	}
+	if query(t, db, "SELECT *  FROM users\nWHERE name = 'a  b'") != 1 {
+		t.Error("expected the whitespace outside of the literal to be collapsed")
+	}
+	query(t, db, `SELECT * FROM users WHERE name = 'it\'s  a' OR name = 'it''s  b'`)
+	if query(t, db, `SELECT * FROM users WHERE name = 'it\'s a' OR name = 'it''s b'`) != 4 {
+		t.Error("expected the whitespace of the literals with escaped quotes to be part of the key")
+	}
+}
+
+func TestDB_QueryWithPointerArguments(t *testing.T) {
+	db, _ := newTestDB(t)
+	first, second, other := 1, 1, 2
+	query(t, db, "SELECT * FROM users WHERE id = ?", &first)
+	if query(t, db, "SELECT * FROM users WHERE id = ?", &second) != 1 {
+		t.Error("expected pointers to the same value to share the cached results")
+	}
+	if query(t, db, "SELECT * FROM users WHERE id = ?", &other) != 2 {
+		t.Error("expected pointers to different values not to share the cached results")
+	}
+	var nilPointer *int
+	if query(t, db, "SELECT * FROM users WHERE id = ?", nilPointer) != 3 {
+		t.Error("expected a nil pointer to hit the database")
+	}
+}
+
+func TestDB_Exec(t *testing.T) {
+	db, fake := newTestDB(t)
+	query(t, db, "SELECT * FROM users WHERE id = ?", 1)
+	query(t, db, "SELECT * FROM orders o JOIN products p ON o.product_id = p.id")
+	if _, err := db.Exec(context.Background(), "UPDATE users SET name = ? WHERE id = ?", "john", 1); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if len(fake.execs) != 1 {
+		t.Error("expected the statement to have been executed")
+	}
+	if query(t, db, "SELECT * FROM users WHERE id = ?", 1) != 3 {
+		t.Error("expected the query on users to have been invalidated")
+	}
+	if query(t, db, "SELECT * FROM orders o JOIN products p ON o.product_id = p.id") != 2 {
+		t.Error("expected the query on orders and products to still be cached")
+	}
+	db.Exec(context.Background(), `DELETE FROM "Products" WHERE id = 1`)
+	if query(t, db, "SELECT * FROM orders o JOIN products p ON o.product_id = p.id") != 4 {
+		t.Error("expected the query joining products to have been invalidated")
+	}
+	db.Exec(context.Background(), "CALL do_something()")
+	if query(t, db, "SELECT * FROM users WHERE id = ?", 1) != 5 {
+		t.Error("expected every query to have been invalidated, since the table modified could not be determined")
+	}
+}
+
+func TestDB_ExecWithTableLists(t *testing.T) {
+	db, _ := newTestDB(t)
+	query(t, db, "SELECT * FROM orders o, products AS p WHERE o.product_id = p.id")
+	query(t, db, "SELECT * FROM users u, sessions s JOIN accounts a ON a.user_id = u.id WHERE s.user_id = u.id")
+	db.Exec(context.Background(), "UPDATE products SET price = 1")
+	if query(t, db, "SELECT * FROM orders o, products AS p WHERE o.product_id = p.id") != 3 {
+		t.Error("expected the query reading from the second table of the list to have been invalidated")
+	}
+	if query(t, db, "SELECT * FROM users u, sessions s JOIN accounts a ON a.user_id = u.id WHERE s.user_id = u.id") != 2 {
+		t.Error("expected the query not reading from products to still be cached")
+	}
+	db.Exec(context.Background(), "DELETE FROM sessions")
+	if query(t, db, "SELECT * FROM users u, sessions s JOIN accounts a ON a.user_id = u.id WHERE s.user_id = u.id") != 4 {
+		t.Error("expected the query reading from the second table of the list before the join to have been invalidated")
+	}
+}
+
+func TestDB_ExecWithQualifiedTables(t *testing.T) {
+	db, _ := newTestDB(t)
+	query(t, db, "SELECT * FROM users")
+	query(t, db, `SELECT * FROM "public"."orders"`)
+	db.Exec(context.Background(), "UPDATE public.users SET name = 'john'")
+	db.Exec(context.Background(), "DELETE FROM orders")
+	if query(t, db, "SELECT * FROM users") != 3 {
+		t.Error("expected the write through the qualified name to have invalidated the unqualified one")
+	}
+	if query(t, db, `SELECT * FROM "public"."orders"`) != 4 {
+		t.Error("expected the write through the unqualified name to have invalidated the qualified one")
+	}
+}
+
+func TestDB_ExecInvalidatesQueriesWithUnknownTables(t *testing.T) {
+	db, _ := newTestDB(t)
+	query(t, db, "SELECT 1")
+	query(t, db, "SELECT * FROM users")
+	db.Exec(context.Background(), "UPDATE orders SET total = 0")
+	if query(t, db, "SELECT 1") != 3 {
+		t.Error("expected the query whose tables are unknown to have been invalidated")
+	}
+	if query(t, db, "SELECT * FROM users") != 2 {
+		t.Error("expected the query on users to still be cached")
+	}
+}
+
+func TestDB_ExecWithWriteHook(t *testing.T) {
+	db, _ := newTestDB(t, WithWriteHook(func(query string, args []interface{}) []string {
+		return []string{"user_stats"}
+	}))
+	query(t, db, "SELECT * FROM user_stats")
+	query(t, db, "SELECT * FROM orders")
+	db.Exec(context.Background(), "INSERT INTO users (name) VALUES (?)", "john")
+	if query(t, db, "SELECT * FROM user_stats") != 3 {
+		t.Error("expected the query on user_stats to have been invalidated by the write hook")
+	}
+	if query(t, db, "SELECT * FROM orders") != 2 {
+		t.Error("expected the query on orders to still be cached")
+	}
+}
+
+func TestDB_QueryWithStatementTTL(t *testing.T) {
+	db, _ := newTestDB(t, WithDefaultTTL(time.Hour), WithStatementTTL("SELECT * FROM sessions", time.Millisecond))
+	query(t, db, "SELECT * FROM sessions")
+	query(t, db, "SELECT * FROM users")
+	time.Sleep(2 * time.Millisecond)
+	if query(t, db, "SELECT   * FROM sessions") != 3 {
+		t.Error("expected the query on sessions to have expired")
+	}
+	if query(t, db, "SELECT * FROM users") != 2 {
+		t.Error("expected the query on users to still be cached")
+	}
+}
@@ -1,5 +1,7 @@
 package gocache
 
+import "time"
+
 type Statistics struct {
 	// EvictedKeys is the number of keys that were evicted
 	EvictedKeys uint64
@@ -12,4 +14,86 @@ type Statistics struct {
 
 	// Misses is the number of cache misses
 	Misses uint64
+
+	// StaleServes is the number of times an expired value was returned by GetOrRefresh because the refresh failed
+	// See WithServeStaleMax
+	StaleServes uint64
+
+	// CorruptedEntries is the number of persisted entries whose checksum did not match their value when they were
+	// loaded. See WithChecksums
+	CorruptedEntries uint64
+
+	// NotAdmitted is the number of new entries that were not cached because the admission filter refused them
+	// See WithAdmissionFilter
+	NotAdmitted uint64
+
+	// LongKeys is the number of writes that were rejected because their key was longer than the MaxKeyLength
+	// See WithMaxKeyLength
+	LongKeys uint64
+
+	// IdenticalWrites is the number of writes that were skipped because the value was identical to the one cached
+	// See WithSkipIdenticalWrites
+	IdenticalWrites uint64
+
+	// NetworkErrors is the number of calls to the functions talking to Redis, such as ImportFromRedis, that failed
+	// because the connection could not be established or was lost
+	NetworkErrors uint64
+
+	// FirstSeen is the number of calls to Deduplicate for a key that wasn't seen within the window
+	FirstSeen uint64
+
+	// Duplicates is the number of calls to Deduplicate for a key that was already seen within the window
+	Duplicates uint64
+
+	// Loads is the number of values loaded after a miss, either by GetOrRefresh or by a loader reporting to the cache
+	// through LoadStarted
+	Loads uint64
+
+	// CoalescedLoads is the number of callers that waited for a load already in flight for the same key rather than
+	// starting their own, as reported by loaders through LoadCoalesced
+	CoalescedLoads uint64
+
+	// LoadsInFlight is the number of loads that have started but not completed yet
+	LoadsInFlight uint64
+
+	// LoadLatencyP50 is the median duration of the last LoadLatencySamples loads
+	LoadLatencyP50 time.Duration
+
+	// LoadLatencyP90 is the 90th percentile of the duration of the last LoadLatencySamples loads
+	LoadLatencyP90 time.Duration
+
+	// LoadLatencyP99 is the 99th percentile of the duration of the last LoadLatencySamples loads
+	LoadLatencyP99 time.Duration
+}
+
+// countHits adds hits to the statistics, unless they are not sampled (see WithStatsSampling)
+//
+// The caller must hold the lock.
+func (c *Cache) countHits(hits uint64) {
+	if c.sampled() {
+		c.stats.Hits += hits
+	}
+}
+
+// countMiss adds a miss to the statistics, unless it is not sampled (see WithStatsSampling)
+//
+// The caller must hold the lock.
+func (c *Cache) countMiss() {
+	if c.sampled() {
+		c.stats.Misses++
+	}
+}
+
+// sampled returns whether the hit or miss being recorded should be counted in the statistics
+//
+// The caller must hold the lock.
+func (c *Cache) sampled() bool {
+	if c.statsSamplingRate <= 0 || c.statsSamplingRate >= 1 {
+		return true
+	}
+	// xorshift64 is used rather than math/rand, whose global source is protected by a lock of its own
+	c.statsSampler ^= c.statsSampler << 13
+	c.statsSampler ^= c.statsSampler >> 7
+	c.statsSampler ^= c.statsSampler << 17
+	return float64(c.statsSampler>>11)/(1<<53) < c.statsSamplingRate
 }
@@ -0,0 +1,99 @@
+package gocache
+
+// stringSet is the value of the keys created through SAdd
+type stringSet struct {
+	members map[string]struct{}
+
+	// cost is the approximate size of the members in bytes
+	cost int
+}
+
+func newStringSet() *stringSet {
+	return &stringSet{members: make(map[string]struct{})}
+}
+
+// CacheCost returns the approximate size of the set in bytes
+func (set *stringSet) CacheCost() int {
+	return set.cost
+}
+
+// Len returns the number of members of the set
+func (set *stringSet) Len() int {
+	return len(set.members)
+}
+
+// SAdd adds the members passed as parameter to the set stored under a key, creating it without expiration if it
+// doesn't exist, and returns the number of members that weren't already in the set.
+//
+// The whole set is a single entry, so it expires and is evicted as a whole. Use Expire to set its TTL.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through SAdd
+func (c *Cache) SAdd(key string, members ...string) (int, error) {
+	added := 0
+	err := modifyCollection(c, key, newStringSet, func(set *stringSet) (bool, error) {
+		for _, member := range members {
+			if _, ok := set.members[member]; ok {
+				continue
+			}
+			set.members[member] = struct{}{}
+			set.cost += toBytes(member)
+			added++
+		}
+		return added > 0, nil
+	})
+	return added, err
+}
+
+// SRem removes the members passed as parameter from the set stored under a key, and returns the number of members
+// that were in the set. The key is deleted once the set is empty.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through SAdd
+func (c *Cache) SRem(key string, members ...string) (int, error) {
+	removed := 0
+	err := modifyCollection(c, key, nil, func(set *stringSet) (bool, error) {
+		for _, member := range members {
+			if _, ok := set.members[member]; !ok {
+				continue
+			}
+			delete(set.members, member)
+			set.cost -= toBytes(member)
+			removed++
+		}
+		return removed > 0, nil
+	})
+	return removed, err
+}
+
+// SMembers retrieves the members of the set stored under a key, in no particular order
+// If the key doesn't exist or has expired, the slice returned will be empty.
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through SAdd
+func (c *Cache) SMembers(key string) ([]string, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	set, ok, err := lookupCollection[*stringSet](c, key)
+	if !ok {
+		return nil, err
+	}
+	members := make([]string, 0, len(set.members))
+	for member := range set.members {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// SIsMember returns whether a member is in the set stored under a key
+//
+// Returns ErrWrongType if the key holds a value that wasn't created through SAdd
+func (c *Cache) SIsMember(key, member string) (bool, error) {
+	key = c.storageKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	set, ok, err := lookupCollection[*stringSet](c, key)
+	if !ok {
+		return false, err
+	}
+	_, ok = set.members[member]
+	return ok, nil
+}
@@ -0,0 +1,107 @@
+package gocache
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCache_SAdd(t *testing.T) {
+	cache := NewCache()
+	if added, err := cache.SAdd("key", "a", "b", "a"); err != nil || added != 2 {
+		t.Errorf("expected 2 members to have been added, got %d (%v)", added, err)
+	}
+	if added, err := cache.SAdd("key", "b", "c"); err != nil || added != 1 {
+		t.Errorf("expected 1 member to have been added, got %d (%v)", added, err)
+	}
+	members, err := cache.SMembers("key")
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	sort.Strings(members)
+	if len(members) != 3 || members[0] != "a" || members[1] != "b" || members[2] != "c" {
+		t.Error("expected members to be [a b c], got", members)
+	}
+	if ok, _ := cache.SIsMember("key", "c"); !ok {
+		t.Error("expected c to be a member")
+	}
+	if ok, _ := cache.SIsMember("key", "d"); ok {
+		t.Error("expected d not to be a member")
+	}
+	if cache.Count() != 1 {
+		t.Error("expected the set to be a single entry, got", cache.Count())
+	}
+}
+
+func TestCache_SAddWithNoMembers(t *testing.T) {
+	cache := NewCache()
+	if added, err := cache.SAdd("key"); err != nil || added != 0 {
+		t.Errorf("expected no member to have been added, got %d (%v)", added, err)
+	}
+	if cache.Count() != 0 {
+		t.Error("expected an empty set not to have been created")
+	}
+}
+
+func TestCache_SAddKeepsExpiration(t *testing.T) {
+	cache := NewCache()
+	cache.SAdd("key", "a")
+	if _, err := cache.TTL("key"); err != ErrKeyHasNoExpiration {
+		t.Error("expected the set to have been created without expiration, got", err)
+	}
+	cache.Expire("key", 50*time.Millisecond)
+	cache.SAdd("key", "b")
+	if _, err := cache.TTL("key"); err != nil {
+		t.Error("expected adding members to keep the TTL of the set, got", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if members, _ := cache.SMembers("key"); len(members) != 0 {
+		t.Error("expected the whole set to have expired, got", members)
+	}
+	if added, _ := cache.SAdd("key", "c"); added != 1 {
+		t.Error("expected the set to have been recreated")
+	}
+	if members, _ := cache.SMembers("key"); len(members) != 1 || members[0] != "c" {
+		t.Error("expected the members of the expired set to have been discarded, got", members)
+	}
+}
+
+func TestCache_SRem(t *testing.T) {
+	cache := NewCache()
+	cache.SAdd("key", "a", "b", "c")
+	if removed, err := cache.SRem("key", "a", "d"); err != nil || removed != 1 {
+		t.Errorf("expected 1 member to have been removed, got %d (%v)", removed, err)
+	}
+	if ok, _ := cache.SIsMember("key", "a"); ok {
+		t.Error("expected a to have been removed")
+	}
+	if removed, _ := cache.SRem("key", "b", "c"); removed != 2 {
+		t.Error("expected 2 members to have been removed, got", removed)
+	}
+	if cache.Count() != 0 {
+		t.Error("expected the key to have been deleted once the set was empty")
+	}
+	if removed, err := cache.SRem("key", "a"); err != nil || removed != 0 {
+		t.Errorf("expected nothing to have been removed from a set that doesn't exist, got %d (%v)", removed, err)
+	}
+}
+
+func TestCache_SAddWithWrongType(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	if _, err := cache.SAdd("key", "a"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.SRem("key", "a"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.SMembers("key"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if _, err := cache.SIsMember("key", "a"); err != ErrWrongType {
+		t.Error("expected ErrWrongType, got", err)
+	}
+	if value, _ := cache.Get("key"); value != "value" {
+		t.Error("expected the value to have been left untouched, got", value)
+	}
+}
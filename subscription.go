@@ -0,0 +1,41 @@
+package gocache
+
+// setSubscription is a callback registered through OnSetPattern
+type setSubscription struct {
+	pattern  string
+	callback func(key string, value interface{})
+}
+
+// OnSetPattern registers a callback that is invoked whenever a key matching the pattern passed as parameter is
+// created or updated, which can be used to maintain indexes derived from the content of the cache.
+//
+// The callback is invoked asynchronously by a pool of at most HookWorkerPoolSize goroutines, so unlike OnEvict and
+// OnExpire, it may call methods of the cache, and it doesn't slow down writes. However, this also means that the key
+// may have been updated, deleted or evicted by the time the callback is invoked, and that callbacks for different
+// writes may be invoked in any order.
+//
+// Writes that do not create nor update an entry, such as the ones rejected because the cache is full or the ones with
+// a TTL of 0, do not invoke the callback. There is no way to unregister a callback.
+//
+// Like the patterns of WithNeverCachePatterns, the pattern is matched against the key given by the caller even if
+// WithKeyObfuscation is used, while the key passed to the callback is the key the entry is stored under.
+func (c *Cache) OnSetPattern(pattern string, callback func(key string, value interface{})) {
+	c.mutex.Lock()
+	c.setSubscriptions = append(c.setSubscriptions, setSubscription{pattern: pattern, callback: callback})
+	c.mutex.Unlock()
+}
+
+// notifySetSubscribers submits the callbacks registered for the patterns matching the key given by the caller to the
+// hook worker pool, passing them the key the entry is stored under
+//
+// The caller must hold the lock.
+func (c *Cache) notifySetSubscribers(callerKey, key string, value interface{}) {
+	for _, subscription := range c.setSubscriptions {
+		if MatchPattern(subscription.pattern, callerKey) {
+			callback := subscription.callback
+			c.hookWorkers.submit(c.name, func() {
+				callback(key, value)
+			})
+		}
+	}
+}
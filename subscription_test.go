@@ -0,0 +1,100 @@
+package gocache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_OnSetPattern(t *testing.T) {
+	cache := NewCache()
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	received := make(map[string]interface{})
+	cache.OnSetPattern("user:*", func(key string, value interface{}) {
+		mutex.Lock()
+		received[key] = value
+		mutex.Unlock()
+		wg.Done()
+	})
+	wg.Add(3)
+	cache.Set("user:1", "john")
+	cache.Set("user:2", "jane")
+	cache.Set("user:1", "johnny")
+	cache.Set("product:1", "book")
+	cache.SetWithTTL("user:3", "jim", 0)
+	wg.Wait()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(received) != 2 {
+		t.Errorf("expected callbacks for 2 keys, got %d", len(received))
+	}
+	if received["user:2"] != "jane" {
+		t.Errorf("expected the callback to receive jane for user:2, got %v", received["user:2"])
+	}
+	if _, ok := received["product:1"]; ok {
+		t.Error("expected no callback for a key not matching the pattern")
+	}
+}
+
+func TestCache_OnSetPatternCallbackCanUseCache(t *testing.T) {
+	cache := NewCache()
+	done := make(chan struct{})
+	cache.OnSetPattern("user:*", func(key string, value interface{}) {
+		// Maintain a derived index, which would deadlock if the callback was invoked while holding the lock
+		cache.Set("index:"+value.(string), key)
+		close(done)
+	})
+	cache.Set("user:1", "john")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the callback to have been invoked")
+	}
+	if value, _ := cache.Get("index:john"); value != "user:1" {
+		t.Errorf("expected index:john to be user:1, got %v", value)
+	}
+}
+
+func TestCache_OnSetPatternNotInvokedWhenRejected(t *testing.T) {
+	cache := NewCache(WithMaxSize(1), WithFullBehavior(RejectWrites))
+	invoked := make(chan string, 2)
+	cache.OnSetPattern("*", func(key string, value interface{}) {
+		invoked <- key
+	})
+	cache.Set("1", "value")
+	if err := cache.Set("2", "value"); err != ErrCacheFull {
+		t.Fatalf("expected %v, got %v", ErrCacheFull, err)
+	}
+	if key := <-invoked; key != "1" {
+		t.Errorf("expected the callback to be invoked for 1, got %s", key)
+	}
+	select {
+	case key := <-invoked:
+		t.Errorf("expected no callback for the rejected write, got one for %s", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCache_OnSetPatternWithKeyObfuscation(t *testing.T) {
+	cache := NewCache(WithKeyObfuscation([]byte("secret")))
+	invoked := make(chan string, 2)
+	cache.OnSetPattern("user:*", func(key string, value interface{}) {
+		invoked <- key
+	})
+	cache.Set("product:1", "book")
+	cache.Set("user:1", "john")
+	select {
+	case key := <-invoked:
+		if key != cache.storageKey("user:1") {
+			t.Errorf("expected the callback to receive the digest of user:1, got %s", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the pattern to have been matched against the key given by the caller")
+	}
+	select {
+	case key := <-invoked:
+		t.Errorf("expected no callback for a key not matching the pattern, got one for %s", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,71 @@
+package gocache
+
+import "context"
+
+// GetKeysByTag retrieves the keys of the entries whose value is a Tagger returning the tag passed as parameter
+//
+// Like GetKeysByPattern, this does not trigger active evictions, nor does it count as accessing the entries.
+func (c *Cache) GetKeysByTag(tag string) []string {
+	var keys []string
+	c.mutex.Lock()
+	for key := range c.tags[tag] {
+		if entry := c.entries[key]; !entry.Expired() {
+			keys = append(keys, key)
+		}
+	}
+	c.mutex.Unlock()
+	return keys
+}
+
+// DeleteByTag deletes the entries whose value is a Tagger returning the tag passed as parameter
+//
+// Returns the number of entries deleted
+func (c *Cache) DeleteByTag(tag string) int {
+	numberOfKeysDeleted := 0
+	c.mutex.Lock()
+	for key := range c.tags[tag] {
+		entry := c.entries[key]
+		c.delete(key)
+		c.auditDeletion(context.Background(), entry)
+		numberOfKeysDeleted++
+	}
+	c.assertInvariants()
+	c.mutex.Unlock()
+	return numberOfKeysDeleted
+}
+
+// tag indexes an entry under the tags of its value, if its value is a Tagger
+func (c *Cache) tag(entry *Entry) {
+	tagger, ok := entry.Value.(Tagger)
+	if !ok {
+		return
+	}
+	entry.tags = tagger.CacheTags()
+	if len(entry.tags) == 0 {
+		return
+	}
+	if c.tags == nil {
+		c.tags = make(map[string]map[string]struct{})
+	}
+	for _, tag := range entry.tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[entry.Key] = struct{}{}
+	}
+}
+
+// untag removes an entry from the index of the tags it was indexed under
+func (c *Cache) untag(entry *Entry) {
+	for _, tag := range entry.tags {
+		if keys, ok := c.tags[tag]; ok {
+			delete(keys, entry.Key)
+			if len(keys) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+	entry.tags = nil
+}
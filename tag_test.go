@@ -0,0 +1,66 @@
+package gocache
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+type taggedValue []string
+
+func (value taggedValue) CacheTags() []string {
+	return value
+}
+
+func TestCache_GetKeysByTag(t *testing.T) {
+	cache := NewCache(WithRaceAssertions(true))
+	cache.Set("1", taggedValue{"user", "admin"})
+	cache.Set("2", taggedValue{"user"})
+	cache.Set("3", "untagged")
+	cache.SetWithTTL("4", taggedValue{"user"}, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	keys := cache.GetKeysByTag("user")
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "1" || keys[1] != "2" {
+		t.Errorf("expected keys 1 and 2, got %v", keys)
+	}
+	if keys := cache.GetKeysByTag("admin"); len(keys) != 1 || keys[0] != "1" {
+		t.Errorf("expected key 1, got %v", keys)
+	}
+	// Updating an entry with a value that has different tags must re-index it
+	cache.Set("1", taggedValue{"guest"})
+	if keys := cache.GetKeysByTag("admin"); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+	if keys := cache.GetKeysByTag("guest"); len(keys) != 1 {
+		t.Errorf("expected key 1, got %v", keys)
+	}
+	cache.Set("2", "untagged")
+	if keys := cache.GetKeysByTag("user"); len(keys) != 0 {
+		t.Errorf("expected no keys since 2 is no longer tagged and 4 expired, got %v", keys)
+	}
+}
+
+func TestCache_DeleteByTag(t *testing.T) {
+	cache := NewCache(WithMaxSize(3), WithRaceAssertions(true))
+	cache.Set("1", taggedValue{"user"})
+	cache.Set("2", taggedValue{"user"})
+	cache.Set("3", taggedValue{"other"})
+	if deleted := cache.DeleteByTag("user"); deleted != 2 {
+		t.Errorf("expected 2 keys to have been deleted, got %d", deleted)
+	}
+	if cache.Count() != 1 {
+		t.Errorf("expected 1 entry left, got %d", cache.Count())
+	}
+	// Evicted entries must be removed from the index as well
+	cache.Set("4", taggedValue{"user"})
+	cache.Set("5", "value")
+	cache.Set("6", "value")
+	if keys := cache.GetKeysByTag("other"); len(keys) != 0 {
+		t.Errorf("expected the evicted key 3 to have been removed from the index, got %v", keys)
+	}
+	cache.Clear()
+	if keys := cache.GetKeysByTag("user"); len(keys) != 0 {
+		t.Errorf("expected no keys after Clear, got %v", keys)
+	}
+}
@@ -0,0 +1,154 @@
+// Package tokencache caches the result of introspecting access tokens in a gocache.Cache, so that authentication
+// middlewares do not have to call the authorization server, or verify the signature of the token against a JWKS, on
+// every request.
+//
+// Tokens are cached by their SHA-256 hash rather than by their value, so that the tokens themselves never end up in
+// the cache. Active tokens are cached until they expire, up to the maximum TTL (see WithMaxTTL), and inactive or
+// invalid tokens are cached for the negative TTL (see WithNegativeTTL), so that clients retrying with a bad token do
+// not hammer the authorization server either. Errors returned by the IntrospectFunc are never cached.
+//
+// Usage:
+//
+//	introspector := tokencache.New(gocache.NewCache(gocache.WithMaxSize(100000)), func(ctx context.Context, token string) (tokencache.Introspection, error) {
+//		return introspectWithAuthorizationServer(ctx, token)
+//	})
+//	introspection, err := introspector.Introspect(ctx, token)
+//	if err != nil || !introspection.Active {
+//		http.Error(w, "unauthorized", http.StatusUnauthorized)
+//		return
+//	}
+package tokencache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+const (
+	// KeyPrefix is the prefix of the keys used to store introspection results in the cache
+	KeyPrefix = "tokencache:"
+
+	// DefaultMaxTTL is the maximum duration an active token is cached for if WithMaxTTL is not used
+	DefaultMaxTTL = 5 * time.Minute
+
+	// DefaultNegativeTTL is how long an inactive token is cached for if WithNegativeTTL is not used
+	DefaultNegativeTTL = 30 * time.Second
+)
+
+// Introspection is the result of introspecting a token
+type Introspection struct {
+	// Active is whether the token is valid and can be used
+	Active bool
+
+	// ExpiresAt is the time at which the token expires, or the zero time if it is unknown
+	ExpiresAt time.Time
+
+	// Claims are the claims of the token, such as its subject and its scopes
+	Claims map[string]interface{}
+}
+
+// IntrospectFunc introspects a token, for instance by sending it to the introspection endpoint of an authorization
+// server (RFC 7662) or by verifying its signature against the keys of a JWKS.
+//
+// An invalid token must be reported as an Introspection that isn't Active, rather than as an error, for it to be
+// cached. Errors should be reserved for failures to introspect the token, such as network errors.
+type IntrospectFunc func(ctx context.Context, token string) (Introspection, error)
+
+// Introspector introspects tokens, serving the results from the cache when possible
+type Introspector struct {
+	cache       *gocache.Cache
+	introspect  IntrospectFunc
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+}
+
+// Option is an option for New
+type Option func(introspector *Introspector)
+
+// WithMaxTTL sets the maximum duration an active token is cached for, which bounds how long a revoked token may still
+// be accepted. Defaults to DefaultMaxTTL
+func WithMaxTTL(ttl time.Duration) Option {
+	return func(introspector *Introspector) {
+		introspector.maxTTL = ttl
+	}
+}
+
+// WithNegativeTTL sets how long an inactive token is cached for. A TTL of 0 disables negative caching.
+// Defaults to DefaultNegativeTTL
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(introspector *Introspector) {
+		introspector.negativeTTL = ttl
+	}
+}
+
+// New creates an Introspector which introspects tokens with the given function, and caches the results in the given
+// cache
+func New(cache *gocache.Cache, introspect IntrospectFunc, opts ...Option) *Introspector {
+	introspector := &Introspector{
+		cache:       cache,
+		introspect:  introspect,
+		maxTTL:      DefaultMaxTTL,
+		negativeTTL: DefaultNegativeTTL,
+	}
+	for _, opt := range opts {
+		opt(introspector)
+	}
+	return introspector
+}
+
+// Introspect returns the result of introspecting a token, from the cache if possible
+//
+// A cached active token whose ExpiresAt has passed is never returned as active, even if it is still in the cache.
+// An active token that has already expired according to the IntrospectFunc is returned, and cached, as inactive.
+// The Claims of the Introspection returned are shared with the cache, so they must not be modified.
+//
+// Note that concurrent calls for the same token that isn't in the cache will each call the IntrospectFunc.
+func (introspector *Introspector) Introspect(ctx context.Context, token string) (Introspection, error) {
+	key := Key(token)
+	if value, ok := introspector.cache.GetCtx(ctx, key); ok {
+		if introspection, ok := value.(Introspection); ok && !expired(introspection) {
+			return introspection, nil
+		}
+	}
+	introspection, err := introspector.introspect(ctx, token)
+	if err != nil {
+		return Introspection{}, err
+	}
+	if expired(introspection) {
+		introspection.Active = false
+	}
+	ttl := introspector.negativeTTL
+	if introspection.Active {
+		ttl = introspector.maxTTL
+		if !introspection.ExpiresAt.IsZero() {
+			if untilExpiration := time.Until(introspection.ExpiresAt); untilExpiration < ttl {
+				ttl = untilExpiration
+			}
+		}
+	}
+	if ttl > 0 {
+		_ = introspector.cache.SetWithTTLCtx(ctx, key, introspection, ttl)
+	}
+	return introspection, nil
+}
+
+// Invalidate removes the cached result of introspecting a token, so that it is introspected again the next time, which
+// should be called when a token is revoked
+func (introspector *Introspector) Invalidate(token string) {
+	introspector.cache.Delete(Key(token))
+}
+
+// Key returns the key under which the result of introspecting a token is cached
+func Key(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return KeyPrefix + hex.EncodeToString(hash[:])
+}
+
+// expired returns whether an active token has expired
+func expired(introspection Introspection) bool {
+	return introspection.Active && !introspection.ExpiresAt.IsZero() && !time.Now().Before(introspection.ExpiresAt)
+}
@@ -0,0 +1,121 @@
+package tokencache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gocache "github.com/arham09/cache"
+)
+
+func TestIntrospector_Introspect(t *testing.T) {
+	var calls int32
+	cache := gocache.NewCache()
+	introspector := New(cache, func(ctx context.Context, token string) (Introspection, error) {
+		atomic.AddInt32(&calls, 1)
+		return Introspection{Active: true, ExpiresAt: time.Now().Add(time.Hour), Claims: map[string]interface{}{"sub": "john"}}, nil
+	})
+	for i := 0; i < 3; i++ {
+		introspection, err := introspector.Introspect(context.Background(), "token")
+		if err != nil || !introspection.Active || introspection.Claims["sub"] != "john" {
+			t.Fatalf("expected an active token for john, got %v (%v)", introspection, err)
+		}
+	}
+	if calls != 1 {
+		t.Error("expected the token to have been introspected once, got", calls)
+	}
+	if ttl, _ := cache.TTL(Key("token")); ttl > DefaultMaxTTL {
+		t.Error("expected the TTL to be capped at the max TTL, got", ttl)
+	}
+	if keys := cache.GetKeysByPattern("*token", 0); len(keys) != 0 {
+		t.Error("expected the token not to be stored in the cache, got", keys)
+	}
+	introspector.Invalidate("token")
+	introspector.Introspect(context.Background(), "token")
+	if calls != 2 {
+		t.Error("expected the token to have been introspected again once invalidated, got", calls)
+	}
+}
+
+func TestIntrospector_IntrospectUsesExpirationOfToken(t *testing.T) {
+	var calls int32
+	cache := gocache.NewCache()
+	introspector := New(cache, func(ctx context.Context, token string) (Introspection, error) {
+		atomic.AddInt32(&calls, 1)
+		return Introspection{Active: true, ExpiresAt: time.Now().Add(50 * time.Millisecond)}, nil
+	})
+	introspector.Introspect(context.Background(), "token")
+	if ttl, _ := cache.TTL(Key("token")); ttl > 50*time.Millisecond {
+		t.Error("expected the TTL to be derived from the expiration of the token, got", ttl)
+	}
+	time.Sleep(60 * time.Millisecond)
+	introspector.Introspect(context.Background(), "token")
+	if calls != 2 {
+		t.Error("expected the token to have been introspected again once expired, got", calls)
+	}
+}
+
+func TestIntrospector_IntrospectWithExpiredToken(t *testing.T) {
+	introspector := New(gocache.NewCache(), func(ctx context.Context, token string) (Introspection, error) {
+		return Introspection{Active: true, ExpiresAt: time.Now().Add(-time.Second)}, nil
+	})
+	if introspection, _ := introspector.Introspect(context.Background(), "token"); introspection.Active {
+		t.Error("expected an expired token to be inactive")
+	}
+}
+
+func TestIntrospector_IntrospectWithInactiveToken(t *testing.T) {
+	var calls int32
+	cache := gocache.NewCache()
+	introspector := New(cache, func(ctx context.Context, token string) (Introspection, error) {
+		atomic.AddInt32(&calls, 1)
+		return Introspection{Active: false}, nil
+	}, WithNegativeTTL(50*time.Millisecond))
+	introspector.Introspect(context.Background(), "bad-token")
+	introspector.Introspect(context.Background(), "bad-token")
+	if calls != 1 {
+		t.Error("expected the inactive token to have been cached, got", calls, "calls")
+	}
+	time.Sleep(60 * time.Millisecond)
+	introspector.Introspect(context.Background(), "bad-token")
+	if calls != 2 {
+		t.Error("expected the inactive token to have been introspected again after the negative TTL, got", calls, "calls")
+	}
+}
+
+func TestIntrospector_IntrospectWithoutNegativeCaching(t *testing.T) {
+	cache := gocache.NewCache()
+	introspector := New(cache, func(ctx context.Context, token string) (Introspection, error) {
+		return Introspection{Active: false}, nil
+	}, WithNegativeTTL(0))
+	introspector.Introspect(context.Background(), "bad-token")
+	if cache.Count() != 0 {
+		t.Error("expected the inactive token not to have been cached")
+	}
+}
+
+func TestIntrospector_IntrospectWithError(t *testing.T) {
+	var calls int32
+	cache := gocache.NewCache()
+	introspector := New(cache, func(ctx context.Context, token string) (Introspection, error) {
+		atomic.AddInt32(&calls, 1)
+		return Introspection{}, errors.New("connection refused")
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := introspector.Introspect(context.Background(), "token"); err == nil {
+			t.Error("expected an error")
+		}
+	}
+	if calls != 2 || cache.Count() != 0 {
+		t.Error("expected errors not to have been cached")
+	}
+}
+
+func TestKey(t *testing.T) {
+	if key := Key("token"); !strings.HasPrefix(key, KeyPrefix) || strings.Contains(strings.TrimPrefix(key, KeyPrefix), "token") || key == Key("other-token") {
+		t.Error("expected the key to be derived from the hash of the token, got", key)
+	}
+}
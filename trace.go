@@ -0,0 +1,102 @@
+package gocache
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyTrace is the destination of the events of a key traced through TraceKey
+type keyTrace struct {
+	// mutex guards w, as the same writer may be used to trace several keys of several caches
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// TraceKey writes every operation touching a key to the writer passed as parameter, one line per event, until the
+// function returned is called, which is invaluable when debugging why a key disappeared from the cache.
+//
+// The events are set (created or updated, along with the expiration), skip (a write that was refused), get (hit or
+// miss), reposition (the entry was moved to the head of the list by a retrieval), modify (a collection such as the
+// sets created through SAdd was modified in place), delete, evict, expire and clear. Each line starts with the time of
+// the event, followed by the event and the key, e.g.
+//     2024-01-02T15:04:05.123456789Z set key="user:42" created expiration=2024-01-02T16:04:05Z
+//
+// The events are written while the cache's lock is held, so w should be fast, or buffered, like the audit log (see
+// WithAuditLog). Errors writing the events are ignored. The same key may be traced more than once at the same time.
+// The key is the key given by the caller, so tracing a key works even if WithKeyObfuscation is used.
+func (c *Cache) TraceKey(key string, w io.Writer) (cancel func()) {
+	key = c.storageKey(key)
+	trace := &keyTrace{w: w}
+	c.mutex.Lock()
+	if c.traces == nil {
+		c.traces = make(map[string][]*keyTrace)
+	}
+	c.traces[key] = append(c.traces[key], trace)
+	c.mutex.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			traces := c.traces[key]
+			for i, current := range traces {
+				if current == trace {
+					traces = append(traces[:i:i], traces[i+1:]...)
+					break
+				}
+			}
+			if len(traces) == 0 {
+				delete(c.traces, key)
+			} else {
+				c.traces[key] = traces
+			}
+			if len(c.traces) == 0 {
+				c.traces = nil
+			}
+		})
+	}
+}
+
+// traced returns whether there is a trace of the key passed as parameter, so that callers can avoid building events
+// that would be discarded
+//
+// The caller must hold the lock.
+func (c *Cache) traced(key string) bool {
+	if c.traces == nil {
+		return false
+	}
+	_, ok := c.traces[key]
+	return ok
+}
+
+// trace writes an event to every trace of the key passed as parameter, if any
+// The details are appended to the line after the key, separated by a space.
+//
+// The caller must hold the lock.
+func (c *Cache) trace(key, event string, details ...string) {
+	traces := c.traces[key]
+	if len(traces) == 0 {
+		return
+	}
+	line := fmt.Sprintf("%s %s key=%q", time.Now().UTC().Format(time.RFC3339Nano), event, key)
+	if len(details) > 0 {
+		line += " " + strings.Join(details, " ")
+	}
+	line += "\n"
+	for _, trace := range traces {
+		trace.mutex.Lock()
+		_, _ = io.WriteString(trace.w, line)
+		trace.mutex.Unlock()
+	}
+}
+
+// traceExpiration returns the detail of a set event describing the expiration of an entry
+func traceExpiration(expiration int64) string {
+	if expiration == NoExpiration {
+		return "expiration=none"
+	}
+	return "expiration=" + time.Unix(0, expiration).UTC().Format(time.RFC3339Nano)
+}
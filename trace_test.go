@@ -0,0 +1,103 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// traceEvents returns the events written by TraceKey, without their time
+func traceEvents(trace string) []string {
+	var events []string
+	for _, line := range strings.Split(strings.TrimSuffix(trace, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		events = append(events, line[strings.IndexByte(line, ' ')+1:])
+	}
+	return events
+}
+
+func TestCache_TraceKey(t *testing.T) {
+	cache := NewCache(WithMaxSize(2), WithEvictionPolicy(LeastRecentlyUsed))
+	var sb strings.Builder
+	cancel := cache.TraceKey("traced", &sb)
+	cache.Get("traced")
+	cache.Set("traced", "value")
+	cache.Set("other", "value")
+	cache.Get("traced")
+	cache.Set("traced", "updated")
+	cache.Get("other")
+	cache.Set("third", "value")
+	cache.SetWithTTL("traced", "value", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	cache.Get("traced")
+	cache.Set("traced", "value")
+	cache.Delete("traced")
+	cancel()
+	cancel()
+	cache.Set("traced", "untraced")
+	expected := []string{
+		`get key="traced" miss`,
+		`set key="traced" created expiration=none`,
+		`get key="traced" hit`,
+		`reposition key="traced" head`,
+		`set key="traced" updated expiration=none`,
+		`evict key="traced"`,
+		`get key="traced" miss expired`,
+		`expire key="traced"`,
+		`set key="traced" created expiration=none`,
+		`delete key="traced"`,
+	}
+	events := traceEvents(sb.String())
+	// The expiration of the entry set with a TTL varies, so only its prefix is checked
+	if len(events) != len(expected)+1 || !strings.HasPrefix(events[6], `set key="traced" created expiration=2`) {
+		t.Fatalf("expected %d events, got:\n%s", len(expected)+1, sb.String())
+	}
+	events = append(events[:6], events[7:]...)
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("expected event %d to be %q, got %q", i, expected[i], events[i])
+		}
+	}
+	if cache.traces != nil {
+		t.Error("expected the traces to have been removed")
+	}
+}
+
+func TestCache_TraceKeyWhenSkipped(t *testing.T) {
+	cache := NewCache(WithMaxSize(1), WithFullBehavior(RejectWrites), WithNeverCachePatterns("secret:*"))
+	var sb strings.Builder
+	defer cache.TraceKey("key", &sb)()
+	defer cache.TraceKey("secret:key", &sb)()
+	cache.Set("other", "value")
+	cache.Set("key", "value")
+	cache.SetWithTTL("key", "value", 0)
+	cache.Set("secret:key", "value")
+	expected := []string{
+		`skip key="key" reason=cache-full`,
+		`skip key="key" reason=expired`,
+		`skip key="secret:key" reason=never-cached`,
+	}
+	events := traceEvents(sb.String())
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+}
+
+func TestCache_TraceKeyWithCollectionAndClear(t *testing.T) {
+	cache := NewCache()
+	var first, second strings.Builder
+	cancelFirst := cache.TraceKey("set", &first)
+	defer cache.TraceKey("set", &second)()
+	cache.SAdd("set", "a")
+	cache.SAdd("set", "b")
+	cancelFirst()
+	cache.Clear()
+	if events := traceEvents(first.String()); len(events) != 2 || events[1] != `modify key="set"` {
+		t.Errorf("expected the first trace to have stopped after the modification, got %v", events)
+	}
+	if events := traceEvents(second.String()); len(events) != 3 || events[2] != `clear key="set"` {
+		t.Errorf("expected the second trace to have recorded the clear, got %v", events)
+	}
+}
@@ -0,0 +1,87 @@
+package gocache
+
+import "time"
+
+// UsageAlertInterval is the minimum interval between two calls to the callback passed to WithUsageAlert
+const UsageAlertInterval = time.Minute
+
+// UsageReport is the usage of a cache, as passed to the callback of WithUsageAlert
+type UsageReport struct {
+	// Time is the time at which the threshold was found to be crossed
+	Time time.Time
+
+	// Count is the number of entries in the cache
+	Count int
+
+	// MaxSize is the MaxSize of the cache, or NoMaxSize
+	MaxSize int
+
+	// MemoryUsage is the approximate memory usage of the entries of the cache in bytes
+	MemoryUsage int
+
+	// MaxMemoryUsage is the MaxMemoryUsage of the cache, or NoMaxMemoryUsage
+	MaxMemoryUsage int
+
+	// Usage is the highest of the fractions of the MaxSize and of the MaxMemoryUsage in use
+	Usage float64
+
+	// Threshold is the threshold passed to WithUsageAlert
+	Threshold float64
+}
+
+// WithUsageAlert sets a function that is called when the number of entries or the memory usage of the cache crosses
+// the given fraction of the MaxSize or of the MaxMemoryUsage, e.g. 0.9 for 90%, so that services can emit warnings
+// before the evictions start hurting the hit ratio. The usage is checked on every write, but the function is called
+// at most once per UsageAlertInterval while the usage stays above the threshold.
+//
+// The function is called asynchronously, on one of the goroutines also running the callbacks registered through
+// OnSetPattern (see HookWorkerPoolSize), so it may call methods of the cache. If the cache has neither a MaxSize nor
+// a MaxMemoryUsage, the function is never called.
+// A threshold of 0 or less, or a nil function, disables the alert, which is the default.
+func WithUsageAlert(threshold float64, fn func(report UsageReport)) func(c *Cache) {
+	return func(c *Cache) {
+		if threshold <= 0 || fn == nil {
+			c.usageAlert = nil
+			return
+		}
+		c.usageAlertThreshold = threshold
+		c.usageAlert = fn
+	}
+}
+
+// checkUsage calls the function passed to WithUsageAlert if the usage of the cache is above the threshold and the
+// function wasn't called within the last UsageAlertInterval
+//
+// The caller must hold the lock.
+func (c *Cache) checkUsage() {
+	usage := 0.0
+	if c.maxSize != NoMaxSize {
+		usage = float64(len(c.entries)) / float64(c.maxSize)
+	}
+	if c.maxMemoryUsage != NoMaxMemoryUsage {
+		if memoryUsage := float64(c.memoryUsage) / float64(c.maxMemoryUsage); memoryUsage > usage {
+			usage = memoryUsage
+		}
+	}
+	if usage < c.usageAlertThreshold {
+		return
+	}
+	now := time.Now()
+	if c.lastUsageAlert != 0 && now.UnixNano()-c.lastUsageAlert < int64(UsageAlertInterval) {
+		return
+	}
+	c.lastUsageAlert = now.UnixNano()
+	report := UsageReport{
+		Time:           now,
+		Count:          len(c.entries),
+		MaxSize:        c.maxSize,
+		MemoryUsage:    c.memoryUsage,
+		MaxMemoryUsage: c.maxMemoryUsage,
+		Usage:          usage,
+		Threshold:      c.usageAlertThreshold,
+	}
+	alert := c.usageAlert
+	c.hookWorkers.submit(c.name, func() {
+		alert(report)
+	})
+}
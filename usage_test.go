@@ -0,0 +1,68 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWithUsageAlert(t *testing.T) {
+	reports := make(chan UsageReport, 10)
+	cache := NewCache(WithMaxSize(10), WithUsageAlert(0.8, func(report UsageReport) {
+		reports <- report
+	}))
+	for n := 0; n < 7; n++ {
+		cache.Set(fmt.Sprint(n), n)
+	}
+	select {
+	case report := <-reports:
+		t.Fatalf("expected no alert below the threshold, got %+v", report)
+	case <-time.After(10 * time.Millisecond):
+	}
+	for n := 7; n < 20; n++ {
+		cache.Set(fmt.Sprint(n), n)
+	}
+	select {
+	case report := <-reports:
+		if report.Count != 8 || report.MaxSize != 10 || report.Usage != 0.8 || report.Threshold != 0.8 || report.Time.IsZero() {
+			t.Errorf("expected the report of the write crossing the threshold, got %+v", report)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an alert once the threshold was crossed")
+	}
+	select {
+	case report := <-reports:
+		t.Errorf("expected the alerts to be rate-limited, got %+v", report)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestWithUsageAlertWithMaxMemoryUsage(t *testing.T) {
+	reports := make(chan UsageReport, 10)
+	cache := NewCache(WithMaxSize(NoMaxSize), WithMaxMemoryUsage(Kilobyte), WithUsageAlert(0.5, func(report UsageReport) {
+		reports <- report
+	}))
+	cache.SAdd("set", "a")
+	cache.SAdd("set", string(make([]byte, 600)))
+	select {
+	case report := <-reports:
+		if report.MemoryUsage < Kilobyte/2 || report.MaxMemoryUsage != Kilobyte || report.Usage < 0.5 {
+			t.Errorf("expected the report of the memory usage, got %+v", report)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an alert once the memory usage crossed the threshold")
+	}
+}
+
+func TestWithUsageAlertWithoutMaximums(t *testing.T) {
+	called := make(chan bool, 1)
+	cache := NewCache(WithMaxSize(NoMaxSize), WithUsageAlert(0.1, func(UsageReport) {
+		called <- true
+	}))
+	cache.Set("key", "value")
+	select {
+	case <-called:
+		t.Error("expected no alert for a cache without maximums")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,36 @@
+package gocache
+
+import "time"
+
+// TTLer is implemented by values that determine their own TTL
+//
+// When a TTLer is passed to Set or SetAll, the TTL returned by CacheTTL is used instead of NoExpiration. An explicit
+// TTL, such as the one passed to SetWithTTL, always takes precedence. Like with SetWithTTL, a TTL of 0 means that the
+// value is not cached at all.
+type TTLer interface {
+	CacheTTL() time.Duration
+}
+
+// Coster is implemented by values that determine their own cost, which is used instead of their approximate size in
+// bytes when computing the memory usage of the cache (see WithMaxMemoryUsage)
+//
+// The cost of a value must not change while it is in the cache, as it is only computed when the value is written.
+type Coster interface {
+	CacheCost() int
+}
+
+// Tagger is implemented by values that belong to one or more tags, which can be used to retrieve or delete every
+// entry sharing the same tag (see GetKeysByTag and DeleteByTag)
+//
+// The tags of a value must not change while it is in the cache, as they are only read when the value is written.
+type Tagger interface {
+	CacheTags() []string
+}
+
+// ttlOf returns the TTL that the value passed as parameter should be stored with when no TTL is specified
+func ttlOf(value interface{}) time.Duration {
+	if ttler, ok := value.(TTLer); ok {
+		return ttler.CacheTTL()
+	}
+	return NoExpiration
+}
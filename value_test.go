@@ -0,0 +1,61 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+type session struct {
+	ttl time.Duration
+}
+
+func (s session) CacheTTL() time.Duration {
+	return s.ttl
+}
+
+type blob struct {
+	cost int
+}
+
+func (b blob) CacheCost() int {
+	return b.cost
+}
+
+func TestCache_SetWithTTLer(t *testing.T) {
+	cache := NewCache()
+	cache.Set("session", session{ttl: time.Hour})
+	if ttl, err := cache.TTL("session"); err != nil || ttl.Minutes() < 59 {
+		t.Error("expected the TTL of the value to be used, got", ttl, err)
+	}
+	cache.SetAll(map[string]interface{}{"other": session{ttl: time.Minute}})
+	if ttl, err := cache.TTL("other"); err != nil || ttl.Seconds() < 59 || ttl.Minutes() > 1 {
+		t.Error("expected SetAll to use the TTL of the value, got", ttl, err)
+	}
+	cache.Set("uncacheable", session{ttl: 0})
+	if _, ok := cache.Get("uncacheable"); ok {
+		t.Error("expected a value with a TTL of 0 not to be cached")
+	}
+	cache.SetWithTTL("session", session{ttl: time.Hour}, NoExpiration)
+	if _, err := cache.TTL("session"); err != ErrKeyHasNoExpiration {
+		t.Error("expected the explicit TTL to take precedence over the TTL of the value, got", err)
+	}
+}
+
+func TestCache_SetWithCoster(t *testing.T) {
+	cache := NewCache(WithMaxMemoryUsage(1000))
+	cache.Set("a", blob{cost: 400})
+	cache.Set("b", blob{cost: 400})
+	if cache.Count() != 2 {
+		t.Fatalf("expected 2 entries, got %d", cache.Count())
+	}
+	cache.Set("c", blob{cost: 400})
+	if cache.Count() != 2 {
+		t.Errorf("expected the cost of the values to cause an eviction, got %d entries", cache.Count())
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected a to have been evicted")
+	}
+	if size := (&Entry{Key: "k", Value: blob{cost: 400}}).SizeInBytes(); size != 400+toBytes("k")+32 {
+		t.Errorf("expected the cost to replace the size of the value, got %d", size)
+	}
+}
@@ -0,0 +1,98 @@
+package gocache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// View is a read-only handle on a cache, returned by ReadOnlyView, which serves reads from a snapshot of the cache
+// rather than from the cache itself
+type View struct {
+	cache        *Cache
+	maxStaleness int64
+
+	// snapshot is the current *viewSnapshot, or nil until the first read
+	snapshot atomic.Value
+
+	// refreshing is 1 while a reader is taking a new snapshot, so that concurrent readers keep using the current one
+	refreshing int32
+}
+
+// viewSnapshot is a copy of the entries of a cache taken at a point in time
+type viewSnapshot struct {
+	entries map[string]viewEntry
+
+	// takenAt is the unix time in nanoseconds at which the snapshot was taken
+	takenAt int64
+}
+
+// viewEntry is an entry of a viewSnapshot
+type viewEntry struct {
+	value      interface{}
+	expiration int64
+}
+
+// ReadOnlyView returns a read-only handle on the cache whose reads are served from a snapshot of the cache, which
+// is taken again by the first read that happens more than maxStaleness after the previous snapshot was taken.
+//
+// Reading from the snapshot doesn't acquire any lock, so it never contends with writes nor with other reads, at the
+// cost of serving values that may be up to maxStaleness old, or slightly older while a new snapshot is being taken.
+// Since taking a snapshot copies every entry of the cache, this is only worth it for read-mostly workloads with a
+// maxStaleness much longer than the time it takes to copy the cache.
+//
+// Reads through the view do not count as accessing the entries, nor do they update the statistics. Entries that
+// expire after the snapshot was taken are not served.
+func (c *Cache) ReadOnlyView(maxStaleness time.Duration) *View {
+	return &View{cache: c, maxStaleness: int64(maxStaleness)}
+}
+
+// Get retrieves the value of a key from the snapshot, taking a new snapshot first if the current one is too stale
+// If the key wasn't in the cache when the snapshot was taken, or has expired since, the value returned will be nil
+// and the boolean will be false.
+func (view *View) Get(key string) (interface{}, bool) {
+	now := time.Now().UnixNano()
+	entry, ok := view.current(now).entries[view.cache.storageKey(key)]
+	if !ok || (entry.expiration > 0 && now > entry.expiration) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Count returns the number of entries in the snapshot, taking a new snapshot first if the current one is too stale
+// Unlike Cache.Count, this may include entries that have expired since the snapshot was taken.
+func (view *View) Count() int {
+	return len(view.current(time.Now().UnixNano()).entries)
+}
+
+// current returns the current snapshot, taking a new one if it is too stale, unless another reader is already doing so
+func (view *View) current(now int64) *viewSnapshot {
+	snapshot, _ := view.snapshot.Load().(*viewSnapshot)
+	if snapshot != nil && now-snapshot.takenAt <= view.maxStaleness {
+		return snapshot
+	}
+	// Without any snapshot to serve in the meantime, concurrent first reads each take their own snapshot
+	if snapshot != nil {
+		if !atomic.CompareAndSwapInt32(&view.refreshing, 0, 1) {
+			return snapshot
+		}
+		defer atomic.StoreInt32(&view.refreshing, 0)
+	}
+	snapshot = view.take()
+	view.snapshot.Store(snapshot)
+	return snapshot
+}
+
+// take takes a snapshot of the entries of the cache that haven't expired
+func (view *View) take() *viewSnapshot {
+	c := view.cache
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	snapshot := &viewSnapshot{entries: make(map[string]viewEntry, len(c.entries)), takenAt: time.Now().UnixNano()}
+	for key, entry := range c.entries {
+		if entry.Expired() {
+			continue
+		}
+		snapshot.entries[key] = viewEntry{value: entry.Value, expiration: entry.Expiration}
+	}
+	return snapshot
+}
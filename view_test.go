@@ -0,0 +1,83 @@
+package gocache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_ReadOnlyView(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	view := cache.ReadOnlyView(50 * time.Millisecond)
+	if value, ok := view.Get("key"); !ok || value != "value" {
+		t.Errorf("expected value, got %v", value)
+	}
+	cache.Set("key", "new-value")
+	cache.Set("new-key", "value")
+	if value, _ := view.Get("key"); value != "value" {
+		t.Error("expected the stale value to have been served from the snapshot, got", value)
+	}
+	if _, ok := view.Get("new-key"); ok {
+		t.Error("expected the key created after the snapshot was taken not to be served")
+	}
+	if view.Count() != 1 {
+		t.Error("expected the snapshot to have 1 entry, got", view.Count())
+	}
+	time.Sleep(60 * time.Millisecond)
+	if value, _ := view.Get("key"); value != "new-value" {
+		t.Error("expected a new snapshot to have been taken once the previous one was too stale, got", value)
+	}
+	if view.Count() != 2 {
+		t.Error("expected the snapshot to have 2 entries, got", view.Count())
+	}
+}
+
+func TestCache_ReadOnlyViewDoesNotServeExpiredEntries(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("key", "value", 30*time.Millisecond)
+	view := cache.ReadOnlyView(time.Hour)
+	if _, ok := view.Get("key"); !ok {
+		t.Error("expected the key to be served")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := view.Get("key"); ok {
+		t.Error("expected the key not to be served once expired")
+	}
+}
+
+func TestCache_ReadOnlyViewDoesNotUpdateStats(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	view := cache.ReadOnlyView(time.Hour)
+	view.Get("key")
+	view.Get("missing")
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("expected no hit nor miss, got %d and %d", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCache_ReadOnlyViewConcurrency(t *testing.T) {
+	cache := NewCache()
+	view := cache.ReadOnlyView(time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cache.Set(fmt.Sprintf("%d-%d", i, j), j)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if value, ok := view.Get(fmt.Sprintf("%d-%d", i, j)); ok && value != j {
+					t.Errorf("expected %d, got %v", j, value)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}